@@ -0,0 +1,610 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/analytics"
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/pricing"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/tradecsv"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// maxRequestBody caps the size of API request bodies
+const maxRequestBody = 10 << 20
+
+// apiError is an error with the HTTP status and code it is reported with
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string { return e.Message }
+
+// badRequest reports a malformed request
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{Status: http.StatusBadRequest, Code: "bad_request", Message: fmt.Sprintf(format, args...)}
+}
+
+// apiServer serves the App bindings as a JSON API on a loopback address
+type apiServer struct {
+	app    *App
+	token  string
+	server *http.Server
+}
+
+// newAPIServer creates an API server for a loopback address. An empty token
+// generates a random one.
+func newAPIServer(app *App, addr, token string) (*apiServer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API address %q: %w", addr, err)
+	}
+	if !isLoopback(host) {
+		return nil, fmt.Errorf("API address %q is not a loopback address", addr)
+	}
+
+	if token == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate API token: %w", err)
+		}
+		token = hex.EncodeToString(b)
+	}
+
+	s := &apiServer{app: app, token: token}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// Start listens on the server's address and serves requests in the background
+func (s *apiServer) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the server, waiting for requests in progress to finish
+func (s *apiServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// routes maps the API endpoints to the App bindings
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	a := s.app
+
+	// Risk assessments
+	mux.Handle("GET /api/assessments", handler(func(r *http.Request) (interface{}, error) {
+		query, err := parseQuery(r)
+		if err != nil {
+			return nil, err
+		}
+		return a.QueryRiskAssessments(query)
+	}))
+	mux.Handle("POST /api/assessments", handler(func(r *http.Request) (interface{}, error) {
+		assessment := models.NewRiskAssessment()
+		if err := decode(r, assessment); err != nil {
+			return nil, err
+		}
+		return assessment, a.SaveRiskAssessment(assessment)
+	}))
+	mux.Handle("DELETE /api/assessments/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteRiskAssessment(r.PathValue("id"))
+	}))
+	mux.Handle("POST /api/assessments/recommendation", handler(func(r *http.Request) (interface{}, error) {
+		assessment := models.NewRiskAssessment()
+		if err := decode(r, assessment); err != nil {
+			return nil, err
+		}
+		return a.GetRecommendedPositionSize(assessment)
+	}))
+
+	// Stock, sector and market ratings
+	mux.Handle("GET /api/ratings", handler(func(r *http.Request) (interface{}, error) {
+		query, err := parseQuery(r)
+		if err != nil {
+			return nil, err
+		}
+		return a.QueryStockRatings(query)
+	}))
+	mux.Handle("POST /api/ratings", handler(func(r *http.Request) (interface{}, error) {
+		rating := models.NewStockRating()
+		if err := decode(r, rating); err != nil {
+			return nil, err
+		}
+		return rating, a.SaveStockRating(rating)
+	}))
+	mux.Handle("POST /api/ratings/batch", handler(func(r *http.Request) (interface{}, error) {
+		var ratings []*models.StockRating
+		if err := decode(r, &ratings); err != nil {
+			return nil, err
+		}
+		return ratings, a.SaveStockRatings(ratings)
+	}))
+	mux.Handle("DELETE /api/ratings/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteStockRating(r.PathValue("id"))
+	}))
+	mux.Handle("GET /api/ratings/by-date/{date}", handler(func(r *http.Request) (interface{}, error) {
+		date, err := parseTime(r.PathValue("date"))
+		if err != nil {
+			return nil, err
+		}
+		return a.GetStockRatingsByDate(date)
+	}))
+	mux.Handle("PUT /api/ratings/by-date/{date}", handler(func(r *http.Request) (interface{}, error) {
+		date, err := parseTime(r.PathValue("date"))
+		if err != nil {
+			return nil, err
+		}
+		var ratings []*models.StockRating
+		if err := decode(r, &ratings); err != nil {
+			return nil, err
+		}
+		return ratings, a.ReplaceRatingsForDate(date, ratings)
+	}))
+	mux.Handle("GET /api/ratings/latest/market", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetLatestMarketRating()
+	}))
+	mux.Handle("GET /api/ratings/latest/sector/{sector}", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetLatestSectorRating(r.PathValue("sector"))
+	}))
+	mux.Handle("GET /api/ratings/latest/stock/{symbol}", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetLatestStockRating(strings.ToUpper(r.PathValue("symbol")))
+	}))
+
+	// Trades
+	mux.Handle("GET /api/trades", handler(func(r *http.Request) (interface{}, error) {
+		query, err := parseQuery(r)
+		if err != nil {
+			return nil, err
+		}
+		return a.QueryTrades(query)
+	}))
+	mux.Handle("POST /api/trades", handler(func(r *http.Request) (interface{}, error) {
+		trade := &models.Trade{}
+		if err := decode(r, trade); err != nil {
+			return nil, err
+		}
+		check, err := a.SaveTrade(trade)
+		if err != nil && check != nil && check.Blocked {
+			return nil, &apiError{Status: http.StatusConflict, Code: "risk_blocked", Message: err.Error()}
+		}
+		return &savedTrade{Trade: trade, Check: check}, err
+	}))
+	mux.Handle("POST /api/trades/check", handler(func(r *http.Request) (interface{}, error) {
+		trade := &models.Trade{}
+		if err := decode(r, trade); err != nil {
+			return nil, err
+		}
+		return a.CheckTradeRisk(trade)
+	}))
+	mux.Handle("GET /api/trades/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetTradeHistory(r.PathValue("id"))
+	}))
+	mux.Handle("DELETE /api/trades/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteTrade(r.PathValue("id"))
+	}))
+	mux.Handle("POST /api/trades/{id}/close", handler(func(r *http.Request) (interface{}, error) {
+		event := &models.TradeEvent{}
+		if err := decode(r, event); err != nil {
+			return nil, err
+		}
+		return a.CloseTrade(r.PathValue("id"), event)
+	}))
+	mux.Handle("POST /api/trades/{id}/roll", handler(func(r *http.Request) (interface{}, error) {
+		body := rollRequest{Event: &models.TradeEvent{}, Trade: &models.Trade{}}
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.RollTrade(r.PathValue("id"), body.Event, body.Trade)
+	}))
+	mux.Handle("POST /api/trades/{id}/greeks", handler(func(r *http.Request) (interface{}, error) {
+		var market pricing.Market
+		if err := decode(r, &market); err != nil {
+			return nil, err
+		}
+		return a.GetTradeGreeks(r.PathValue("id"), market)
+	}))
+	mux.Handle("POST /api/trades/{id}/payoff", handler(func(r *http.Request) (interface{}, error) {
+		var options pricing.PayoffOptions
+		if err := decode(r, &options); err != nil {
+			return nil, err
+		}
+		return a.GetTradePayoff(r.PathValue("id"), options)
+	}))
+	mux.Handle("GET /api/exposure", handler(func(r *http.Request) (interface{}, error) {
+		var asOf time.Time
+		if v := r.URL.Query().Get("asOf"); v != "" {
+			var err error
+			if asOf, err = parseTime(v); err != nil {
+				return nil, err
+			}
+		}
+		return a.GetPortfolioExposure(asOf)
+	}))
+
+	mux.Handle("GET /api/performance", handler(func(r *http.Request) (interface{}, error) {
+		filter, err := parseFilter(r)
+		if err != nil {
+			return nil, err
+		}
+		return a.GetPerformanceReport(filter)
+	}))
+	mux.Handle("GET /api/psychology", handler(func(r *http.Request) (interface{}, error) {
+		filter, err := parseFilter(r)
+		if err != nil {
+			return nil, err
+		}
+		return a.GetPsychologyReport(filter)
+	}))
+
+	// Loss limits and equity
+	mux.Handle("GET /api/loss-limit", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetLossLimitStatus(nil)
+	}))
+	mux.Handle("POST /api/loss-limit", handler(func(r *http.Request) (interface{}, error) {
+		var marks map[string]float64
+		if err := decode(r, &marks); err != nil {
+			return nil, err
+		}
+		return a.GetLossLimitStatus(marks)
+	}))
+	mux.Handle("POST /api/loss-limit/override", handler(func(r *http.Request) (interface{}, error) {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.OverrideLossLimit(body.Reason)
+	}))
+	mux.Handle("GET /api/equity", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetEquityCurve()
+	}))
+	mux.Handle("POST /api/equity/snapshots", handler(func(r *http.Request) (interface{}, error) {
+		return a.RecordEquitySnapshot()
+	}))
+	mux.Handle("GET /api/cash-flows", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetCashFlows()
+	}))
+	mux.Handle("POST /api/cash-flows", handler(func(r *http.Request) (interface{}, error) {
+		flow := &models.CashFlow{}
+		if err := decode(r, flow); err != nil {
+			return nil, err
+		}
+		return flow, a.SaveCashFlow(flow)
+	}))
+	mux.Handle("DELETE /api/cash-flows/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteCashFlow(r.PathValue("id"))
+	}))
+
+	// Warning rules
+	mux.Handle("GET /api/rules", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetRules()
+	}))
+	mux.Handle("POST /api/rules", handler(func(r *http.Request) (interface{}, error) {
+		rule := &models.Rule{Enabled: true, Severity: models.SeverityWarning}
+		if err := decode(r, rule); err != nil {
+			return nil, err
+		}
+		return rule, a.SaveRule(rule)
+	}))
+	mux.Handle("DELETE /api/rules/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteRule(r.PathValue("id"))
+	}))
+	mux.Handle("GET /api/rules/variables", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetRuleVariables(), nil
+	}))
+	mux.Handle("GET /api/rules/evaluation", handler(func(r *http.Request) (interface{}, error) {
+		return a.EvaluateRules()
+	}))
+
+	// Journal
+	mux.Handle("GET /api/journal", handler(func(r *http.Request) (interface{}, error) {
+		params := r.URL.Query()
+		if tradeID := params.Get("tradeId"); tradeID != "" {
+			return a.GetJournalEntriesByTrade(tradeID)
+		}
+		if params.Get("start") == "" && params.Get("end") == "" {
+			return a.GetJournalEntries()
+		}
+		query, err := parseQuery(r)
+		if err != nil {
+			return nil, err
+		}
+		if query.End.IsZero() {
+			query.End = time.Now()
+		}
+		return a.GetJournalEntriesByDateRange(query.Start, query.End)
+	}))
+	mux.Handle("POST /api/journal", handler(func(r *http.Request) (interface{}, error) {
+		entry := models.NewJournalEntry()
+		if err := decode(r, entry); err != nil {
+			return nil, err
+		}
+		return entry, a.SaveJournalEntry(entry)
+	}))
+	mux.Handle("DELETE /api/journal/{id}", handler(func(r *http.Request) (interface{}, error) {
+		return nil, a.DeleteJournalEntry(r.PathValue("id"))
+	}))
+
+	// Position settings
+	mux.Handle("GET /api/settings", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetPositionSettings()
+	}))
+	mux.Handle("PUT /api/settings", handler(func(r *http.Request) (interface{}, error) {
+		settings, err := a.GetPositionSettings()
+		if err != nil {
+			return nil, err
+		}
+		if err := decode(r, settings); err != nil {
+			return nil, err
+		}
+		return settings, a.SavePositionSettings(settings)
+	}))
+
+	// Import, export and maintenance
+	mux.Handle("POST /api/trades/export", handler(func(r *http.Request) (interface{}, error) {
+		var body fileRequest
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		count, err := a.ExportTradesCSV(body.Path)
+		return map[string]int{"exported": count}, err
+	}))
+	mux.Handle("POST /api/trades/import", handler(func(r *http.Request) (interface{}, error) {
+		var body struct {
+			fileRequest
+			Mapping tradecsv.Mapping `json:"mapping"`
+			Mode    string           `json:"mode"` // add (default) or update
+		}
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.ImportTradesCSV(body.Path, body.Mapping, body.Mode, body.DryRun)
+	}))
+	mux.Handle("GET /api/brokers", handler(func(r *http.Request) (interface{}, error) {
+		return a.GetBrokerImporters(), nil
+	}))
+	mux.Handle("POST /api/brokers/import", handler(func(r *http.Request) (interface{}, error) {
+		var body struct {
+			fileRequest
+			Broker string `json:"broker"`
+		}
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.ImportBrokerCSV(body.Path, body.Broker, body.DryRun)
+	}))
+	mux.Handle("POST /api/backup/export", handler(func(r *http.Request) (interface{}, error) {
+		var body fileRequest
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.ExportBackup(body.Path)
+	}))
+	mux.Handle("POST /api/backup/preview", handler(func(r *http.Request) (interface{}, error) {
+		var body fileRequest
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.PreviewImport(body.Path, body.Mode)
+	}))
+	mux.Handle("POST /api/backup/import", handler(func(r *http.Request) (interface{}, error) {
+		var body fileRequest
+		if err := decode(r, &body); err != nil {
+			return nil, err
+		}
+		return a.ImportBackup(body.Path, body.Mode)
+	}))
+	mux.Handle("POST /api/maintenance/gc", handler(func(r *http.Request) (interface{}, error) {
+		if a.db == nil {
+			return nil, fmt.Errorf("database not initialized")
+		}
+		err := a.db.RunGC()
+		if errors.Is(err, badger.ErrNoRewrite) {
+			return map[string]bool{"rewritten": false}, nil
+		}
+		return map[string]bool{"rewritten": err == nil}, err
+	}))
+	mux.Handle("POST /api/maintenance/reindex", handler(func(r *http.Request) (interface{}, error) {
+		return a.RebuildIndexes()
+	}))
+
+	mux.Handle("/", handler(func(r *http.Request) (interface{}, error) {
+		return nil, &apiError{Status: http.StatusNotFound, Code: "not_found", Message: fmt.Sprintf("no endpoint %s %s", r.Method, r.URL.Path)}
+	}))
+
+	return s.authorize(mux)
+}
+
+// savedTrade is the response to saving a trade
+type savedTrade struct {
+	Trade *models.Trade `json:"trade"`
+	Check *risk.Check   `json:"check"`
+}
+
+// rollRequest is the body of a roll: the closing event and the new trade
+type rollRequest struct {
+	Event *models.TradeEvent `json:"event"`
+	Trade *models.Trade      `json:"trade"`
+}
+
+// fileRequest is the body of the endpoints that read or write a file
+type fileRequest struct {
+	Path   string `json:"path"`   // Path on the machine running the server
+	Mode   string `json:"mode"`   // Backup import mode, "replace" or "merge"
+	DryRun bool   `json:"dryRun"` // Report without saving
+}
+
+// authorize accepts only loopback clients presenting the API token
+func (s *apiServer) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil || !isLoopback(host) {
+			writeError(w, &apiError{Status: http.StatusForbidden, Code: "forbidden", Message: "the API only accepts local connections"})
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, &apiError{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "missing or invalid API token"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handler writes the result of an App call as JSON, or the error it returned
+func handler(fn func(r *http.Request) (interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := fn(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if result == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// writeError writes an error as {"error": {"status", "code", "message"}}. Missing
+// records are 404s, trades refused by the loss limits 409s, database failures 500s
+// and other failures of the App calls 422s.
+func writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	switch {
+	case errors.As(err, &e):
+	case errors.Is(err, badger.ErrKeyNotFound):
+		e = &apiError{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()}
+	case errors.Is(err, risk.ErrLossLimitLocked):
+		e = &apiError{Status: http.StatusConflict, Code: "loss_limit_locked", Message: err.Error()}
+	case database.IsStorageError(err):
+		log.Printf("API storage error: %v", err)
+		e = &apiError{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()}
+	default:
+		e = &apiError{Status: http.StatusUnprocessableEntity, Code: "failed", Message: err.Error()}
+	}
+	writeJSON(w, e.Status, map[string]*apiError{"error": e})
+}
+
+// writeJSON writes a value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
+
+// decode reads a JSON request body into v, rejecting unknown fields
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return badRequest("request body is empty")
+		}
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// parseQuery reads a repository query from the URL parameters
+func parseQuery(r *http.Request) (database.Query, error) {
+	params := r.URL.Query()
+	query := database.Query{
+		Symbol:   strings.ToUpper(params.Get("symbol")),
+		Sector:   params.Get("sector"),
+		Strategy: params.Get("strategy"),
+		SortBy:   params.Get("sortBy"),
+		SortDir:  params.Get("sortDir"),
+		Cursor:   params.Get("cursor"),
+	}
+
+	var err error
+	if v := params.Get("start"); v != "" {
+		if query.Start, err = parseTime(v); err != nil {
+			return query, err
+		}
+	}
+	if v := params.Get("end"); v != "" {
+		if query.End, err = parseTime(v); err != nil {
+			return query, err
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 0 {
+			return query, badRequest("invalid limit %q", v)
+		}
+	}
+	return query, nil
+}
+
+// parseFilter reads an analytics filter from the URL parameters
+func parseFilter(r *http.Request) (analytics.Filter, error) {
+	query, err := parseQuery(r)
+	return analytics.Filter{
+		Start:    query.Start,
+		End:      query.End,
+		Symbol:   query.Symbol,
+		Sector:   query.Sector,
+		Strategy: query.Strategy,
+	}, err
+}
+
+// parseTime parses a YYYY-MM-DD date or an RFC 3339 time
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, badRequest("invalid date %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// isLoopback reports whether a host is localhost or a loopback IP
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
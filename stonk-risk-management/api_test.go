@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/rules"
+)
+
+const testToken = "test-token"
+
+// newTestAPI serves the API of an App on an empty in-memory database
+func newTestAPI(t *testing.T) (*App, http.Handler) {
+	t.Helper()
+	db, err := database.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(database.MigrationOptions{DefaultRules: rules.Defaults()}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	app.open(db)
+
+	s, err := newAPIServer(app, "127.0.0.1:0", testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, s.routes()
+}
+
+// serve sends a request from a loopback client with the test token
+func serve(h http.Handler, method, path, body string, edit func(r *http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.RemoteAddr = "127.0.0.1:50000"
+	r.Header.Set("Authorization", "Bearer "+testToken)
+	if edit != nil {
+		edit(r)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// checkError checks the status and code of an error response
+func checkError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	var body struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not an error: %v", w.Body.String(), err)
+	}
+	if w.Code != status || body.Error.Status != status || body.Error.Code != code {
+		t.Errorf("response = %d %+v, want %d %s", w.Code, body.Error, status, code)
+	}
+}
+
+func TestAPIAuthorization(t *testing.T) {
+	_, h := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		edit   func(r *http.Request)
+		status int
+		code   string
+	}{
+		{"remote client", func(r *http.Request) { r.RemoteAddr = "192.0.2.10:50000" }, http.StatusForbidden, "forbidden"},
+		{"missing token", func(r *http.Request) { r.Header.Del("Authorization") }, http.StatusUnauthorized, "unauthorized"},
+		{"wrong token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized, "unauthorized"},
+		{"not a bearer token", func(r *http.Request) { r.Header.Set("Authorization", testToken) }, http.StatusUnauthorized, "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h, "GET", "/api/trades", "", tt.edit)
+			checkError(t, w, tt.status, tt.code)
+		})
+	}
+
+	if w := serve(h, "GET", "/api/trades", "", nil); w.Code != http.StatusOK {
+		t.Errorf("authorized request = %d %s, want 200", w.Code, w.Body.String())
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	_, h := newTestAPI(t)
+
+	t.Run("missing record", func(t *testing.T) {
+		w := serve(h, "GET", "/api/trades/no-such-trade", "", nil)
+		checkError(t, w, http.StatusNotFound, "not_found")
+	})
+	t.Run("unknown endpoint", func(t *testing.T) {
+		w := serve(h, "GET", "/api/nothing", "", nil)
+		checkError(t, w, http.StatusNotFound, "not_found")
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		w := serve(h, "POST", "/api/journal", `{"title": "Notes", "mood": "great"}`, nil)
+		checkError(t, w, http.StatusBadRequest, "bad_request")
+	})
+	t.Run("empty body", func(t *testing.T) {
+		w := serve(h, "POST", "/api/journal", "", nil)
+		checkError(t, w, http.StatusBadRequest, "bad_request")
+	})
+	t.Run("invalid request", func(t *testing.T) {
+		w := serve(h, "POST", "/api/cash-flows", `{"amount": 0}`, nil)
+		checkError(t, w, http.StatusUnprocessableEntity, "failed")
+	})
+}
+
+func TestAPIRiskBlockedTrade(t *testing.T) {
+	app, h := newTestAPI(t)
+
+	// A $200 budget per trade, enforced
+	settings := &models.PositionSettings{AccountValue: 10000, AccountRiskPerTrade: 2, RiskEnforcement: "block"}
+	if err := app.SavePositionSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// A long call bought at 5 risks $500
+	trade := `{"symbol": "AAPL", "sector": "Technology", "strategy": "Directional", "type": "Long Call",
+		"entryDate": "2026-10-12T00:00:00Z", "expirationDate": "2026-11-20T00:00:00Z", "entryPrice": 5,
+		"legs": [{"right": "call", "side": "buy", "quantity": 1, "strike": 230, "expiration": "2026-11-20T00:00:00Z", "fillPrice": 5}]}`
+	w := serve(h, "POST", "/api/trades", trade, nil)
+	checkError(t, w, http.StatusConflict, "risk_blocked")
+
+	trades, err := app.GetTrades()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 0 {
+		t.Errorf("%d trades saved, want none", len(trades))
+	}
+}
+
+func TestAPILossLimitLockedTrade(t *testing.T) {
+	app, h := newTestAPI(t)
+
+	settings := &models.PositionSettings{AccountValue: 10000, AccountRiskPerTrade: 10, DailyLossLimit: 3, WeeklyLossLimit: 6, RiskEnforcement: "warn"}
+	if err := app.SavePositionSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Losing $400 today locks the 3% daily limit
+	_, today, _ := risk.DayPeriod(time.Now())
+	loser := &models.Trade{Symbol: "AAPL", Sector: "Technology", Strategy: "Directional", Type: "Long Call",
+		EntryDate: today, ExpirationDate: today.AddDate(0, 1, 0), EntryPrice: 5}
+	loser.Legs = []models.Leg{models.SummaryLeg(loser)}
+	if _, err := app.SaveTrade(loser); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.CloseTrade(loser.ID, &models.TradeEvent{Date: today, Price: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	trade := fmt.Sprintf(`{"symbol": "MSFT", "sector": "Technology", "strategy": "Directional", "type": "Long Call",
+		"entryDate": %q, "expirationDate": "2026-12-18T00:00:00Z", "entryPrice": 1,
+		"legs": [{"quantity": 1, "fillPrice": 1}]}`, today.Format(time.RFC3339))
+	w := serve(h, "POST", "/api/trades", trade, nil)
+	checkError(t, w, http.StatusConflict, "loss_limit_locked")
+
+	trades, err := app.GetTrades()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 {
+		t.Errorf("%d trades saved, want only the losing trade", len(trades))
+	}
+}
+
+func TestAPIStorageError(t *testing.T) {
+	app, h := newTestAPI(t)
+	app.db.Close()
+
+	w := serve(h, "GET", "/api/trades", "", nil)
+	checkError(t, w, http.StatusInternalServerError, "storage_error")
+}
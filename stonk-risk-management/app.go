@@ -7,22 +7,40 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
+	"stonk-risk-management/pkg/analytics"
+	"stonk-risk-management/pkg/backup"
 	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/equity"
+	"stonk-risk-management/pkg/importers"
 	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/portfolio"
+	"stonk-risk-management/pkg/pricing"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/rules"
+	"stonk-risk-management/pkg/sizing"
+	"stonk-risk-management/pkg/tradecsv"
+	"stonk-risk-management/pkg/trading"
 
 	"github.com/dgraph-io/badger/v3"
 )
 
 // App struct
 type App struct {
-	ctx                context.Context
-	db                 *database.DB
-	riskRepository     *database.RiskRepository
-	stockRepository    *database.StockRepository
-	tradeRepository    *database.TradeRepository
-	positionRepository *database.PositionRepository
+	ctx                  context.Context
+	db                   *database.DB
+	riskRepository       *database.RiskRepository
+	stockRepository      *database.StockRepository
+	tradeRepository      *database.TradeRepository
+	tradeEventRepository *database.TradeEventRepository
+	positionRepository   *database.PositionRepository
+	journalRepository    *database.JournalRepository
+	lossLimitRepository  *database.LossLimitRepository
+	cashFlowRepository   *database.CashFlowRepository
+	ruleRepository       *database.RuleRepository
+	trading              *trading.Store
 }
 
 // NewApp creates a new App application struct
@@ -47,11 +65,42 @@ func (a *App) startup(ctx context.Context) {
 		log.Fatalf("Failed to open database: %v", err)
 	}
 
+	// Bring stored records up to the current schema before anything reads them
+	report, err := db.Migrate(database.MigrationOptions{DefaultRules: rules.Defaults()})
+	if err != nil {
+		if report != nil && report.SnapshotPath != "" {
+			log.Fatalf("Failed to migrate database (snapshot at %s): %v", report.SnapshotPath, err)
+		}
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if len(report.Applied) > 0 {
+		log.Printf("Migrated database schema from v%d to v%d (snapshot at %s)", report.FromVersion, report.ToVersion, report.SnapshotPath)
+	}
+	for _, m := range report.Applied {
+		database.LogBadKeys(m.Records, m.BadKeys, nil)
+	}
+
+	a.open(db)
+
+	// Record today's account value so the equity curve has one point per day the app is used
+	if _, err := a.RecordEquitySnapshot(); err != nil {
+		log.Printf("Failed to record equity snapshot: %v", err)
+	}
+}
+
+// open sets up the repositories on a migrated database
+func (a *App) open(db *database.DB) {
 	a.db = db
 	a.riskRepository = database.NewRiskRepository(db)
 	a.stockRepository = database.NewStockRepository(db)
 	a.tradeRepository = database.NewTradeRepository(db)
+	a.tradeEventRepository = database.NewTradeEventRepository(db)
 	a.positionRepository = database.NewPositionRepository(db)
+	a.journalRepository = database.NewJournalRepository(db)
+	a.lossLimitRepository = database.NewLossLimitRepository(db)
+	a.cashFlowRepository = database.NewCashFlowRepository(db)
+	a.ruleRepository = database.NewRuleRepository(db)
+	a.trading = trading.NewStore(db)
 }
 
 // shutdown is called when the app is closing
@@ -63,11 +112,21 @@ func (a *App) shutdown(ctx context.Context) {
 
 // GetRiskAssessments returns all risk assessments
 func (a *App) GetRiskAssessments() ([]*models.RiskAssessment, error) {
-	return a.riskRepository.GetAll()
+	return database.LogBadKeys(a.riskRepository.GetAll())
+}
+
+// QueryRiskAssessments returns one page of risk assessments matching a query
+func (a *App) QueryRiskAssessments(query database.Query) (*database.RiskAssessmentPage, error) {
+	return database.LogBadKeys(a.riskRepository.Query(query))
 }
 
 // SaveRiskAssessment saves a risk assessment
 func (a *App) SaveRiskAssessment(assessment *models.RiskAssessment) error {
+	if err := assessment.Validate(); err != nil {
+		return fmt.Errorf("invalid risk assessment: %w", err)
+	}
+
+	assessment.CalculateOverall()
 	return a.riskRepository.Save(assessment)
 }
 
@@ -76,14 +135,30 @@ func (a *App) DeleteRiskAssessment(id string) error {
 	return a.riskRepository.Delete(id)
 }
 
+// GetRecommendedPositionSize runs an assessment through the position sizing engine
+// using the saved position settings
+func (a *App) GetRecommendedPositionSize(assessment *models.RiskAssessment) (*sizing.Recommendation, error) {
+	settings, err := a.positionRepository.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	return a.trading.Recommendation(assessment, settings)
+}
+
 // GetStockRatings returns all stock ratings
 func (a *App) GetStockRatings() ([]*models.StockRating, error) {
-	return a.stockRepository.GetAll()
+	return database.LogBadKeys(a.stockRepository.GetAll())
+}
+
+// QueryStockRatings returns one page of stock ratings matching a query
+func (a *App) QueryStockRatings(query database.Query) (*database.StockRatingPage, error) {
+	return database.LogBadKeys(a.stockRepository.Query(query))
 }
 
 // GetStockRatingsByDate returns stock ratings for a specific date
 func (a *App) GetStockRatingsByDate(date time.Time) ([]*models.StockRating, error) {
-	return a.stockRepository.GetByDate(date)
+	return database.LogBadKeys(a.stockRepository.GetByDate(date))
 }
 
 // SaveStockRating saves a stock rating
@@ -91,6 +166,17 @@ func (a *App) SaveStockRating(rating *models.StockRating) error {
 	return a.stockRepository.Save(rating)
 }
 
+// SaveStockRatings saves several stock ratings; either all are saved or none are
+func (a *App) SaveStockRatings(ratings []*models.StockRating) error {
+	return a.stockRepository.SaveAll(ratings)
+}
+
+// ReplaceRatingsForDate saves the ratings for a day, replacing the ratings already
+// recorded that day for the same symbol and sector. Either all changes are saved or none are.
+func (a *App) ReplaceRatingsForDate(date time.Time, ratings []*models.StockRating) error {
+	return a.stockRepository.ReplaceForDate(date, ratings)
+}
+
 // DeleteStockRating deletes a stock rating
 func (a *App) DeleteStockRating(id string) error {
 	return a.stockRepository.Delete(id)
@@ -98,24 +184,11 @@ func (a *App) DeleteStockRating(id string) error {
 
 // GetLatestMarketRating returns the most recent market rating
 func (a *App) GetLatestMarketRating() (*models.StockRating, error) {
-	// Get all ratings for the "MARKET" symbol
-	ratings, err := a.stockRepository.GetBySymbol("MARKET")
+	rating, err := database.LogBadKeys(a.stockRepository.GetLatestBySymbol("MARKET"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch market ratings: %w", err)
 	}
-
-	// If no ratings found, return nil
-	if len(ratings) == 0 {
-		return nil, nil
-	}
-
-	// Sort by date (descending) to get the most recent one
-	sort.Slice(ratings, func(i, j int) bool {
-		return ratings[i].Date.After(ratings[j].Date)
-	})
-
-	// Return the first (most recent) rating
-	return ratings[0], nil
+	return rating, nil
 }
 
 // GetLatestSectorRating returns the most recent rating for a specific sector
@@ -124,83 +197,394 @@ func (a *App) GetLatestSectorRating(sector string) (*models.StockRating, error)
 		return nil, fmt.Errorf("sector cannot be empty")
 	}
 
-	// Get all ratings
-	allRatings, err := a.stockRepository.GetAll()
+	// Sector ratings are stored with the symbol "SECTOR"
+	rating, err := database.LogBadKeys(a.stockRepository.GetLatestBySymbolAndSector("SECTOR", sector))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch ratings: %w", err)
 	}
+	return rating, nil
+}
+
+// GetLatestStockRating returns the most recent rating for a specific stock symbol
+func (a *App) GetLatestStockRating(symbol string) (*models.StockRating, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol cannot be empty")
+	}
 
-	// Filter for sector ratings (where symbol is "SECTOR" and sector matches)
-	var sectorRatings []*models.StockRating
-	for _, rating := range allRatings {
-		if rating.Symbol == "SECTOR" && rating.Sector == sector {
-			sectorRatings = append(sectorRatings, rating)
-		}
+	rating, err := database.LogBadKeys(a.stockRepository.GetLatestBySymbol(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ratings for symbol %s: %w", symbol, err)
 	}
+	return rating, nil
+}
+
+// GetTrades returns all trades
+func (a *App) GetTrades() ([]*models.Trade, error) {
+	return database.LogBadKeys(a.tradeRepository.GetAll())
+}
+
+// QueryTrades returns one page of trades matching a query
+func (a *App) QueryTrades(query database.Query) (*database.TradePage, error) {
+	return database.LogBadKeys(a.tradeRepository.Query(query))
+}
 
-	// If no ratings found, return nil
-	if len(sectorRatings) == 0 {
-		return nil, nil
+// SaveTrade saves a trade after checking its max loss against the risk budget.
+// The returned check lists any violations; blocking violations prevent the save.
+func (a *App) SaveTrade(trade *models.Trade) (*risk.Check, error) {
+	return a.trading.SaveTrade(trade)
+}
+
+// CheckTradeRisk computes a trade's max loss and compares it to the per-trade
+// risk budget scaled by the current recommended position size
+func (a *App) CheckTradeRisk(trade *models.Trade) (*risk.Check, error) {
+	return a.trading.CheckRisk(trade)
+}
+
+// GetLossLimitStatus returns the daily and weekly losses against the loss limits.
+// Marks are optional per-unit prices of open trades, keyed by trade ID.
+func (a *App) GetLossLimitStatus(marks map[string]float64) (*risk.LossLimitStatus, error) {
+	return a.trading.LossLimitStatus(marks, time.Now())
+}
+
+// OverrideLossLimit lifts the loss-limit lock for the rest of the breached periods.
+// The override is recorded in the trade journal along with the reason.
+func (a *App) OverrideLossLimit(reason string) (*risk.LossLimitStatus, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason is required to override the loss limit")
 	}
 
-	// Sort by date (descending) to get the most recent one
-	sort.Slice(sectorRatings, func(i, j int) bool {
-		return sectorRatings[i].Date.After(sectorRatings[j].Date)
+	now := time.Now()
+	status, err := a.trading.LossLimitStatus(nil, now)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Locked {
+		return nil, fmt.Errorf("trading is not locked by loss limits")
+	}
+
+	entry := models.NewJournalEntry()
+	entry.Date = now
+	entry.Title = "Loss limit override"
+	entry.Outcome = models.OutcomeLoss
+	entry.PnLAmount = -overriddenLoss(status)
+	entry.WhatWentPoorly = status.Err().Error()
+	entry.OverrideReason = reason
+	entry.Tags = []string{"loss-limit-override"}
+
+	// The entry and the overrides that refer to it are saved together
+	err = a.db.Batch(func(tx *database.Tx) error {
+		if err := a.journalRepository.SaveTx(tx, entry); err != nil {
+			return fmt.Errorf("failed to save override journal entry: %w", err)
+		}
+		for _, p := range []*risk.PeriodLoss{&status.Daily, &status.Weekly} {
+			if !p.Locked() {
+				continue
+			}
+			p.Override = &models.LossLimitOverride{
+				Period:         p.Period,
+				PeriodKey:      p.Key,
+				Date:           now,
+				Reason:         reason,
+				JournalEntryID: entry.ID,
+			}
+			if err := a.lossLimitRepository.SaveTx(tx, p.Override); err != nil {
+				return fmt.Errorf("failed to save loss limit override: %w", err)
+			}
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	status.Locked = false
 
-	// Return the first (most recent) rating
-	return sectorRatings[0], nil
+	return status, nil
 }
 
-// GetLatestStockRating returns the most recent rating for a specific stock symbol
-func (a *App) GetLatestStockRating(symbol string) (*models.StockRating, error) {
-	if symbol == "" {
-		return nil, fmt.Errorf("symbol cannot be empty")
+// overriddenLoss returns the loss of the locked periods an override lifts; when both
+// are locked it is the larger one, usually the week's
+func overriddenLoss(status *risk.LossLimitStatus) float64 {
+	loss := 0.0
+	for _, p := range []risk.PeriodLoss{status.Daily, status.Weekly} {
+		if p.Locked() && p.Loss > loss {
+			loss = p.Loss
+		}
 	}
+	return loss
+}
 
-	// Get all ratings for the provided symbol
-	ratings, err := a.stockRepository.GetBySymbol(symbol)
+// GetEquityCurve returns the daily equity snapshots, including a live snapshot
+// for today, with the running peak, drawdowns and time under water
+func (a *App) GetEquityCurve() (*equity.Curve, error) {
+	settings, err := a.positionRepository.GetSettings()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch ratings for symbol %s: %w", symbol, err)
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
 	}
+	return a.trading.EquityCurve(settings)
+}
+
+// RecordEquitySnapshot saves today's account value, net deposits plus realized P&L
+func (a *App) RecordEquitySnapshot() (*models.EquitySnapshot, error) {
+	return a.trading.RecordEquitySnapshot()
+}
+
+// GetCashFlows returns the recorded deposits and withdrawals, oldest first
+func (a *App) GetCashFlows() ([]*models.CashFlow, error) {
+	return database.LogBadKeys(a.cashFlowRepository.GetAll())
+}
 
-	// If no ratings found, return nil
-	if len(ratings) == 0 {
-		return nil, nil
+// SaveCashFlow records a deposit (positive amount) or withdrawal (negative amount),
+// which moves the equity curve without counting as a gain or a drawdown
+func (a *App) SaveCashFlow(flow *models.CashFlow) error {
+	if err := flow.Validate(); err != nil {
+		return fmt.Errorf("invalid cash flow: %w", err)
+	}
+	if err := a.cashFlowRepository.Save(flow); err != nil {
+		return err
 	}
 
-	// Sort by date (descending) to get the most recent one
-	sort.Slice(ratings, func(i, j int) bool {
-		return ratings[i].Date.After(ratings[j].Date)
-	})
+	if _, err := a.RecordEquitySnapshot(); err != nil {
+		log.Printf("Failed to record equity snapshot: %v", err)
+	}
+	return nil
+}
 
-	// Return the first (most recent) rating
-	return ratings[0], nil
+// DeleteCashFlow deletes a deposit or withdrawal
+func (a *App) DeleteCashFlow(id string) error {
+	return a.cashFlowRepository.Delete(id)
 }
 
-// GetTrades returns all trades
-func (a *App) GetTrades() ([]*models.Trade, error) {
-	return a.tradeRepository.GetAll()
+// GetPerformanceReport computes win rate, expectancy, streaks, Sharpe and Sortino
+// ratios and the R-multiple distribution of the closed trades matching a filter,
+// overall and by strategy, sector, symbol, weekday and holding period
+func (a *App) GetPerformanceReport(filter analytics.Filter) (*analytics.Report, error) {
+	histories, err := a.trading.Histories()
+	if err != nil {
+		return nil, err
+	}
+	return analytics.Performance(histories, filter), nil
 }
 
-// SaveTrade saves a trade
-func (a *App) SaveTrade(trade *models.Trade) error {
-	// Basic validation before saving
-	if trade.Symbol == "" || trade.Sector == "" || trade.Strategy == "" || trade.Type == "" {
-		return fmt.Errorf("invalid trade data: missing required fields")
+// GetPsychologyReport pairs the closed trades matching a filter with the assessment
+// of their entry day and reports win rate, average P&L and risk rule violations by
+// score, and whether trades taken above the recommended size for that day's equity
+// underperformed
+func (a *App) GetPsychologyReport(filter analytics.Filter) (*analytics.PsychologyReport, error) {
+	histories, err := a.trading.Histories()
+	if err != nil {
+		return nil, err
 	}
 
-	// For backward compatibility, always set legNumber to 1
-	trade.LegNumber = 1
+	assessments, err := database.LogBadKeys(a.riskRepository.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch risk assessments: %w", err)
+	}
 
-	return a.tradeRepository.Save(trade)
+	settings, err := a.positionRepository.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	curve, err := a.trading.EquityCurve(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return analytics.Psychology(histories, assessments, curve.Snapshots, settings, filter), nil
 }
 
-// DeleteTrade deletes all legs associated with a trade ID
+// DeleteTrade deletes all legs associated with a trade ID along with its lifecycle events
 func (a *App) DeleteTrade(id string) error {
+	if err := a.tradeEventRepository.DeleteByTrade(id); err != nil {
+		return fmt.Errorf("failed to delete trade events: %w", err)
+	}
 	return a.tradeRepository.Delete(id)
 }
 
+// GetTradeHistory returns a trade with its lifecycle events, derived status and realized P&L
+func (a *App) GetTradeHistory(id string) (*models.TradeHistory, error) {
+	return a.trading.History(id)
+}
+
+// CloseTrade records a close, expiration, assignment or adjustment against a trade.
+// Without a quantity every open unit is closed; fewer units than are open records a
+// partial exit.
+func (a *App) CloseTrade(id string, event *models.TradeEvent) (*models.TradeHistory, error) {
+	return a.trading.CloseTrade(id, event)
+}
+
+// RollTrade closes open units of a trade and opens the new trade they were rolled into.
+// If the event quantity is zero all open units are rolled. The new trade and the roll
+// event are saved together, and a roll is allowed while the loss limits lock new trades.
+func (a *App) RollTrade(id string, event *models.TradeEvent, newTrade *models.Trade) (*models.TradeHistory, error) {
+	return a.trading.RollTrade(id, event, newTrade)
+}
+
+// GetTradeGreeks values a trade and its legs with Black-Scholes-Merton using
+// the supplied underlying price, implied volatility and rates
+func (a *App) GetTradeGreeks(id string, market pricing.Market) (*pricing.TradeGreeks, error) {
+	if market.Spot <= 0 {
+		return nil, fmt.Errorf("underlying price must be positive")
+	}
+	if market.Volatility < 0 {
+		return nil, fmt.Errorf("volatility cannot be negative")
+	}
+
+	trade, err := a.tradeRepository.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade %s: %w", id, err)
+	}
+
+	greeks := pricing.ForTrade(trade, market)
+	return &greeks, nil
+}
+
+// GetTradePayoff returns the profit or loss curve of a trade at expiration and on the
+// requested dates, with max profit, max loss and breakevens. Empty options use defaults.
+func (a *App) GetTradePayoff(id string, options pricing.PayoffOptions) (*pricing.Payoff, error) {
+	trade, err := a.tradeRepository.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade %s: %w", id, err)
+	}
+
+	return pricing.BuildPayoff(trade, options)
+}
+
+// GetPortfolioExposure aggregates the trades open on asOf by sector, symbol, strategy,
+// expiration week and direction. A zero asOf uses the current time.
+func (a *App) GetPortfolioExposure(asOf time.Time) (*portfolio.Exposure, error) {
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	trades, err := database.LogBadKeys(a.tradeRepository.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trades: %w", err)
+	}
+
+	events, err := database.LogBadKeys(a.tradeEventRepository.GetAllByTrade())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade events: %w", err)
+	}
+
+	settings, err := a.positionRepository.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	return portfolio.Aggregate(trades, events, settings, asOf), nil
+}
+
+// GetRules returns the warning rules, enabled or not
+func (a *App) GetRules() ([]*models.Rule, error) {
+	return database.LogBadKeys(a.ruleRepository.GetAll())
+}
+
+// GetRuleVariables returns the variables rule expressions can refer to
+func (a *App) GetRuleVariables() []rules.Variable {
+	return rules.Variables()
+}
+
+// SaveRule saves a warning rule after checking that its expression compiles
+func (a *App) SaveRule(rule *models.Rule) error {
+	if err := rules.Check(rule); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+	return a.ruleRepository.Save(rule)
+}
+
+// DeleteRule deletes a warning rule
+func (a *App) DeleteRule(id string) error {
+	return a.ruleRepository.Delete(id)
+}
+
+// EvaluateRules evaluates the enabled warning rules against today's assessment,
+// the settings, the trades and their P&L, and returns the rules that fired with
+// the values that made them fire
+func (a *App) EvaluateRules() (*rules.Evaluation, error) {
+	stored, err := database.LogBadKeys(a.ruleRepository.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rules: %w", err)
+	}
+
+	settings, err := a.positionRepository.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	now := time.Now()
+	in := rules.Input{Now: now, Settings: settings}
+
+	_, dayStart, dayEnd := risk.DayPeriod(now)
+	assessments, err := database.LogBadKeys(a.riskRepository.GetByDateRange(dayStart, dayEnd.Add(-time.Nanosecond)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch risk assessments: %w", err)
+	}
+
+	curve, err := a.trading.EquityCurve(settings)
+	if err != nil {
+		return nil, err
+	}
+	in.Drawdown = &curve.Drawdown
+
+	if len(assessments) > 0 {
+		in.Assessment = assessments[len(assessments)-1]
+		recommendation := sizing.Recommend(in.Assessment, settings)
+		recommendation.ApplyDrawdown(curve.Drawdown.Breached)
+		in.Recommendation = &recommendation
+	}
+
+	if in.Histories, err = a.trading.Histories(); err != nil {
+		return nil, err
+	}
+	if in.LossLimit, err = a.trading.LossLimitStatus(nil, now); err != nil {
+		return nil, err
+	}
+	if in.Exposure, err = a.GetPortfolioExposure(now); err != nil {
+		return nil, err
+	}
+
+	return rules.Evaluate(stored, rules.NewEnv(in)), nil
+}
+
+// GetJournalEntries returns all trade journal entries, most recent first
+func (a *App) GetJournalEntries() ([]*models.JournalEntry, error) {
+	return database.LogBadKeys(a.journalRepository.GetAll())
+}
+
+// GetJournalEntriesByTrade returns the journal entries linked to a trade
+func (a *App) GetJournalEntriesByTrade(tradeID string) ([]*models.JournalEntry, error) {
+	if tradeID == "" {
+		return nil, fmt.Errorf("trade ID cannot be empty")
+	}
+	return database.LogBadKeys(a.journalRepository.GetByTrade(tradeID))
+}
+
+// GetJournalEntriesByDateRange returns the journal entries between two dates (inclusive)
+func (a *App) GetJournalEntriesByDateRange(start, end time.Time) ([]*models.JournalEntry, error) {
+	return database.LogBadKeys(a.journalRepository.GetByDateRange(start, end))
+}
+
+// SaveJournalEntry saves a trade journal entry
+func (a *App) SaveJournalEntry(entry *models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("invalid journal entry: %w", err)
+	}
+
+	if entry.TradeID != "" {
+		if _, err := a.tradeRepository.Get(entry.TradeID); err != nil {
+			return fmt.Errorf("journal entry references unknown trade %s: %w", entry.TradeID, err)
+		}
+	}
+
+	return a.journalRepository.Save(entry)
+}
+
+// DeleteJournalEntry deletes a trade journal entry
+func (a *App) DeleteJournalEntry(id string) error {
+	return a.journalRepository.Delete(id)
+}
+
 // RunDatabaseMaintenance performs database maintenance tasks including garbage collection
 // Returns a success message or error message
 func (a *App) RunDatabaseMaintenance() string {
@@ -219,6 +603,196 @@ func (a *App) RunDatabaseMaintenance() string {
 	return "Database maintenance completed successfully. Freed up unused space."
 }
 
+// ExportTradesCSV writes all trades to a CSV file, one row per leg, ordered by entry date
+// Returns the number of trades exported
+func (a *App) ExportTradesCSV(path string) (int, error) {
+	trades, err := database.LogBadKeys(a.tradeRepository.GetAll())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch trades: %w", err)
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].EntryDate.Before(trades[j].EntryDate)
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer f.Close()
+
+	if err := tradecsv.Export(f, trades); err != nil {
+		return 0, fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return len(trades), f.Close()
+}
+
+// ImportTradesCSV reads trades from a CSV file using the given column mapping and
+// reports the outcome of every row. Trades are added under new IDs; in update mode
+// a trade_id naming a stored trade replaces it, keeping its events. Unless dryRun
+// is set, the valid trades are saved together; imported trades are history, so the
+// risk and loss limit checks of SaveTrade do not apply.
+func (a *App) ImportTradesCSV(path string, mapping tradecsv.Mapping, mode string, dryRun bool) (*tradecsv.Report, error) {
+	var update func(id string) bool
+	switch mode {
+	case "", tradecsv.ModeAdd:
+	case tradecsv.ModeUpdate:
+		update = func(id string) bool {
+			_, err := a.tradeRepository.Get(id)
+			return err == nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown import mode %q", mode)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+
+	trades, report, err := tradecsv.Import(f, mapping, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import trades: %w", err)
+	}
+	if dryRun || len(trades) == 0 {
+		return report, nil
+	}
+
+	err = a.db.Batch(func(tx *database.Tx) error {
+		for _, trade := range trades {
+			if err := a.tradeRepository.SaveTx(tx, trade); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported trades: %w", err)
+	}
+
+	report.Saved = true
+	return report, nil
+}
+
+// GetBrokerImporters returns the names of the supported broker CSV formats
+func (a *App) GetBrokerImporters() []string {
+	return importers.Names()
+}
+
+// ImportBrokerCSV imports trades and their closing events from a broker's
+// trade-history CSV. An empty broker detects the format from the file. Sectors
+// are taken from the latest stock rating of each symbol. Unless dryRun is set,
+// the trades and events are saved together.
+func (a *App) ImportBrokerCSV(path string, broker string, dryRun bool) (*importers.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open broker file: %w", err)
+	}
+	defer f.Close()
+
+	result, err := importers.Import(f, broker)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, trade := range result.Trades {
+		rating, err := database.LogBadKeys(a.stockRepository.GetLatestBySymbol(trade.Symbol))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up sector of %s: %w", trade.Symbol, err)
+		}
+		if rating != nil {
+			trade.Sector = rating.Sector
+		}
+	}
+
+	if dryRun || len(result.Trades)+len(result.Events) == 0 {
+		return result, nil
+	}
+
+	err = a.db.Batch(func(tx *database.Tx) error {
+		for _, trade := range result.Trades {
+			if err := a.tradeRepository.SaveTx(tx, trade); err != nil {
+				return err
+			}
+		}
+		for _, event := range result.Events {
+			if err := a.tradeEventRepository.SaveTx(tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported trades: %w", err)
+	}
+
+	result.Saved = true
+	return result, nil
+}
+
+// ExportBackup writes every record to a versioned, checksummed JSON backup.
+// Paths ending in .gz are gzip-compressed.
+func (a *App) ExportBackup(path string) (*backup.Summary, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return backup.NewStore(a.db).Export(path)
+}
+
+// PreviewImport reports what importing a backup would add, update, skip and remove.
+// Mode is "replace" or "merge".
+func (a *App) PreviewImport(path string, mode string) (*backup.Preview, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return backup.NewStore(a.db).Preview(path, mode)
+}
+
+// ImportBackup restores a backup. In "replace" mode the backup replaces all stored
+// data; in "merge" mode records are added or updated by ID and others are kept.
+func (a *App) ImportBackup(path string, mode string) (*backup.Preview, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return backup.NewStore(a.db).Import(path, mode)
+}
+
+// RebuildIndexes recreates the secondary indexes from the stored records
+// Returns the number of index entries written and the records that could not be read
+func (a *App) RebuildIndexes() (*database.ReindexReport, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.db.RebuildIndexes()
+}
+
+// MigrateDatabase runs the pending schema migrations. With dryRun the migrations run
+// on an in-memory copy and the report shows what they would change.
+func (a *App) MigrateDatabase(dryRun bool) (*database.MigrationReport, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.db.Migrate(database.MigrationOptions{DryRun: dryRun, DefaultRules: rules.Defaults()})
+}
+
+// GetDatabaseSnapshots returns the paths of the pre-migration snapshots, newest first
+func (a *App) GetDatabaseSnapshots() ([]string, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.db.ListSnapshots()
+}
+
+// RestoreDatabaseSnapshot replaces the database with a pre-migration snapshot to roll
+// back an upgrade. The restored data keeps the snapshot's schema version until the app
+// is restarted, which migrates it again; quit right away to go back to an older version.
+func (a *App) RestoreDatabaseSnapshot(path string) error {
+	if a.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return a.db.RestoreSnapshot(path)
+}
+
 // Greet returns a greeting for the given name
 func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
@@ -229,7 +803,15 @@ func (a *App) GetPositionSettings() (*models.PositionSettings, error) {
 	return a.positionRepository.GetSettings()
 }
 
-// SavePositionSettings saves the position settings
+// SavePositionSettings saves the position settings. The first account value
+// saved is recorded as the opening balance; after that, deposits and withdrawals
+// are recorded with SaveCashFlow.
 func (a *App) SavePositionSettings(settings *models.PositionSettings) error {
-	return a.positionRepository.SaveSettings(settings)
+	if err := a.positionRepository.SaveSettings(settings); err != nil {
+		return err
+	}
+	if err := a.cashFlowRepository.SaveOpeningBalance(settings.AccountValue); err != nil {
+		return fmt.Errorf("failed to record opening balance: %w", err)
+	}
+	return nil
 }
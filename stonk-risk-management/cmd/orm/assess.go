@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/sizing"
+)
+
+// assessAdd records a risk assessment and shows the position size it recommends
+func assessAdd(c *cli, args []string) error {
+	fs := newFlags("assess add", "")
+	day := fs.String("date", "", "assessment date, YYYY-MM-DD (default today)")
+	a := models.NewRiskAssessment()
+	fs.IntVar(&a.EmotionalScore, "emotional", 0, "emotional state, -3 (distressed) to +3 (euphoric)")
+	fs.IntVar(&a.FOMOScore, "fomo", 0, "fear of missing out, -3 to +3")
+	fs.IntVar(&a.BiasScore, "bias", 0, "market bias, -3 to +3 (does not affect sizing)")
+	fs.IntVar(&a.PhysicalScore, "physical", 0, "physical condition, -3 (exhausted) to +3 (excellent)")
+	fs.IntVar(&a.PLImpactScore, "pnl", 0, "impact of recent P&L, -3 to +3")
+	fs.IntVar(&a.OtherScore, "other", 0, "anything else, -3 to +3")
+	fs.StringVar(&a.Notes, "notes", "", "notes")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *day != "" {
+		d, err := parseDay(*day)
+		if err != nil {
+			return err
+		}
+		a.Date = d
+	}
+	if err := a.Validate(); err != nil {
+		return fmt.Errorf("invalid risk assessment: %w", err)
+	}
+	a.CalculateOverall()
+
+	if err := c.store.risk.Save(a); err != nil {
+		return fmt.Errorf("failed to save risk assessment: %w", err)
+	}
+
+	settings, err := c.store.positions.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load position settings: %w", err)
+	}
+	rec, err := c.store.trading.Recommendation(a, settings)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Assessment     *models.RiskAssessment `json:"assessment"`
+		Recommendation *sizing.Recommendation `json:"recommendation"`
+	}{a, rec}
+
+	return c.out.print(result, func(t *tabwriter.Writer) {
+		row(t, "Assessment", shortID(a.ID))
+		row(t, "Date", date(a.Date))
+		row(t, "Overall score", a.OverallScore)
+		row(t, "Recommended size", fmt.Sprintf("%d%%", rec.Percent))
+		row(t, "Dollar risk per trade", money(rec.RecommendedDollarRisk))
+		if rec.DrawdownBreached {
+			row(t, "Drawdown", "past tolerance, size reduced")
+		}
+		if rec.Advice.Title != "" {
+			row(t, "Advice", rec.Advice.Title)
+		}
+		for _, tip := range rec.Advice.Tips {
+			row(t, "", "- "+tip)
+		}
+	})
+}
+
+// assessList lists the most recent risk assessments
+func assessList(c *cli, args []string) error {
+	fs := newFlags("assess list", "")
+	limit := fs.Int("limit", 10, "number of assessments to show, newest first")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	page, err := database.LogBadKeys(c.store.risk.Query(database.Query{Limit: *limit}))
+	if err != nil {
+		return fmt.Errorf("failed to fetch risk assessments: %w", err)
+	}
+	assessments := page.Items
+
+	return c.out.print(assessments, func(t *tabwriter.Writer) {
+		row(t, "ID", "DATE", "EMOTIONAL", "FOMO", "BIAS", "PHYSICAL", "P&L", "OTHER", "OVERALL", "NOTES")
+		for _, a := range assessments {
+			row(t, shortID(a.ID), date(a.Date), a.EmotionalScore, a.FOMOScore, a.BiasScore,
+				a.PhysicalScore, a.PLImpactScore, a.OtherScore, a.OverallScore, a.Notes)
+		}
+	})
+}
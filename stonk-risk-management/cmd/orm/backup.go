@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"stonk-risk-management/pkg/backup"
+	"stonk-risk-management/pkg/database"
+)
+
+// backupExport writes every record to a checksummed JSON backup
+func backupExport(c *cli, args []string) error {
+	fs := newFlags("backup export", "PATH")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("backup export needs one PATH")
+	}
+
+	summary, err := backup.NewStore(c.store.db).Export(args[0])
+	if err != nil {
+		return err
+	}
+
+	return c.out.print(summary, func(t *tabwriter.Writer) {
+		row(t, "Path", summary.Path)
+		row(t, "Schema version", summary.SchemaVersion)
+		row(t, "Checksum", summary.Checksum)
+		names := make([]string, 0, len(summary.Counts))
+		for name := range summary.Counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			row(t, name, summary.Counts[name])
+		}
+	})
+}
+
+// backupImport previews a backup import, and applies it with --apply
+func backupImport(c *cli, args []string) error {
+	fs := newFlags("backup import", "PATH")
+	mode := fs.String("mode", backup.ModeMerge, "merge adds and updates records; replace also removes records not in the backup")
+	apply := fs.Bool("apply", false, "apply the import instead of only previewing it")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("backup import needs one PATH")
+	}
+
+	store := backup.NewStore(c.store.db)
+	var preview *backup.Preview
+	if *apply {
+		preview, err = store.Import(args[0], *mode)
+	} else {
+		preview, err = store.Preview(args[0], *mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.out.print(preview, func(t *tabwriter.Writer) {
+		row(t, "COLLECTION", "ADDED", "UPDATED", "UNCHANGED", "REMOVED")
+		for _, col := range preview.Collections {
+			row(t, col.Name, col.Added, col.Updated, col.Unchanged, col.Removed)
+		}
+		row(t)
+		if preview.Migrated {
+			row(t, fmt.Sprintf("Backup records upgraded from schema v%d to v%d", preview.SchemaVersion, database.LatestSchemaVersion()))
+		}
+		if preview.Applied {
+			row(t, fmt.Sprintf("Imported in %s mode", preview.Mode))
+		} else {
+			row(t, "Preview only; run again with --apply to import")
+		}
+		if len(preview.BadKeys) > 0 {
+			row(t, fmt.Sprintf("%d unreadable records left untouched", len(preview.BadKeys)))
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+)
+
+// cashDeposit records money paid into the account
+func cashDeposit(c *cli, args []string) error {
+	return saveCashFlow(c, "cash deposit", 1, args)
+}
+
+// cashWithdraw records money taken out of the account
+func cashWithdraw(c *cli, args []string) error {
+	return saveCashFlow(c, "cash withdraw", -1, args)
+}
+
+// saveCashFlow records a deposit or withdrawal of a positive AMOUNT, then the
+// day's equity snapshot so the drawdown starts from the new balance
+func saveCashFlow(c *cli, name string, sign float64, args []string) error {
+	fs := newFlags(name, "AMOUNT")
+	day := fs.String("date", "", "transfer date, YYYY-MM-DD (default today)")
+	notes := fs.String("notes", "", "notes")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("%s needs one AMOUNT", name)
+	}
+
+	amount, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || amount <= 0 {
+		return fmt.Errorf("AMOUNT must be a positive number, got %q", args[0])
+	}
+	flow := &models.CashFlow{Amount: sign * amount, Notes: *notes}
+	if flow.Date, err = parseDay(*day); err != nil {
+		return err
+	}
+
+	if err := c.store.cashFlows.Save(flow); err != nil {
+		return fmt.Errorf("failed to save cash flow: %w", err)
+	}
+	c.store.recordEquity()
+	return printCashFlows(c, []*models.CashFlow{flow})
+}
+
+// cashList lists the deposits and withdrawals, oldest first
+func cashList(c *cli, args []string) error {
+	fs := newFlags("cash list", "")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	flows, err := database.LogBadKeys(c.store.cashFlows.GetAll())
+	if err != nil {
+		return fmt.Errorf("failed to fetch cash flows: %w", err)
+	}
+	return printCashFlows(c, flows)
+}
+
+// printCashFlows writes cash flows as a table or JSON
+func printCashFlows(c *cli, flows []*models.CashFlow) error {
+	return c.out.print(flows, func(t *tabwriter.Writer) {
+		row(t, "ID", "DATE", "AMOUNT", "NOTES")
+		for _, f := range flows {
+			row(t, shortID(f.ID), f.Date.Format(dayLayout), money(f.Amount), f.Notes)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/dgraph-io/badger/v3"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/rules"
+)
+
+// dbGC reclaims space in the value log
+func dbGC(c *cli, args []string) error {
+	fs := newFlags("db gc", "")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	// Each run rewrites at most one value log file, so run until there is nothing left
+	rewritten := 0
+	for {
+		err := c.store.db.RunGC()
+		if errors.Is(err, badger.ErrNoRewrite) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("garbage collection failed: %w", err)
+		}
+		rewritten++
+	}
+
+	result := struct {
+		Rewritten int `json:"rewritten"` // Value log files rewritten
+	}{rewritten}
+	return c.out.print(result, func(t *tabwriter.Writer) {
+		if rewritten == 0 {
+			row(t, "No garbage collection needed")
+			return
+		}
+		row(t, fmt.Sprintf("Rewrote %d value log files", rewritten))
+	})
+}
+
+// dbReindex rebuilds the secondary indexes from the stored records
+func dbReindex(c *cli, args []string) error {
+	fs := newFlags("db reindex", "")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	report, err := c.store.db.RebuildIndexes()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild indexes: %w", err)
+	}
+
+	return c.out.print(report, func(t *tabwriter.Writer) {
+		row(t, fmt.Sprintf("Wrote %d index entries", report.Entries))
+		printBadKeys(t, report.BadKeys)
+	})
+}
+
+// dbMigrate runs the pending schema migrations, or reports what they would change with --dry-run
+func dbMigrate(c *cli, args []string) error {
+	fs := newFlags("db migrate", "")
+	dryRun := fs.Bool("dry-run", false, "migrate an in-memory copy and report the changes without writing them")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	report, err := c.store.db.Migrate(database.MigrationOptions{DryRun: *dryRun, DefaultRules: rules.Defaults()})
+	if err != nil {
+		if report != nil && report.SnapshotPath != "" {
+			return fmt.Errorf("failed to migrate database (snapshot at %s): %w", report.SnapshotPath, err)
+		}
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return c.out.print(report, func(t *tabwriter.Writer) {
+		if len(report.Applied) == 0 {
+			row(t, fmt.Sprintf("Schema is up to date at v%d", report.ToVersion))
+			return
+		}
+		row(t, "VERSION", "RECORDS", "DESCRIPTION")
+		var badKeys []string
+		for _, m := range report.Applied {
+			row(t, m.Version, m.Records, m.Description)
+			badKeys = append(badKeys, m.BadKeys...)
+		}
+		row(t)
+		printBadKeys(t, badKeys)
+		if report.DryRun {
+			row(t, fmt.Sprintf("Dry run from v%d to v%d; nothing was written", report.FromVersion, report.ToVersion))
+			return
+		}
+		row(t, fmt.Sprintf("Migrated from v%d to v%d", report.FromVersion, report.ToVersion))
+		row(t, "Snapshot", report.SnapshotPath)
+	})
+}
+
+// dbSnapshots lists the pre-migration snapshots
+func dbSnapshots(c *cli, args []string) error {
+	fs := newFlags("db snapshots", "")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	paths, err := c.store.db.ListSnapshots()
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	return c.out.print(paths, func(t *tabwriter.Writer) {
+		if len(paths) == 0 {
+			row(t, "No snapshots in "+c.store.db.SnapshotDir())
+			return
+		}
+		for _, path := range paths {
+			row(t, path)
+		}
+	})
+}
+
+// dbRestore replaces the database with a snapshot, only with --apply
+func dbRestore(c *cli, args []string) error {
+	fs := newFlags("db restore", "SNAPSHOT")
+	apply := fs.Bool("apply", false, "replace the database instead of only showing what would be restored")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("db restore needs one SNAPSHOT path")
+	}
+
+	current, err := c.store.db.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if *apply {
+		if err := c.store.db.RestoreSnapshot(args[0]); err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+	}
+	restored, err := c.store.db.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	result := struct {
+		Snapshot    string `json:"snapshot"`
+		Applied     bool   `json:"applied"`
+		FromVersion int    `json:"fromVersion"`
+		ToVersion   int    `json:"toVersion"` // Schema version after the restore, the current one when previewing
+	}{args[0], *apply, current, restored}
+	return c.out.print(result, func(t *tabwriter.Writer) {
+		if !*apply {
+			row(t, fmt.Sprintf("The database at schema v%d would be replaced by %s", current, args[0]))
+			row(t, "Run again with --apply to restore")
+			return
+		}
+		row(t, fmt.Sprintf("Restored %s; schema is now v%d", args[0], restored))
+		row(t, "The app and orm migrate it again when they next open it")
+	})
+}
+
+// printBadKeys lists the records that could not be read and were skipped
+func printBadKeys(t *tabwriter.Writer, badKeys []string) {
+	if len(badKeys) == 0 {
+		return
+	}
+	row(t, fmt.Sprintf("Skipped %d unreadable records:", len(badKeys)))
+	for _, key := range badKeys {
+		row(t, "  "+key)
+	}
+}
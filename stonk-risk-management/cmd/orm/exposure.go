@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/portfolio"
+)
+
+// exposureShow prints the open exposure of the portfolio
+func exposureShow(c *cli, args []string) error {
+	fs := newFlags("exposure", "")
+	day := fs.String("date", "", "as-of date, YYYY-MM-DD (default now)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	asOf := time.Now()
+	if *day != "" {
+		d, err := parseDay(*day)
+		if err != nil {
+			return err
+		}
+		asOf = d
+	}
+
+	settings, err := c.store.positions.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load position settings: %w", err)
+	}
+	trades, err := database.LogBadKeys(c.store.trades.GetAll())
+	if err != nil {
+		return fmt.Errorf("failed to fetch trades: %w", err)
+	}
+	events, err := database.LogBadKeys(c.store.events.GetAllByTrade())
+	if err != nil {
+		return fmt.Errorf("failed to fetch trade events: %w", err)
+	}
+
+	exposure := portfolio.Aggregate(trades, events, settings, asOf)
+
+	return c.out.print(exposure, func(t *tabwriter.Writer) {
+		status := "within limit"
+		if exposure.OverLimit {
+			status = "OVER LIMIT"
+		}
+		row(t, "Total exposure", money(exposure.TotalExposure), percent(exposure.TotalPercent),
+			fmt.Sprintf("limit %s", percent(exposure.MaxPortfolioExposure)), status)
+
+		for _, group := range []struct {
+			name    string
+			buckets []portfolio.Bucket
+		}{
+			{"SECTOR", exposure.BySector},
+			{"SYMBOL", exposure.BySymbol},
+			{"STRATEGY", exposure.ByStrategy},
+			{"EXPIRATION WEEK", exposure.ByExpirationWeek},
+			{"DIRECTION", exposure.ByDirection},
+		} {
+			row(t)
+			row(t, group.name, "EXPOSURE", "PERCENT", "TRADES")
+			for _, b := range group.buckets {
+				row(t, b.Key, money(b.Exposure), percent(b.Percent), b.Trades)
+			}
+		}
+	})
+}
@@ -0,0 +1,172 @@
+// Command orm is a terminal companion to the Options Risk Management app.
+// It reads and writes the same database, so it must not run while the app is open.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand such as "trade list"
+type command struct {
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+// commands are the subcommands by group and name. Groups with a single
+// action use the empty name.
+var commands = map[string]map[string]command{
+	"assess": {
+		"add":  {"Record a risk assessment and show the recommended position size", assessAdd},
+		"list": {"List recent risk assessments", assessList},
+	},
+	"rate": {
+		"market": {"Rate the market and optionally sectors for a day", rateMarket},
+		"stock":  {"Rate a stock", rateStock},
+		"list":   {"List stock, sector and market ratings", rateList},
+	},
+	"trade": {
+		"add":   {"Add a trade after the risk and loss limit checks", tradeAdd},
+		"list":  {"List trades with their status and realized P&L", tradeList},
+		"show":  {"Show a trade with its legs and events", tradeShow},
+		"close": {"Close, expire, assign or adjust a trade", tradeClose},
+		"roll":  {"Roll open units of a trade into a new trade", tradeRoll},
+	},
+	"exposure": {
+		"": {"Show open exposure by sector, symbol, strategy, week and direction", exposureShow},
+	},
+	"cash": {
+		"deposit":  {"Record a deposit, which raises the equity without counting as a gain", cashDeposit},
+		"withdraw": {"Record a withdrawal, which lowers the equity without counting as a drawdown", cashWithdraw},
+		"list":     {"List deposits and withdrawals", cashList},
+	},
+	"settings": {
+		"show": {"Show the position settings", settingsShow},
+		"set":  {"Change position settings, e.g. accountValue=25000", settingsSet},
+	},
+	"backup": {
+		"export": {"Write a JSON backup (gzip when the path ends in .gz)", backupExport},
+		"import": {"Preview or restore a backup", backupImport},
+	},
+	"db": {
+		"gc":        {"Reclaim space in the value log", dbGC},
+		"reindex":   {"Rebuild the secondary indexes", dbReindex},
+		"migrate":   {"Run pending schema migrations, or preview them with --dry-run", dbMigrate},
+		"snapshots": {"List the pre-migration snapshots", dbSnapshots},
+		"restore":   {"Replace the database with a pre-migration snapshot", dbRestore},
+	},
+}
+
+// unmigrated are the commands that open the database without migrating it first
+var unmigrated = map[string]bool{
+	"db migrate":   true,
+	"db snapshots": true,
+	"db restore":   true,
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "orm:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, opens the database and runs the subcommand
+func run(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	global := flag.NewFlagSet("orm", flag.ContinueOnError)
+	dbPath := global.String("db", filepath.Join(home, ".options-risk-management"), "database directory")
+	format := global.String("format", formatTable, "output format: table or json")
+	global.Usage = func() { usage(global) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *format != formatTable && *format != formatJSON {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	args = global.Args()
+	if len(args) == 0 {
+		usage(global)
+		return fmt.Errorf("no command given")
+	}
+
+	group, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	name, rest := "", args[1:]
+	if _, single := group[""]; !single {
+		if len(rest) == 0 {
+			return fmt.Errorf("%s needs a subcommand: %s", args[0], strings.Join(names(group), ", "))
+		}
+		name, rest = rest[0], rest[1:]
+	}
+	cmd, ok := group[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q; %s subcommands: %s", args[0]+" "+name, args[0], strings.Join(names(group), ", "))
+	}
+
+	s, err := openStore(*dbPath, !unmigrated[args[0]+" "+name])
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	err = cmd.run(&cli{store: s, out: newOutput(os.Stdout, *format)}, rest)
+	if errors.Is(err, errHelp) {
+		return nil
+	}
+	return err
+}
+
+// cli is the state shared by subcommands
+type cli struct {
+	store *store
+	out   *output
+}
+
+// usage prints the global flags and the list of commands
+func usage(global *flag.FlagSet) {
+	w := global.Output()
+	fmt.Fprintln(w, "Usage: orm [--db DIR] [--format table|json] <command> [subcommand] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	groups := make([]string, 0, len(commands))
+	for g := range commands {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	for _, g := range groups {
+		for _, name := range names(commands[g]) {
+			fmt.Fprintf(w, "  %-18s %s\n", strings.TrimSpace(g+" "+name), commands[g][name].summary)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	global.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run a subcommand with -h for its flags.")
+}
+
+// names returns the sorted subcommand names of a group
+func names(group map[string]command) []string {
+	list := make([]string, 0, len(group))
+	for name := range group {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
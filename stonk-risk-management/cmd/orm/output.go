@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// output writes command results as aligned tables or indented JSON
+type output struct {
+	w      io.Writer
+	format string
+}
+
+func newOutput(w io.Writer, format string) *output {
+	return &output{w: w, format: format}
+}
+
+// print writes v as JSON, or renders it with table in table format
+func (o *output) print(v interface{}, table func(t *tabwriter.Writer)) error {
+	if o.format == formatJSON {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	t := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	table(t)
+	return t.Flush()
+}
+
+// row writes one tab-separated table row
+func row(w io.Writer, values ...interface{}) {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = fmt.Sprint(v)
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
+
+// Table cell formatters
+func date(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+// shortID abbreviates an ID for tables; commands accept any unique prefix
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
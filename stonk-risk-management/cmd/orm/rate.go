@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+)
+
+// Symbols under which market and sector ratings are stored
+const (
+	marketSymbol = "MARKET"
+	sectorSymbol = "SECTOR"
+)
+
+// rateMarket replaces a day's market rating and any given sector ratings
+func rateMarket(c *cli, args []string) error {
+	fs := newFlags("rate market", "VALUE")
+	day := fs.String("date", "", "rating date, YYYY-MM-DD (default today)")
+	var sectors listFlag
+	fs.Var(&sectors, "sector", "sector rating as Name=VALUE, may be repeated")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("rate market needs one VALUE from %d to %d", models.MinAssessmentScore, models.MaxAssessmentScore)
+	}
+
+	d, err := parseDay(*day)
+	if err != nil {
+		return err
+	}
+	value, err := parseRating(args[0])
+	if err != nil {
+		return err
+	}
+
+	ratings := []*models.StockRating{marketRating(d, marketSymbol, marketSymbol, "market_rating", value)}
+	for _, s := range sectors {
+		name, v, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid sector rating %q, expected Name=VALUE", s)
+		}
+		value, err := parseRating(v)
+		if err != nil {
+			return fmt.Errorf("sector %s: %w", name, err)
+		}
+		ratings = append(ratings, marketRating(d, sectorSymbol, strings.TrimSpace(name), "sector_rating", value))
+	}
+
+	if err := c.store.stocks.ReplaceForDate(d, ratings); err != nil {
+		return fmt.Errorf("failed to save ratings: %w", err)
+	}
+	return printRatings(c, ratings)
+}
+
+// rateStock saves a rating of a single stock
+func rateStock(c *cli, args []string) error {
+	fs := newFlags("rate stock", "SYMBOL")
+	day := fs.String("date", "", "rating date, YYYY-MM-DD (default today)")
+	r := models.NewStockRating()
+	fs.StringVar(&r.Sector, "sector", "", "industry sector (required)")
+	fs.IntVar(&r.StockSentiment, "sentiment", 0, "stock sentiment, -3 to +3")
+	fs.IntVar(&r.Confidence, "confidence", 0, "confidence, 0 to 10")
+	fs.IntVar(&r.Enthusiasm, "enthusiasm", 0, "enthusiasm, 0 to 10")
+	fs.Float64Var(&r.PriceTarget, "target", 0, "price target")
+	fs.StringVar(&r.ChartPattern, "patterns", "", "comma-separated chart patterns")
+	fs.StringVar(&r.Notes, "notes", "", "notes")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("rate stock needs one SYMBOL")
+	}
+	if r.Sector == "" {
+		return fmt.Errorf("--sector is required")
+	}
+
+	if r.Date, err = parseDay(*day); err != nil {
+		return err
+	}
+	r.Symbol = strings.ToUpper(args[0])
+	if r.StockSentiment < models.MinAssessmentScore || r.StockSentiment > models.MaxAssessmentScore {
+		return fmt.Errorf("sentiment must be between %d and %d", models.MinAssessmentScore, models.MaxAssessmentScore)
+	}
+	if r.Confidence < 0 || r.Confidence > 10 || r.Enthusiasm < 0 || r.Enthusiasm > 10 {
+		return fmt.Errorf("confidence and enthusiasm must be between 0 and 10")
+	}
+
+	if err := c.store.stocks.Save(r); err != nil {
+		return fmt.Errorf("failed to save stock rating: %w", err)
+	}
+	return printRatings(c, []*models.StockRating{r})
+}
+
+// rateList lists ratings, newest first
+func rateList(c *cli, args []string) error {
+	fs := newFlags("rate list", "")
+	var q database.Query
+	from := fs.String("from", "", "earliest date, YYYY-MM-DD")
+	to := fs.String("to", "", "latest date, YYYY-MM-DD")
+	fs.StringVar(&q.Symbol, "symbol", "", "symbol, or MARKET or SECTOR")
+	fs.StringVar(&q.Sector, "sector", "", "sector")
+	fs.IntVar(&q.Limit, "limit", 20, "number of ratings to show")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var err error
+	if *from != "" {
+		if q.Start, err = parseDay(*from); err != nil {
+			return err
+		}
+	}
+	if *to != "" {
+		if q.End, err = parseDay(*to); err != nil {
+			return err
+		}
+	}
+	q.Symbol = strings.ToUpper(q.Symbol)
+
+	page, err := database.LogBadKeys(c.store.stocks.Query(q))
+	if err != nil {
+		return fmt.Errorf("failed to fetch stock ratings: %w", err)
+	}
+	return printRatings(c, page.Items)
+}
+
+// printRatings writes ratings as a table or JSON
+func printRatings(c *cli, ratings []*models.StockRating) error {
+	return c.out.print(ratings, func(t *tabwriter.Writer) {
+		row(t, "ID", "DATE", "SYMBOL", "SECTOR", "SENTIMENT", "CONFIDENCE", "ENTHUSIASM", "TARGET")
+		for _, r := range ratings {
+			row(t, shortID(r.ID), date(r.Date), r.Symbol, r.Sector, r.StockSentiment, r.Confidence, r.Enthusiasm, money(r.PriceTarget))
+		}
+	})
+}
+
+// marketRating builds a market or sector rating the way the dashboard stores them
+func marketRating(day time.Time, symbol, sector, kind string, value int) *models.StockRating {
+	notes, _ := json.Marshal(map[string]interface{}{"type": kind, "value": value})
+	return &models.StockRating{
+		Date:           day,
+		Symbol:         symbol,
+		Sector:         sector,
+		StockSentiment: value,
+		Notes:          string(notes),
+	}
+}
+
+// parseRating parses a market or sector rating from -3 to +3
+func parseRating(value string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+	if err != nil || v < models.MinAssessmentScore || v > models.MaxAssessmentScore {
+		return 0, fmt.Errorf("rating must be a whole number from %d to %d, got %q", models.MinAssessmentScore, models.MaxAssessmentScore, value)
+	}
+	return v, nil
+}
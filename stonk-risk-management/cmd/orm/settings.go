@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+)
+
+// settingsShow prints the position settings
+func settingsShow(c *cli, args []string) error {
+	fs := newFlags("settings show", "")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	settings, err := c.store.positions.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load position settings: %w", err)
+	}
+	return printSettings(c, settings)
+}
+
+// settingsSet changes settings given as name=value, using the JSON field names
+func settingsSet(c *cli, args []string) error {
+	fs := newFlags("settings set", "NAME=VALUE...")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("settings set needs at least one NAME=VALUE")
+	}
+
+	settings, err := c.store.positions.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load position settings: %w", err)
+	}
+	fields, err := settingsFields(settings)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid setting %q, expected NAME=VALUE", arg)
+		}
+		key, ok := fieldName(fields, name)
+		if !ok {
+			return fmt.Errorf("unknown setting %q; settings: %s", name, strings.Join(sortedKeys(fields), ", "))
+		}
+
+		switch fields[key].(type) {
+		case float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f < 0 {
+				return fmt.Errorf("%s must be a non-negative number, got %q", key, value)
+			}
+			fields[key] = f
+		default:
+			fields[key] = value
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	updated := &models.PositionSettings{}
+	if err := json.Unmarshal(data, updated); err != nil {
+		return err
+	}
+	switch updated.RiskEnforcement {
+	case "", risk.EnforcementOff, risk.EnforcementWarn, risk.EnforcementBlock:
+	default:
+		return fmt.Errorf("riskEnforcement must be %s, %s or %s", risk.EnforcementOff, risk.EnforcementWarn, risk.EnforcementBlock)
+	}
+
+	if err := c.store.positions.SaveSettings(updated); err != nil {
+		return fmt.Errorf("failed to save position settings: %w", err)
+	}
+	if err := c.store.cashFlows.SaveOpeningBalance(updated.AccountValue); err != nil {
+		return fmt.Errorf("failed to record opening balance: %w", err)
+	}
+	return printSettings(c, updated)
+}
+
+// printSettings writes the settings as a name/value table or JSON
+func printSettings(c *cli, settings *models.PositionSettings) error {
+	fields, err := settingsFields(settings)
+	if err != nil {
+		return err
+	}
+	return c.out.print(settings, func(t *tabwriter.Writer) {
+		for _, key := range sortedKeys(fields) {
+			row(t, key, fields[key])
+		}
+	})
+}
+
+// settingsFields returns the settings keyed by their JSON field names
+func settingsFields(settings *models.PositionSettings) (map[string]interface{}, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// fieldName matches a setting name ignoring case
+func fieldName(fields map[string]interface{}, name string) (string, bool) {
+	for key := range fields {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/rules"
+	"stonk-risk-management/pkg/trading"
+)
+
+// store holds the database and its repositories
+type store struct {
+	db        *database.DB
+	risk      *database.RiskRepository
+	stocks    *database.StockRepository
+	trades    *database.TradeRepository
+	events    *database.TradeEventRepository
+	positions *database.PositionRepository
+	cashFlows *database.CashFlowRepository
+	trading   *trading.Store
+}
+
+// openStore opens the database and, when migrate is set, brings it up to the current
+// schema as the app does on startup
+func openStore(path string, migrate bool) (*store, error) {
+	db, err := database.New(path)
+	if err != nil {
+		if strings.Contains(err.Error(), "directory lock") {
+			return nil, fmt.Errorf("database %s is in use; close the app first", path)
+		}
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	if migrate {
+		report, err := db.Migrate(database.MigrationOptions{DefaultRules: rules.Defaults()})
+		if err != nil {
+			db.Close()
+			if report != nil && report.SnapshotPath != "" {
+				return nil, fmt.Errorf("failed to migrate database (snapshot at %s): %w", report.SnapshotPath, err)
+			}
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		if len(report.Applied) > 0 {
+			log.Printf("Migrated database schema from v%d to v%d (snapshot at %s)", report.FromVersion, report.ToVersion, report.SnapshotPath)
+		}
+		for _, m := range report.Applied {
+			database.LogBadKeys(m.Records, m.BadKeys, nil)
+		}
+	}
+
+	return &store{
+		db:        db,
+		risk:      database.NewRiskRepository(db),
+		stocks:    database.NewStockRepository(db),
+		trades:    database.NewTradeRepository(db),
+		events:    database.NewTradeEventRepository(db),
+		positions: database.NewPositionRepository(db),
+		cashFlows: database.NewCashFlowRepository(db),
+		trading:   trading.NewStore(db),
+	}, nil
+}
+
+// close closes the database
+func (s *store) close() {
+	if err := s.db.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+}
+
+// recordEquity saves today's account value after a change to the trades or cash flows
+func (s *store) recordEquity() {
+	if _, err := s.trading.RecordEquitySnapshot(); err != nil {
+		log.Printf("Failed to record equity snapshot: %v", err)
+	}
+}
+
+// resolveTradeID expands a unique prefix of a trade ID, as shown by trade list
+func (s *store) resolveTradeID(prefix string) (string, error) {
+	keys, err := s.db.GetKeysWithPrefix("trade:" + prefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up trade %s: %w", prefix, err)
+	}
+	switch len(keys) {
+	case 0:
+		return "", fmt.Errorf("no trade with ID %s", prefix)
+	case 1:
+		return strings.TrimPrefix(keys[0], "trade:"), nil
+	default:
+		return "", fmt.Errorf("trade ID %s is ambiguous (%d matches)", prefix, len(keys))
+	}
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+	"stonk-risk-management/pkg/risk"
+)
+
+// tradeAdd saves a new trade, refusing it like the app does while a loss limit
+// is breached or when it breaks the risk budget under "block" enforcement
+func tradeAdd(c *cli, args []string) error {
+	fs := newFlags("trade add", "")
+	build := tradeFlags(fs, nil)
+	dryRun := fs.Bool("dry-run", false, "run the checks without saving")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	trade, err := build()
+	if err != nil {
+		return err
+	}
+
+	var check *risk.Check
+	if *dryRun {
+		check, err = c.store.trading.CheckTrade(trade, true)
+	} else {
+		check, err = c.store.trading.SaveTrade(trade)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Trade *models.Trade `json:"trade"`
+		Check *risk.Check   `json:"check"`
+		Saved bool          `json:"saved"`
+	}{trade, check, !*dryRun}
+
+	return c.out.print(result, func(t *tabwriter.Writer) {
+		printTrade(t, models.NewTradeHistory(trade, nil))
+		row(t)
+		maxLoss := money(check.MaxLoss)
+		if check.MaxLossUnlimited {
+			maxLoss = "unlimited"
+		}
+		row(t, "Max loss", maxLoss)
+		row(t, "Risk budget", fmt.Sprintf("%s (%d%% of %s)", money(check.Budget), check.RecommendedPercent, money(check.BaseBudget)))
+		for _, v := range check.Violations {
+			row(t, "Warning", v.Message)
+		}
+		if *dryRun {
+			row(t, "Dry run", "trade not saved")
+		}
+	})
+}
+
+// tradeFlags adds the flags describing a trade to fs and returns a function that
+// builds the trade once they are parsed. The symbol, sector and type default to
+// those of base when it is not nil.
+func tradeFlags(fs *flag.FlagSet, base *models.Trade) func() (*models.Trade, error) {
+	var symbol, sector, strategyType string
+	if base != nil {
+		symbol, sector, strategyType = base.Symbol, base.Sector, base.Type
+	}
+
+	trade := &models.Trade{LegNumber: 1}
+	fs.StringVar(&trade.Symbol, "symbol", symbol, "underlying symbol (required)")
+	fs.StringVar(&trade.Sector, "sector", sector, "industry sector (required)")
+	fs.StringVar(&strategyType, "type", strategyType, "strategy type, e.g. \"bull put\" or \"Iron Condor\" (required)")
+	entry := fs.String("entry", "", "entry date, YYYY-MM-DD (default today)")
+	expiration := fs.String("expiration", "", "expiration date, YYYY-MM-DD (default the latest leg expiration)")
+	fs.Float64Var(&trade.EntryPrice, "price", 0, "net price per unit (default the net of the leg fills)")
+	fs.Float64Var(&trade.Stop, "stop", 0, "stop loss price")
+	fs.Float64Var(&trade.Target, "target", 0, "price target")
+	fs.StringVar(&trade.Timeframe, "timeframe", "", "timeframe, e.g. \"2 weeks\"")
+	fs.StringVar(&trade.Notes, "notes", "", "notes")
+	var legs listFlag
+	fs.Var(&legs, "leg", "leg as \"SIDE QTY RIGHT STRIKE EXPIRATION [@PRICE]\", \"SIDE QTY OCC-SYMBOL [@PRICE]\" or \"buy QTY stock [@PRICE]\", may be repeated")
+
+	return func() (*models.Trade, error) {
+		trade.Symbol = strings.ToUpper(trade.Symbol)
+		if trade.Symbol == "" || trade.Sector == "" || strategyType == "" {
+			return nil, fmt.Errorf("--symbol, --sector and --type are required")
+		}
+		t, ok := models.NormalizeStrategyType(strategyType)
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy type %q", strategyType)
+		}
+		trade.Type, trade.Strategy = t, models.StrategyCategoryFor(t)
+
+		var err error
+		if trade.EntryDate, err = parseDay(*entry); err != nil {
+			return nil, err
+		}
+		if *expiration != "" {
+			if trade.ExpirationDate, err = parseDay(*expiration); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, spec := range legs {
+			leg, err := parseLeg(trade.Symbol, spec)
+			if err != nil {
+				return nil, err
+			}
+			trade.Legs = append(trade.Legs, leg)
+			if leg.Expiration.After(trade.ExpirationDate) {
+				trade.ExpirationDate = leg.Expiration
+			}
+		}
+		if trade.ExpirationDate.IsZero() {
+			return nil, fmt.Errorf("--expiration is required for a trade without option legs")
+		}
+		if len(trade.Legs) == 0 {
+			trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+		} else if trade.EntryPrice == 0 {
+			trade.EntryPrice = math.Abs(trade.EntryValue())
+		}
+		if err := trade.ValidateLegs(); err != nil {
+			return nil, fmt.Errorf("invalid trade legs: %w", err)
+		}
+		return trade, nil
+	}
+}
+
+// tradeList lists trades with their derived status, newest first
+func tradeList(c *cli, args []string) error {
+	fs := newFlags("trade list", "")
+	var q database.Query
+	from := fs.String("from", "", "earliest entry date, YYYY-MM-DD")
+	to := fs.String("to", "", "latest entry date, YYYY-MM-DD")
+	fs.StringVar(&q.Symbol, "symbol", "", "symbol")
+	fs.StringVar(&q.Sector, "sector", "", "sector")
+	fs.StringVar(&q.Strategy, "strategy", "", "strategy category or type")
+	fs.StringVar(&q.SortBy, "sort", "entryDate", "sort by entryDate, expiration or symbol")
+	fs.StringVar(&q.SortDir, "dir", database.SortDesc, "sort direction, asc or desc")
+	status := fs.String("status", "", "only trades with this status: open, closed, expired, assigned or rolled")
+	limit := fs.Int("limit", 25, "number of trades to show")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var err error
+	if *from != "" {
+		if q.Start, err = parseDay(*from); err != nil {
+			return err
+		}
+	}
+	if *to != "" {
+		if q.End, err = parseDay(*to); err != nil {
+			return err
+		}
+	}
+	q.Symbol = strings.ToUpper(q.Symbol)
+	if q.Strategy != "" {
+		if t, ok := models.NormalizeStrategyType(q.Strategy); ok {
+			q.Strategy = t
+		} else if category, ok := models.NormalizeStrategyCategory(q.Strategy); ok {
+			q.Strategy = category
+		}
+	}
+
+	events, err := database.LogBadKeys(c.store.events.GetAllByTrade())
+	if err != nil {
+		return fmt.Errorf("failed to fetch trade events: %w", err)
+	}
+
+	// Status is derived from events, so pages are read until enough trades match
+	histories := []*models.TradeHistory{}
+	q.Limit = *limit
+	for len(histories) < *limit {
+		page, err := database.LogBadKeys(c.store.trades.Query(q))
+		if err != nil {
+			return fmt.Errorf("failed to fetch trades: %w", err)
+		}
+		for _, trade := range page.Items {
+			h := models.NewTradeHistory(trade, events[trade.ID])
+			if (*status == "" || h.Status == *status) && len(histories) < *limit {
+				histories = append(histories, h)
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	return c.out.print(histories, func(t *tabwriter.Writer) {
+		row(t, "ID", "ENTRY", "SYMBOL", "TYPE", "EXPIRATION", "QTY", "OPEN", "STATUS", "REALIZED")
+		for _, h := range histories {
+			row(t, shortID(h.Trade.ID), date(h.Trade.EntryDate), h.Trade.Symbol, h.Trade.Type,
+				date(h.Trade.ExpirationDate), h.Quantity, h.OpenQuantity, h.Status, money(h.RealizedPL))
+		}
+	})
+}
+
+// tradeShow shows a trade with its legs and events
+func tradeShow(c *cli, args []string) error {
+	fs := newFlags("trade show", "ID")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("trade show needs one trade ID")
+	}
+
+	id, err := c.store.resolveTradeID(args[0])
+	if err != nil {
+		return err
+	}
+	h, err := c.store.trading.History(id)
+	if err != nil {
+		return err
+	}
+
+	return c.out.print(h, func(t *tabwriter.Writer) {
+		printTrade(t, h)
+	})
+}
+
+// tradeClose records a close, expiration, assignment or adjustment against a trade
+func tradeClose(c *cli, args []string) error {
+	fs := newFlags("trade close", "ID")
+	event := &models.TradeEvent{}
+	fs.StringVar(&event.Type, "type", models.EventClose, "close, expire, assign or adjust; use trade roll to roll")
+	fs.Float64Var(&event.Price, "price", 0, "per-unit exit price; for adjust the net credit (+) or debit (-)")
+	fs.IntVar(&event.Quantity, "qty", 0, "units to close (default all open units)")
+	fs.Float64Var(&event.Fees, "fees", 0, "commissions and fees")
+	fs.StringVar(&event.Notes, "notes", "", "notes")
+	day := fs.String("date", "", "event date, YYYY-MM-DD (default today)")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("trade close needs one trade ID")
+	}
+	if event.Type == models.EventRoll {
+		return fmt.Errorf("use trade roll to roll a trade")
+	}
+
+	id, err := c.store.resolveTradeID(args[0])
+	if err != nil {
+		return err
+	}
+	if event.Date, err = parseDay(*day); err != nil {
+		return err
+	}
+
+	h, err := c.store.trading.CloseTrade(id, event)
+	if err != nil {
+		return err
+	}
+	return c.out.print(h, func(t *tabwriter.Writer) {
+		printTrade(t, h)
+	})
+}
+
+// tradeRoll closes open units of a trade and opens the trade they were rolled into.
+// The new trade takes the flags of trade add; its symbol, sector and type default to
+// those of the rolled trade and its entry date to the roll date.
+func tradeRoll(c *cli, args []string) error {
+	fs := newFlags("trade roll", "ID")
+	event := &models.TradeEvent{}
+	fs.Float64Var(&event.Price, "close-price", 0, "per-unit price the rolled units are closed at")
+	fs.IntVar(&event.Quantity, "qty", 0, "units to roll (default all open units)")
+	fs.Float64Var(&event.Fees, "fees", 0, "commissions and fees of closing the rolled units")
+	day := fs.String("date", "", "roll date, YYYY-MM-DD (default today)")
+
+	// The rolled trade is needed for the defaults before the flags are parsed
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var err error
+		if id, err = c.store.resolveTradeID(args[0]); err != nil {
+			return err
+		}
+	}
+	var base *models.Trade
+	if id != "" {
+		h, err := c.store.trading.History(id)
+		if err != nil {
+			return err
+		}
+		base = h.Trade
+	}
+	build := tradeFlags(fs, base)
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || id == "" {
+		return fmt.Errorf("trade roll needs the trade ID before the flags")
+	}
+
+	if event.Date, err = parseDay(*day); err != nil {
+		return err
+	}
+	if fs.Lookup("entry").Value.String() == "" {
+		fs.Set("entry", event.Date.Format(dayLayout))
+	}
+	newTrade, err := build()
+	if err != nil {
+		return err
+	}
+
+	h, err := c.store.trading.RollTrade(id, event, newTrade)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Rolled *models.TradeHistory `json:"rolled"`
+		Trade  *models.Trade        `json:"trade"`
+	}{h, newTrade}
+
+	return c.out.print(result, func(t *tabwriter.Writer) {
+		printTrade(t, h)
+		row(t)
+		printTrade(t, models.NewTradeHistory(newTrade, nil))
+	})
+}
+
+// printTrade writes a trade history as a table
+func printTrade(t *tabwriter.Writer, h *models.TradeHistory) {
+	trade := h.Trade
+	id := trade.ID
+	if id == "" {
+		id = "(not saved)"
+	}
+	row(t, "Trade", id)
+	row(t, "Symbol", trade.Symbol, trade.Sector)
+	row(t, "Strategy", trade.Type, trade.Strategy)
+	row(t, "Entry", date(trade.EntryDate), money(trade.EntryPrice))
+	row(t, "Expiration", date(trade.ExpirationDate))
+	row(t, "Status", h.Status, fmt.Sprintf("%d of %d open", h.OpenQuantity, h.Quantity))
+	row(t, "Realized P&L", money(h.RealizedPL))
+
+	if trade.HasLegDetail() {
+		row(t)
+		row(t, "LEG", "SIDE", "QTY", "RIGHT", "STRIKE", "EXPIRATION", "FILL", "SYMBOL")
+		for i, leg := range trade.Legs {
+			row(t, i+1, leg.Side, leg.Quantity, leg.Right, leg.Strike, date(leg.Expiration), money(leg.FillPrice), leg.Symbol)
+		}
+	}
+
+	if len(h.Events) > 0 {
+		row(t)
+		row(t, "EVENT", "DATE", "QTY", "PRICE", "FEES", "P&L")
+		for _, e := range h.Events {
+			row(t, e.Type, date(e.Date), e.Quantity, money(e.Price), money(e.Fees), money(h.EventPL(e)))
+		}
+	}
+}
+
+// parseLeg parses a --leg value. Quantities are contracts, or shares for stock.
+func parseLeg(underlying, spec string) (models.Leg, error) {
+	var leg models.Leg
+
+	contract, price, hasPrice := strings.Cut(spec, "@")
+	if hasPrice {
+		p, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+		if err != nil {
+			return leg, fmt.Errorf("leg %q: invalid price %q", spec, price)
+		}
+		leg.FillPrice = p
+	}
+
+	fields := strings.Fields(contract)
+	if len(fields) < 3 {
+		return leg, fmt.Errorf("leg %q: expected SIDE QTY and a contract", spec)
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case models.SideBuy:
+		leg.Side = models.SideBuy
+	case models.SideSell:
+		leg.Side = models.SideSell
+	default:
+		return leg, fmt.Errorf("leg %q: side must be buy or sell", spec)
+	}
+
+	q, err := strconv.Atoi(fields[1])
+	if err != nil || q <= 0 {
+		return leg, fmt.Errorf("leg %q: invalid quantity %q", spec, fields[1])
+	}
+	leg.Quantity = q
+
+	rest := fields[2:]
+	switch right := strings.ToLower(rest[0]); {
+	case len(rest) == 1 && (right == models.RightStock || right == "shares"):
+		leg.Right = models.RightStock
+	case len(rest) == 3 && (right == models.RightCall || right == models.RightPut):
+		leg.Right = right
+		if leg.Strike, err = strconv.ParseFloat(rest[1], 64); err != nil {
+			return leg, fmt.Errorf("leg %q: invalid strike %q", spec, rest[1])
+		}
+		if leg.Expiration, err = parseDay(rest[2]); err != nil {
+			return leg, fmt.Errorf("leg %q: %w", spec, err)
+		}
+	default:
+		symbol, err := occ.Parse(strings.Join(rest, ""))
+		if err != nil {
+			return leg, fmt.Errorf("leg %q: expected RIGHT STRIKE EXPIRATION, an OCC symbol or stock", spec)
+		}
+		if symbol.Root != occ.Root(underlying) {
+			return leg, fmt.Errorf("leg %q: OCC symbol is for %s, not %s", spec, symbol.Root, underlying)
+		}
+		leg.Right, leg.Strike, leg.Expiration = symbol.Right, symbol.Strike, symbol.Expiration
+	}
+
+	return leg, nil
+}
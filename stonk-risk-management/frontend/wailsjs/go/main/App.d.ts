@@ -1,38 +1,135 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {models} from '../models';
+import {risk} from '../models';
+import {rules} from '../models';
+import {backup} from '../models';
+import {equity} from '../models';
 import {time} from '../models';
+import {analytics} from '../models';
+import {portfolio} from '../models';
+import {sizing} from '../models';
+import {pricing} from '../models';
+import {importers} from '../models';
+import {tradecsv} from '../models';
+import {database} from '../models';
+
+export function CheckTradeRisk(arg1:models.Trade):Promise<risk.Check>;
+
+export function CloseTrade(arg1:string,arg2:models.TradeEvent):Promise<models.TradeHistory>;
+
+export function DeleteCashFlow(arg1:string):Promise<void>;
+
+export function DeleteJournalEntry(arg1:string):Promise<void>;
 
 export function DeleteRiskAssessment(arg1:string):Promise<void>;
 
+export function DeleteRule(arg1:string):Promise<void>;
+
 export function DeleteStockRating(arg1:string):Promise<void>;
 
 export function DeleteTrade(arg1:string):Promise<void>;
 
+export function EvaluateRules():Promise<rules.Evaluation>;
+
+export function ExportBackup(arg1:string):Promise<backup.Summary>;
+
+export function ExportTradesCSV(arg1:string):Promise<number>;
+
+export function GetBrokerImporters():Promise<Array<string>>;
+
+export function GetCashFlows():Promise<Array<models.CashFlow>>;
+
+export function GetDatabaseSnapshots():Promise<Array<string>>;
+
+export function GetEquityCurve():Promise<equity.Curve>;
+
+export function GetJournalEntries():Promise<Array<models.JournalEntry>>;
+
+export function GetJournalEntriesByDateRange(arg1:time.Time,arg2:time.Time):Promise<Array<models.JournalEntry>>;
+
+export function GetJournalEntriesByTrade(arg1:string):Promise<Array<models.JournalEntry>>;
+
 export function GetLatestMarketRating():Promise<models.StockRating>;
 
 export function GetLatestSectorRating(arg1:string):Promise<models.StockRating>;
 
 export function GetLatestStockRating(arg1:string):Promise<models.StockRating>;
 
+export function GetLossLimitStatus(arg1:Record<string, number>):Promise<risk.LossLimitStatus>;
+
+export function GetPerformanceReport(arg1:analytics.Filter):Promise<analytics.Report>;
+
+export function GetPortfolioExposure(arg1:time.Time):Promise<portfolio.Exposure>;
+
 export function GetPositionSettings():Promise<models.PositionSettings>;
 
+export function GetPsychologyReport(arg1:analytics.Filter):Promise<analytics.PsychologyReport>;
+
+export function GetRecommendedPositionSize(arg1:models.RiskAssessment):Promise<sizing.Recommendation>;
+
 export function GetRiskAssessments():Promise<Array<models.RiskAssessment>>;
 
+export function GetRuleVariables():Promise<Array<rules.Variable>>;
+
+export function GetRules():Promise<Array<models.Rule>>;
+
 export function GetStockRatings():Promise<Array<models.StockRating>>;
 
 export function GetStockRatingsByDate(arg1:time.Time):Promise<Array<models.StockRating>>;
 
+export function GetTradeGreeks(arg1:string,arg2:pricing.Market):Promise<pricing.TradeGreeks>;
+
+export function GetTradeHistory(arg1:string):Promise<models.TradeHistory>;
+
+export function GetTradePayoff(arg1:string,arg2:pricing.PayoffOptions):Promise<pricing.Payoff>;
+
 export function GetTrades():Promise<Array<models.Trade>>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportBackup(arg1:string,arg2:string):Promise<backup.Preview>;
+
+export function ImportBrokerCSV(arg1:string,arg2:string,arg3:boolean):Promise<importers.Result>;
+
+export function ImportTradesCSV(arg1:string,arg2:tradecsv.Mapping,arg3:string,arg4:boolean):Promise<tradecsv.Report>;
+
+export function MigrateDatabase(arg1:boolean):Promise<database.MigrationReport>;
+
+export function OverrideLossLimit(arg1:string):Promise<risk.LossLimitStatus>;
+
+export function PreviewImport(arg1:string,arg2:string):Promise<backup.Preview>;
+
+export function QueryRiskAssessments(arg1:database.Query):Promise<database.RiskAssessmentPage>;
+
+export function QueryStockRatings(arg1:database.Query):Promise<database.StockRatingPage>;
+
+export function QueryTrades(arg1:database.Query):Promise<database.TradePage>;
+
+export function RebuildIndexes():Promise<database.ReindexReport>;
+
+export function RecordEquitySnapshot():Promise<models.EquitySnapshot>;
+
+export function ReplaceRatingsForDate(arg1:time.Time,arg2:Array<models.StockRating>):Promise<void>;
+
+export function RestoreDatabaseSnapshot(arg1:string):Promise<void>;
+
+export function RollTrade(arg1:string,arg2:models.TradeEvent,arg3:models.Trade):Promise<models.TradeHistory>;
+
 export function RunDatabaseMaintenance():Promise<string>;
 
+export function SaveCashFlow(arg1:models.CashFlow):Promise<void>;
+
+export function SaveJournalEntry(arg1:models.JournalEntry):Promise<void>;
+
 export function SavePositionSettings(arg1:models.PositionSettings):Promise<void>;
 
 export function SaveRiskAssessment(arg1:models.RiskAssessment):Promise<void>;
 
+export function SaveRule(arg1:models.Rule):Promise<void>;
+
 export function SaveStockRating(arg1:models.StockRating):Promise<void>;
 
-export function SaveTrade(arg1:models.Trade):Promise<void>;
+export function SaveStockRatings(arg1:Array<models.StockRating>):Promise<void>;
+
+export function SaveTrade(arg1:models.Trade):Promise<risk.Check>;
@@ -2,10 +2,30 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckTradeRisk(arg1) {
+  return window['go']['main']['App']['CheckTradeRisk'](arg1);
+}
+
+export function CloseTrade(arg1, arg2) {
+  return window['go']['main']['App']['CloseTrade'](arg1, arg2);
+}
+
+export function DeleteCashFlow(arg1) {
+  return window['go']['main']['App']['DeleteCashFlow'](arg1);
+}
+
+export function DeleteJournalEntry(arg1) {
+  return window['go']['main']['App']['DeleteJournalEntry'](arg1);
+}
+
 export function DeleteRiskAssessment(arg1) {
   return window['go']['main']['App']['DeleteRiskAssessment'](arg1);
 }
 
+export function DeleteRule(arg1) {
+  return window['go']['main']['App']['DeleteRule'](arg1);
+}
+
 export function DeleteStockRating(arg1) {
   return window['go']['main']['App']['DeleteStockRating'](arg1);
 }
@@ -14,6 +34,46 @@ export function DeleteTrade(arg1) {
   return window['go']['main']['App']['DeleteTrade'](arg1);
 }
 
+export function EvaluateRules() {
+  return window['go']['main']['App']['EvaluateRules']();
+}
+
+export function ExportBackup(arg1) {
+  return window['go']['main']['App']['ExportBackup'](arg1);
+}
+
+export function ExportTradesCSV(arg1) {
+  return window['go']['main']['App']['ExportTradesCSV'](arg1);
+}
+
+export function GetBrokerImporters() {
+  return window['go']['main']['App']['GetBrokerImporters']();
+}
+
+export function GetCashFlows() {
+  return window['go']['main']['App']['GetCashFlows']();
+}
+
+export function GetDatabaseSnapshots() {
+  return window['go']['main']['App']['GetDatabaseSnapshots']();
+}
+
+export function GetEquityCurve() {
+  return window['go']['main']['App']['GetEquityCurve']();
+}
+
+export function GetJournalEntries() {
+  return window['go']['main']['App']['GetJournalEntries']();
+}
+
+export function GetJournalEntriesByDateRange(arg1, arg2) {
+  return window['go']['main']['App']['GetJournalEntriesByDateRange'](arg1, arg2);
+}
+
+export function GetJournalEntriesByTrade(arg1) {
+  return window['go']['main']['App']['GetJournalEntriesByTrade'](arg1);
+}
+
 export function GetLatestMarketRating() {
   return window['go']['main']['App']['GetLatestMarketRating']();
 }
@@ -26,14 +86,42 @@ export function GetLatestStockRating(arg1) {
   return window['go']['main']['App']['GetLatestStockRating'](arg1);
 }
 
+export function GetLossLimitStatus(arg1) {
+  return window['go']['main']['App']['GetLossLimitStatus'](arg1);
+}
+
+export function GetPerformanceReport(arg1) {
+  return window['go']['main']['App']['GetPerformanceReport'](arg1);
+}
+
+export function GetPortfolioExposure(arg1) {
+  return window['go']['main']['App']['GetPortfolioExposure'](arg1);
+}
+
 export function GetPositionSettings() {
   return window['go']['main']['App']['GetPositionSettings']();
 }
 
+export function GetPsychologyReport(arg1) {
+  return window['go']['main']['App']['GetPsychologyReport'](arg1);
+}
+
+export function GetRecommendedPositionSize(arg1) {
+  return window['go']['main']['App']['GetRecommendedPositionSize'](arg1);
+}
+
 export function GetRiskAssessments() {
   return window['go']['main']['App']['GetRiskAssessments']();
 }
 
+export function GetRuleVariables() {
+  return window['go']['main']['App']['GetRuleVariables']();
+}
+
+export function GetRules() {
+  return window['go']['main']['App']['GetRules']();
+}
+
 export function GetStockRatings() {
   return window['go']['main']['App']['GetStockRatings']();
 }
@@ -42,6 +130,18 @@ export function GetStockRatingsByDate(arg1) {
   return window['go']['main']['App']['GetStockRatingsByDate'](arg1);
 }
 
+export function GetTradeGreeks(arg1, arg2) {
+  return window['go']['main']['App']['GetTradeGreeks'](arg1, arg2);
+}
+
+export function GetTradeHistory(arg1) {
+  return window['go']['main']['App']['GetTradeHistory'](arg1);
+}
+
+export function GetTradePayoff(arg1, arg2) {
+  return window['go']['main']['App']['GetTradePayoff'](arg1, arg2);
+}
+
 export function GetTrades() {
   return window['go']['main']['App']['GetTrades']();
 }
@@ -50,10 +150,74 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportBackup(arg1, arg2) {
+  return window['go']['main']['App']['ImportBackup'](arg1, arg2);
+}
+
+export function ImportBrokerCSV(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportBrokerCSV'](arg1, arg2, arg3);
+}
+
+export function ImportTradesCSV(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ImportTradesCSV'](arg1, arg2, arg3, arg4);
+}
+
+export function MigrateDatabase(arg1) {
+  return window['go']['main']['App']['MigrateDatabase'](arg1);
+}
+
+export function OverrideLossLimit(arg1) {
+  return window['go']['main']['App']['OverrideLossLimit'](arg1);
+}
+
+export function PreviewImport(arg1, arg2) {
+  return window['go']['main']['App']['PreviewImport'](arg1, arg2);
+}
+
+export function QueryRiskAssessments(arg1) {
+  return window['go']['main']['App']['QueryRiskAssessments'](arg1);
+}
+
+export function QueryStockRatings(arg1) {
+  return window['go']['main']['App']['QueryStockRatings'](arg1);
+}
+
+export function QueryTrades(arg1) {
+  return window['go']['main']['App']['QueryTrades'](arg1);
+}
+
+export function RebuildIndexes() {
+  return window['go']['main']['App']['RebuildIndexes']();
+}
+
+export function RecordEquitySnapshot() {
+  return window['go']['main']['App']['RecordEquitySnapshot']();
+}
+
+export function ReplaceRatingsForDate(arg1, arg2) {
+  return window['go']['main']['App']['ReplaceRatingsForDate'](arg1, arg2);
+}
+
+export function RestoreDatabaseSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreDatabaseSnapshot'](arg1);
+}
+
+export function RollTrade(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RollTrade'](arg1, arg2, arg3);
+}
+
 export function RunDatabaseMaintenance() {
   return window['go']['main']['App']['RunDatabaseMaintenance']();
 }
 
+export function SaveCashFlow(arg1) {
+  return window['go']['main']['App']['SaveCashFlow'](arg1);
+}
+
+export function SaveJournalEntry(arg1) {
+  return window['go']['main']['App']['SaveJournalEntry'](arg1);
+}
+
 export function SavePositionSettings(arg1) {
   return window['go']['main']['App']['SavePositionSettings'](arg1);
 }
@@ -62,10 +226,18 @@ export function SaveRiskAssessment(arg1) {
   return window['go']['main']['App']['SaveRiskAssessment'](arg1);
 }
 
+export function SaveRule(arg1) {
+  return window['go']['main']['App']['SaveRule'](arg1);
+}
+
 export function SaveStockRating(arg1) {
   return window['go']['main']['App']['SaveStockRating'](arg1);
 }
 
+export function SaveStockRatings(arg1) {
+  return window['go']['main']['App']['SaveStockRatings'](arg1);
+}
+
 export function SaveTrade(arg1) {
   return window['go']['main']['App']['SaveTrade'](arg1);
 }
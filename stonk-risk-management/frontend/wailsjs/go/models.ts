@@ -1,59 +1,107 @@
-export namespace models {
+export namespace analytics {
 	
-	export class PositionSettings {
-	    accountValue: number;
-	    accountRiskPerTrade: number;
-	    maxPortfolioExposure: number;
-	    stopLossPercent: number;
-	    riskRewardRatio: number;
-	    dailyLossLimit: number;
-	    weeklyLossLimit: number;
-	    positionScaling: number;
-	    correlationAdjustment: number;
-	    volatilityMultiplier: number;
-	    maxDrawdownTolerance: number;
+	export class DailyPL {
+	    date: time.Time;
+	    pl: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new PositionSettings(source);
+	        return new DailyPL(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.accountValue = source["accountValue"];
-	        this.accountRiskPerTrade = source["accountRiskPerTrade"];
-	        this.maxPortfolioExposure = source["maxPortfolioExposure"];
-	        this.stopLossPercent = source["stopLossPercent"];
-	        this.riskRewardRatio = source["riskRewardRatio"];
-	        this.dailyLossLimit = source["dailyLossLimit"];
-	        this.weeklyLossLimit = source["weeklyLossLimit"];
-	        this.positionScaling = source["positionScaling"];
-	        this.correlationAdjustment = source["correlationAdjustment"];
-	        this.volatilityMultiplier = source["volatilityMultiplier"];
-	        this.maxDrawdownTolerance = source["maxDrawdownTolerance"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.pl = source["pl"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class RiskAssessment {
-	    id: string;
-	    date: time.Time;
-	    emotionalScore: number;
-	    fomoScore: number;
-	    biasScore: number;
-	    overallScore: number;
-	    notes: string;
+	export class Stats {
+	    trades: number;
+	    wins: number;
+	    losses: number;
+	    breakeven: number;
+	    winRate: number;
+	    netPL: number;
+	    grossProfit: number;
+	    grossLoss: number;
+	    averageWin: number;
+	    averageLoss: number;
+	    profitFactor: number;
+	    payoffRatio: number;
+	    expectancy: number;
+	    largestWin: number;
+	    largestLoss: number;
+	    maxWinStreak: number;
+	    maxLossStreak: number;
+	    averageR: number;
+	    rTrades: number;
+	    averageHoldingDays: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new RiskAssessment(source);
+	        return new Stats(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.date = this.convertValues(source["date"], time.Time);
-	        this.emotionalScore = source["emotionalScore"];
-	        this.fomoScore = source["fomoScore"];
-	        this.biasScore = source["biasScore"];
-	        this.overallScore = source["overallScore"];
-	        this.notes = source["notes"];
+	        this.trades = source["trades"];
+	        this.wins = source["wins"];
+	        this.losses = source["losses"];
+	        this.breakeven = source["breakeven"];
+	        this.winRate = source["winRate"];
+	        this.netPL = source["netPL"];
+	        this.grossProfit = source["grossProfit"];
+	        this.grossLoss = source["grossLoss"];
+	        this.averageWin = source["averageWin"];
+	        this.averageLoss = source["averageLoss"];
+	        this.profitFactor = source["profitFactor"];
+	        this.payoffRatio = source["payoffRatio"];
+	        this.expectancy = source["expectancy"];
+	        this.largestWin = source["largestWin"];
+	        this.largestLoss = source["largestLoss"];
+	        this.maxWinStreak = source["maxWinStreak"];
+	        this.maxLossStreak = source["maxLossStreak"];
+	        this.averageR = source["averageR"];
+	        this.rTrades = source["rTrades"];
+	        this.averageHoldingDays = source["averageHoldingDays"];
+	    }
+	}
+	export class ScoreBucket {
+	    key: string;
+	    min: number;
+	    max: number;
+	    stats: Stats;
+	    violations: number;
+	    violationRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScoreBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.stats = this.convertValues(source["stats"], Stats);
+	        this.violations = source["violations"];
+	        this.violationRate = source["violationRate"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -74,34 +122,56 @@ export namespace models {
 		    return a;
 		}
 	}
-	export class StockRating {
-	    id: string;
-	    date: time.Time;
+	export class Dimension {
+	    name: string;
+	    buckets: ScoreBucket[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Dimension(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.buckets = this.convertValues(source["buckets"], ScoreBucket);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Filter {
+	    start: time.Time;
+	    end: time.Time;
 	    symbol: string;
 	    sector: string;
-	    stockSentiment: number;
-	    priceTarget: number;
-	    confidence: number;
-	    notes: string;
-	    enthusiasm: number;
-	    chartPattern: string;
+	    strategy: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new StockRating(source);
+	        return new Filter(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.date = this.convertValues(source["date"], time.Time);
+	        this.start = this.convertValues(source["start"], time.Time);
+	        this.end = this.convertValues(source["end"], time.Time);
 	        this.symbol = source["symbol"];
 	        this.sector = source["sector"];
-	        this.stockSentiment = source["stockSentiment"];
-	        this.priceTarget = source["priceTarget"];
-	        this.confidence = source["confidence"];
-	        this.notes = source["notes"];
-	        this.enthusiasm = source["enthusiasm"];
-	        this.chartPattern = source["chartPattern"];
+	        this.strategy = source["strategy"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -122,48 +192,38 @@ export namespace models {
 		    return a;
 		}
 	}
-	export class Trade {
-	    id: string;
+	export class Outcome {
+	    tradeId: string;
 	    symbol: string;
 	    sector: string;
 	    strategy: string;
 	    type: string;
-	    week: number;
+	    status: string;
 	    entryDate: time.Time;
-	    expirationDate: time.Time;
-	    entryPrice: number;
-	    notes: string;
-	    legNumber: number;
-	    isMultiLeg: boolean;
-	    shortLegExp: string;
-	    timeframe: string;
-	    entry: number;
-	    stop: number;
-	    target: number;
+	    closedDate: time.Time;
+	    holdingDays: number;
+	    pl: number;
+	    risk: number;
+	    rMultiple?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Trade(source);
+	        return new Outcome(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
+	        this.tradeId = source["tradeId"];
 	        this.symbol = source["symbol"];
 	        this.sector = source["sector"];
 	        this.strategy = source["strategy"];
 	        this.type = source["type"];
-	        this.week = source["week"];
+	        this.status = source["status"];
 	        this.entryDate = this.convertValues(source["entryDate"], time.Time);
-	        this.expirationDate = this.convertValues(source["expirationDate"], time.Time);
-	        this.entryPrice = source["entryPrice"];
-	        this.notes = source["notes"];
-	        this.legNumber = source["legNumber"];
-	        this.isMultiLeg = source["isMultiLeg"];
-	        this.shortLegExp = source["shortLegExp"];
-	        this.timeframe = source["timeframe"];
-	        this.entry = source["entry"];
-	        this.stop = source["stop"];
-	        this.target = source["target"];
+	        this.closedDate = this.convertValues(source["closedDate"], time.Time);
+	        this.holdingDays = source["holdingDays"];
+	        this.pl = source["pl"];
+	        this.risk = source["risk"];
+	        this.rMultiple = source["rMultiple"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -184,22 +244,2097 @@ export namespace models {
 		    return a;
 		}
 	}
-
-}
-
-export namespace time {
+	export class PairedOutcome {
+	    tradeId: string;
+	    symbol: string;
+	    sector: string;
+	    strategy: string;
+	    type: string;
+	    status: string;
+	    entryDate: time.Time;
+	    closedDate: time.Time;
+	    holdingDays: number;
+	    pl: number;
+	    risk: number;
+	    rMultiple?: number;
+	    assessmentId: string;
+	    recommendedPercent: number;
+	    budget: number;
+	    oversized: boolean;
+	    violations: risk.Violation[];
 	
-	export class Time {
+	    static createFrom(source: any = {}) {
+	        return new PairedOutcome(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tradeId = source["tradeId"];
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.strategy = source["strategy"];
+	        this.type = source["type"];
+	        this.status = source["status"];
+	        this.entryDate = this.convertValues(source["entryDate"], time.Time);
+	        this.closedDate = this.convertValues(source["closedDate"], time.Time);
+	        this.holdingDays = source["holdingDays"];
+	        this.pl = source["pl"];
+	        this.risk = source["risk"];
+	        this.rMultiple = source["rMultiple"];
+	        this.assessmentId = source["assessmentId"];
+	        this.recommendedPercent = source["recommendedPercent"];
+	        this.budget = source["budget"];
+	        this.oversized = source["oversized"];
+	        this.violations = this.convertValues(source["violations"], risk.Violation);
+	    }
 	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SizeComparison {
+	    oversized: Stats;
+	    withinSize: Stats;
+	    underperformed: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new Time(source);
+	        return new SizeComparison(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.oversized = this.convertValues(source["oversized"], Stats);
+	        this.withinSize = this.convertValues(source["withinSize"], Stats);
+	        this.underperformed = source["underperformed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PsychologyReport {
+	    filter: Filter;
+	    paired: number;
+	    unpaired: number;
+	    unassessed: Stats;
+	    dimensions: Dimension[];
+	    size: SizeComparison;
+	    trades: PairedOutcome[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PsychologyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filter = this.convertValues(source["filter"], Filter);
+	        this.paired = source["paired"];
+	        this.unpaired = source["unpaired"];
+	        this.unassessed = this.convertValues(source["unassessed"], Stats);
+	        this.dimensions = this.convertValues(source["dimensions"], Dimension);
+	        this.size = this.convertValues(source["size"], SizeComparison);
+	        this.trades = this.convertValues(source["trades"], PairedOutcome);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RBucket {
+	    key: string;
+	    min?: number;
+	    max?: number;
+	    trades: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RBucket(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.trades = source["trades"];
+	    }
+	}
+	export class Slice {
+	    key: string;
+	    stats: Stats;
+	
+	    static createFrom(source: any = {}) {
+	        return new Slice(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.stats = this.convertValues(source["stats"], Stats);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Report {
+	    filter: Filter;
+	    overall: Stats;
+	    currentStreak: number;
+	    dailyPL: DailyPL[];
+	    sharpe: number;
+	    sortino: number;
+	    rMultiples: RBucket[];
+	    byStrategy: Slice[];
+	    bySector: Slice[];
+	    bySymbol: Slice[];
+	    byWeekday: Slice[];
+	    byHoldingPeriod: Slice[];
+	    outcomes: Outcome[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filter = this.convertValues(source["filter"], Filter);
+	        this.overall = this.convertValues(source["overall"], Stats);
+	        this.currentStreak = source["currentStreak"];
+	        this.dailyPL = this.convertValues(source["dailyPL"], DailyPL);
+	        this.sharpe = source["sharpe"];
+	        this.sortino = source["sortino"];
+	        this.rMultiples = this.convertValues(source["rMultiples"], RBucket);
+	        this.byStrategy = this.convertValues(source["byStrategy"], Slice);
+	        this.bySector = this.convertValues(source["bySector"], Slice);
+	        this.bySymbol = this.convertValues(source["bySymbol"], Slice);
+	        this.byWeekday = this.convertValues(source["byWeekday"], Slice);
+	        this.byHoldingPeriod = this.convertValues(source["byHoldingPeriod"], Slice);
+	        this.outcomes = this.convertValues(source["outcomes"], Outcome);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+
+}
+
+export namespace backup {
+	
+	export class CollectionPreview {
+	    name: string;
+	    added: number;
+	    updated: number;
+	    unchanged: number;
+	    removed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CollectionPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	        this.unchanged = source["unchanged"];
+	        this.removed = source["removed"];
+	    }
+	}
+	export class Preview {
+	    path: string;
+	    mode: string;
+	    createdAt: time.Time;
+	    schemaVersion: number;
+	    migrated: boolean;
+	    collections: CollectionPreview[];
+	    badKeys: string[];
+	    applied: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.mode = source["mode"];
+	        this.createdAt = this.convertValues(source["createdAt"], time.Time);
+	        this.schemaVersion = source["schemaVersion"];
+	        this.migrated = source["migrated"];
+	        this.collections = this.convertValues(source["collections"], CollectionPreview);
+	        this.badKeys = source["badKeys"];
+	        this.applied = source["applied"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Summary {
+	    path: string;
+	    createdAt: time.Time;
+	    schemaVersion: number;
+	    checksum: string;
+	    counts: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.createdAt = this.convertValues(source["createdAt"], time.Time);
+	        this.schemaVersion = source["schemaVersion"];
+	        this.checksum = source["checksum"];
+	        this.counts = source["counts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace database {
+	
+	export class AppliedMigration {
+	    version: number;
+	    description: string;
+	    records: number;
+	    badKeys: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AppliedMigration(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.description = source["description"];
+	        this.records = source["records"];
+	        this.badKeys = source["badKeys"];
+	    }
+	}
+	export class MigrationReport {
+	    fromVersion: number;
+	    toVersion: number;
+	    dryRun: boolean;
+	    snapshotPath: string;
+	    applied: AppliedMigration[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MigrationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fromVersion = source["fromVersion"];
+	        this.toVersion = source["toVersion"];
+	        this.dryRun = source["dryRun"];
+	        this.snapshotPath = source["snapshotPath"];
+	        this.applied = this.convertValues(source["applied"], AppliedMigration);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Query {
+	    start: time.Time;
+	    end: time.Time;
+	    symbol: string;
+	    sector: string;
+	    strategy: string;
+	    sortBy: string;
+	    sortDir: string;
+	    cursor: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = this.convertValues(source["start"], time.Time);
+	        this.end = this.convertValues(source["end"], time.Time);
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.strategy = source["strategy"];
+	        this.sortBy = source["sortBy"];
+	        this.sortDir = source["sortDir"];
+	        this.cursor = source["cursor"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReindexReport {
+	    entries: number;
+	    badKeys: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReindexReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = source["entries"];
+	        this.badKeys = source["badKeys"];
+	    }
+	}
+	export class RiskAssessmentPage {
+	    items: models.RiskAssessment[];
+	    nextCursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RiskAssessmentPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], models.RiskAssessment);
+	        this.nextCursor = source["nextCursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StockRatingPage {
+	    items: models.StockRating[];
+	    nextCursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StockRatingPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], models.StockRating);
+	        this.nextCursor = source["nextCursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TradePage {
+	    items: models.Trade[];
+	    nextCursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TradePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], models.Trade);
+	        this.nextCursor = source["nextCursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace equity {
+	
+	export class Drawdown {
+	    peak: number;
+	    peakDate: time.Time;
+	    equity: number;
+	    current: number;
+	    currentPercent: number;
+	    max: number;
+	    maxPercent: number;
+	    maxDate: time.Time;
+	    underWaterSince?: time.Time;
+	    daysUnderWater: number;
+	    maxDaysUnderWater: number;
+	    tolerancePercent: number;
+	    breached: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Drawdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.peak = source["peak"];
+	        this.peakDate = this.convertValues(source["peakDate"], time.Time);
+	        this.equity = source["equity"];
+	        this.current = source["current"];
+	        this.currentPercent = source["currentPercent"];
+	        this.max = source["max"];
+	        this.maxPercent = source["maxPercent"];
+	        this.maxDate = this.convertValues(source["maxDate"], time.Time);
+	        this.underWaterSince = this.convertValues(source["underWaterSince"], time.Time);
+	        this.daysUnderWater = source["daysUnderWater"];
+	        this.maxDaysUnderWater = source["maxDaysUnderWater"];
+	        this.tolerancePercent = source["tolerancePercent"];
+	        this.breached = source["breached"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Curve {
+	    snapshots: models.EquitySnapshot[];
+	    drawdown: Drawdown;
+	
+	    static createFrom(source: any = {}) {
+	        return new Curve(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.snapshots = this.convertValues(source["snapshots"], models.EquitySnapshot);
+	        this.drawdown = this.convertValues(source["drawdown"], Drawdown);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace importers {
+	
+	export class Skipped {
+	    row: number;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Skipped(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class Result {
+	    broker: string;
+	    trades: models.Trade[];
+	    events: models.TradeEvent[];
+	    skipped: Skipped[];
+	    warnings: string[];
+	    saved: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Result(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.broker = source["broker"];
+	        this.trades = this.convertValues(source["trades"], models.Trade);
+	        this.events = this.convertValues(source["events"], models.TradeEvent);
+	        this.skipped = this.convertValues(source["skipped"], Skipped);
+	        this.warnings = source["warnings"];
+	        this.saved = source["saved"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace models {
+	
+	export class CashFlow {
+	    id: string;
+	    date: time.Time;
+	    amount: number;
+	    notes: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CashFlow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.amount = source["amount"];
+	        this.notes = source["notes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EquitySnapshot {
+	    date: time.Time;
+	    startingValue: number;
+	    realizedPL: number;
+	    equity: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EquitySnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.startingValue = source["startingValue"];
+	        this.realizedPL = source["realizedPL"];
+	        this.equity = source["equity"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JournalEntry {
+	    id: string;
+	    tradeId: string;
+	    date: time.Time;
+	    title: string;
+	    symbol: string;
+	    sector: string;
+	    strategy: string;
+	    type: string;
+	    outcome: string;
+	    pnlAmount: number;
+	    emotionalState: string;
+	    whatWentWell: string;
+	    whatWentPoorly: string;
+	    lessonsLearned: string;
+	    improvementPlan: string;
+	    tags: string[];
+	    overrideReason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.tradeId = source["tradeId"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.title = source["title"];
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.strategy = source["strategy"];
+	        this.type = source["type"];
+	        this.outcome = source["outcome"];
+	        this.pnlAmount = source["pnlAmount"];
+	        this.emotionalState = source["emotionalState"];
+	        this.whatWentWell = source["whatWentWell"];
+	        this.whatWentPoorly = source["whatWentPoorly"];
+	        this.lessonsLearned = source["lessonsLearned"];
+	        this.improvementPlan = source["improvementPlan"];
+	        this.tags = source["tags"];
+	        this.overrideReason = source["overrideReason"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Leg {
+	    strike: number;
+	    right: string;
+	    side: string;
+	    quantity: number;
+	    expiration: time.Time;
+	    fillPrice: number;
+	    symbol: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Leg(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.strike = source["strike"];
+	        this.right = source["right"];
+	        this.side = source["side"];
+	        this.quantity = source["quantity"];
+	        this.expiration = this.convertValues(source["expiration"], time.Time);
+	        this.fillPrice = source["fillPrice"];
+	        this.symbol = source["symbol"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LossLimitOverride {
+	    id: string;
+	    period: string;
+	    periodKey: string;
+	    date: time.Time;
+	    reason: string;
+	    journalEntryId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LossLimitOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.period = source["period"];
+	        this.periodKey = source["periodKey"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.reason = source["reason"];
+	        this.journalEntryId = source["journalEntryId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PositionSettings {
+	    accountValue: number;
+	    accountRiskPerTrade: number;
+	    maxPortfolioExposure: number;
+	    stopLossPercent: number;
+	    riskRewardRatio: number;
+	    dailyLossLimit: number;
+	    weeklyLossLimit: number;
+	    positionScaling: number;
+	    correlationAdjustment: number;
+	    volatilityMultiplier: number;
+	    maxDrawdownTolerance: number;
+	    riskEnforcement: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PositionSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accountValue = source["accountValue"];
+	        this.accountRiskPerTrade = source["accountRiskPerTrade"];
+	        this.maxPortfolioExposure = source["maxPortfolioExposure"];
+	        this.stopLossPercent = source["stopLossPercent"];
+	        this.riskRewardRatio = source["riskRewardRatio"];
+	        this.dailyLossLimit = source["dailyLossLimit"];
+	        this.weeklyLossLimit = source["weeklyLossLimit"];
+	        this.positionScaling = source["positionScaling"];
+	        this.correlationAdjustment = source["correlationAdjustment"];
+	        this.volatilityMultiplier = source["volatilityMultiplier"];
+	        this.maxDrawdownTolerance = source["maxDrawdownTolerance"];
+	        this.riskEnforcement = source["riskEnforcement"];
+	    }
+	}
+	export class RiskAssessment {
+	    id: string;
+	    date: time.Time;
+	    emotionalScore: number;
+	    fomoScore: number;
+	    biasScore: number;
+	    physicalScore: number;
+	    plImpactScore: number;
+	    otherScore: number;
+	    overallScore: number;
+	    notes: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RiskAssessment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.emotionalScore = source["emotionalScore"];
+	        this.fomoScore = source["fomoScore"];
+	        this.biasScore = source["biasScore"];
+	        this.physicalScore = source["physicalScore"];
+	        this.plImpactScore = source["plImpactScore"];
+	        this.otherScore = source["otherScore"];
+	        this.overallScore = source["overallScore"];
+	        this.notes = source["notes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Rule {
+	    id: string;
+	    name: string;
+	    expression: string;
+	    severity: string;
+	    message: string;
+	    enabled: boolean;
+	    builtin: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.expression = source["expression"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.enabled = source["enabled"];
+	        this.builtin = source["builtin"];
+	    }
+	}
+	export class StockRating {
+	    id: string;
+	    date: time.Time;
+	    symbol: string;
+	    sector: string;
+	    stockSentiment: number;
+	    priceTarget: number;
+	    confidence: number;
+	    notes: string;
+	    enthusiasm: number;
+	    chartPattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StockRating(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.stockSentiment = source["stockSentiment"];
+	        this.priceTarget = source["priceTarget"];
+	        this.confidence = source["confidence"];
+	        this.notes = source["notes"];
+	        this.enthusiasm = source["enthusiasm"];
+	        this.chartPattern = source["chartPattern"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Trade {
+	    id: string;
+	    symbol: string;
+	    sector: string;
+	    strategy: string;
+	    type: string;
+	    week: number;
+	    entryDate: time.Time;
+	    expirationDate: time.Time;
+	    entryPrice: number;
+	    notes: string;
+	    legNumber: number;
+	    isMultiLeg: boolean;
+	    shortLegExp: string;
+	    timeframe: string;
+	    entry: number;
+	    stop: number;
+	    target: number;
+	    legs: Leg[];
+	    rolledFromId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Trade(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.strategy = source["strategy"];
+	        this.type = source["type"];
+	        this.week = source["week"];
+	        this.entryDate = this.convertValues(source["entryDate"], time.Time);
+	        this.expirationDate = this.convertValues(source["expirationDate"], time.Time);
+	        this.entryPrice = source["entryPrice"];
+	        this.notes = source["notes"];
+	        this.legNumber = source["legNumber"];
+	        this.isMultiLeg = source["isMultiLeg"];
+	        this.shortLegExp = source["shortLegExp"];
+	        this.timeframe = source["timeframe"];
+	        this.entry = source["entry"];
+	        this.stop = source["stop"];
+	        this.target = source["target"];
+	        this.legs = this.convertValues(source["legs"], Leg);
+	        this.rolledFromId = source["rolledFromId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TradeEvent {
+	    id: string;
+	    tradeId: string;
+	    type: string;
+	    date: time.Time;
+	    price: number;
+	    quantity: number;
+	    fees: number;
+	    rolledToId: string;
+	    notes: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TradeEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.tradeId = source["tradeId"];
+	        this.type = source["type"];
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.price = source["price"];
+	        this.quantity = source["quantity"];
+	        this.fees = source["fees"];
+	        this.rolledToId = source["rolledToId"];
+	        this.notes = source["notes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TradeHistory {
+	    trade?: Trade;
+	    events: TradeEvent[];
+	    status: string;
+	    quantity: number;
+	    openQuantity: number;
+	    entryValue: number;
+	    realizedPL: number;
+	    fees: number;
+	    closedDate?: time.Time;
+	
+	    static createFrom(source: any = {}) {
+	        return new TradeHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trade = this.convertValues(source["trade"], Trade);
+	        this.events = this.convertValues(source["events"], TradeEvent);
+	        this.status = source["status"];
+	        this.quantity = source["quantity"];
+	        this.openQuantity = source["openQuantity"];
+	        this.entryValue = source["entryValue"];
+	        this.realizedPL = source["realizedPL"];
+	        this.fees = source["fees"];
+	        this.closedDate = this.convertValues(source["closedDate"], time.Time);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace portfolio {
+	
+	export class Bucket {
+	    key: string;
+	    exposure: number;
+	    percent: number;
+	    trades: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Bucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.exposure = source["exposure"];
+	        this.percent = source["percent"];
+	        this.trades = source["trades"];
+	    }
+	}
+	export class Position {
+	    tradeId: string;
+	    symbol: string;
+	    sector: string;
+	    strategy: string;
+	    type: string;
+	    direction: string;
+	    expirationWeek: string;
+	    openQuantity: number;
+	    exposure: number;
+	    maxLossUnlimited: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Position(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tradeId = source["tradeId"];
+	        this.symbol = source["symbol"];
+	        this.sector = source["sector"];
+	        this.strategy = source["strategy"];
+	        this.type = source["type"];
+	        this.direction = source["direction"];
+	        this.expirationWeek = source["expirationWeek"];
+	        this.openQuantity = source["openQuantity"];
+	        this.exposure = source["exposure"];
+	        this.maxLossUnlimited = source["maxLossUnlimited"];
+	    }
+	}
+	export class Exposure {
+	    asOf: time.Time;
+	    accountValue: number;
+	    totalExposure: number;
+	    totalPercent: number;
+	    maxPortfolioExposure: number;
+	    overLimit: boolean;
+	    bySector: Bucket[];
+	    bySymbol: Bucket[];
+	    byStrategy: Bucket[];
+	    byExpirationWeek: Bucket[];
+	    byDirection: Bucket[];
+	    positions: Position[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Exposure(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.asOf = this.convertValues(source["asOf"], time.Time);
+	        this.accountValue = source["accountValue"];
+	        this.totalExposure = source["totalExposure"];
+	        this.totalPercent = source["totalPercent"];
+	        this.maxPortfolioExposure = source["maxPortfolioExposure"];
+	        this.overLimit = source["overLimit"];
+	        this.bySector = this.convertValues(source["bySector"], Bucket);
+	        this.bySymbol = this.convertValues(source["bySymbol"], Bucket);
+	        this.byStrategy = this.convertValues(source["byStrategy"], Bucket);
+	        this.byExpirationWeek = this.convertValues(source["byExpirationWeek"], Bucket);
+	        this.byDirection = this.convertValues(source["byDirection"], Bucket);
+	        this.positions = this.convertValues(source["positions"], Position);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace pricing {
+	
+	export class Greeks {
+	    price: number;
+	    delta: number;
+	    gamma: number;
+	    theta: number;
+	    vega: number;
+	    rho: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Greeks(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.price = source["price"];
+	        this.delta = source["delta"];
+	        this.gamma = source["gamma"];
+	        this.theta = source["theta"];
+	        this.vega = source["vega"];
+	        this.rho = source["rho"];
+	    }
+	}
+	export class LegGreeks {
+	    leg: models.Leg;
+	    years: number;
+	    greeks: Greeks;
+	    total: Greeks;
+	
+	    static createFrom(source: any = {}) {
+	        return new LegGreeks(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.leg = this.convertValues(source["leg"], models.Leg);
+	        this.years = source["years"];
+	        this.greeks = this.convertValues(source["greeks"], Greeks);
+	        this.total = this.convertValues(source["total"], Greeks);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Market {
+	    spot: number;
+	    volatility: number;
+	    rate: number;
+	    dividend: number;
+	    asOf: time.Time;
+	
+	    static createFrom(source: any = {}) {
+	        return new Market(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.spot = source["spot"];
+	        this.volatility = source["volatility"];
+	        this.rate = source["rate"];
+	        this.dividend = source["dividend"];
+	        this.asOf = this.convertValues(source["asOf"], time.Time);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PayoffPoint {
+	    price: number;
+	    pl: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PayoffPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.price = source["price"];
+	        this.pl = source["pl"];
+	    }
+	}
+	export class PayoffCurve {
+	    date: time.Time;
+	    points: PayoffPoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PayoffCurve(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], time.Time);
+	        this.points = this.convertValues(source["points"], PayoffPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Payoff {
+	    tradeId: string;
+	    expiration: PayoffCurve;
+	    curves: PayoffCurve[];
+	    maxProfit: number;
+	    maxProfitUnlimited: boolean;
+	    maxLoss: number;
+	    maxLossUnlimited: boolean;
+	    breakevens: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Payoff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tradeId = source["tradeId"];
+	        this.expiration = this.convertValues(source["expiration"], PayoffCurve);
+	        this.curves = this.convertValues(source["curves"], PayoffCurve);
+	        this.maxProfit = source["maxProfit"];
+	        this.maxProfitUnlimited = source["maxProfitUnlimited"];
+	        this.maxLoss = source["maxLoss"];
+	        this.maxLossUnlimited = source["maxLossUnlimited"];
+	        this.breakevens = source["breakevens"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class PayoffOptions {
+	    low: number;
+	    high: number;
+	    points: number;
+	    dates: time.Time[];
+	    volatility: number;
+	    rate?: number;
+	    dividend: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PayoffOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.low = source["low"];
+	        this.high = source["high"];
+	        this.points = source["points"];
+	        this.dates = this.convertValues(source["dates"], time.Time);
+	        this.volatility = source["volatility"];
+	        this.rate = source["rate"];
+	        this.dividend = source["dividend"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TradeGreeks {
+	    tradeId: string;
+	    market: Market;
+	    legs: LegGreeks[];
+	    total: Greeks;
+	
+	    static createFrom(source: any = {}) {
+	        return new TradeGreeks(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tradeId = source["tradeId"];
+	        this.market = this.convertValues(source["market"], Market);
+	        this.legs = this.convertValues(source["legs"], LegGreeks);
+	        this.total = this.convertValues(source["total"], Greeks);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace risk {
+	
+	export class Violation {
+	    code: string;
+	    severity: string;
+	    message: string;
+	    limit: number;
+	    actual: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Violation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.limit = source["limit"];
+	        this.actual = source["actual"];
+	    }
+	}
+	export class Check {
+	    maxLoss: number;
+	    maxLossUnlimited: boolean;
+	    maxLossSource: string;
+	    baseBudget: number;
+	    recommendedPercent: number;
+	    budget: number;
+	    violations: Violation[];
+	    blocked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Check(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxLoss = source["maxLoss"];
+	        this.maxLossUnlimited = source["maxLossUnlimited"];
+	        this.maxLossSource = source["maxLossSource"];
+	        this.baseBudget = source["baseBudget"];
+	        this.recommendedPercent = source["recommendedPercent"];
+	        this.budget = source["budget"];
+	        this.violations = this.convertValues(source["violations"], Violation);
+	        this.blocked = source["blocked"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PeriodLoss {
+	    period: string;
+	    key: string;
+	    start: time.Time;
+	    end: time.Time;
+	    realizedPL: number;
+	    markedPL: number;
+	    loss: number;
+	    lossPercent: number;
+	    limitPercent: number;
+	    limit: number;
+	    breached: boolean;
+	    override?: models.LossLimitOverride;
+	
+	    static createFrom(source: any = {}) {
+	        return new PeriodLoss(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.key = source["key"];
+	        this.start = this.convertValues(source["start"], time.Time);
+	        this.end = this.convertValues(source["end"], time.Time);
+	        this.realizedPL = source["realizedPL"];
+	        this.markedPL = source["markedPL"];
+	        this.loss = source["loss"];
+	        this.lossPercent = source["lossPercent"];
+	        this.limitPercent = source["limitPercent"];
+	        this.limit = source["limit"];
+	        this.breached = source["breached"];
+	        this.override = this.convertValues(source["override"], models.LossLimitOverride);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LossLimitStatus {
+	    asOf: time.Time;
+	    daily: PeriodLoss;
+	    weekly: PeriodLoss;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LossLimitStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.asOf = this.convertValues(source["asOf"], time.Time);
+	        this.daily = this.convertValues(source["daily"], PeriodLoss);
+	        this.weekly = this.convertValues(source["weekly"], PeriodLoss);
+	        this.locked = source["locked"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
+export namespace rules {
+	
+	export class RuleError {
+	    ruleId: string;
+	    name: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.name = source["name"];
+	        this.error = source["error"];
+	    }
+	}
+	export class Fired {
+	    ruleId: string;
+	    name: string;
+	    severity: string;
+	    message: string;
+	    expression: string;
+	    explanation: string;
+	    values: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Fired(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.name = source["name"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.expression = source["expression"];
+	        this.explanation = source["explanation"];
+	        this.values = source["values"];
+	    }
+	}
+	export class Evaluation {
+	    evaluated: number;
+	    fired: Fired[];
+	    errors: RuleError[];
+	    values: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Evaluation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.evaluated = source["evaluated"];
+	        this.fired = this.convertValues(source["fired"], Fired);
+	        this.errors = this.convertValues(source["errors"], RuleError);
+	        this.values = source["values"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class Variable {
+	    name: string;
+	    type: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Variable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.description = source["description"];
+	    }
+	}
+
+}
+
+export namespace sizing {
+	
+	export class Advice {
+	    tier: string;
+	    title: string;
+	    tips: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Advice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tier = source["tier"];
+	        this.title = source["title"];
+	        this.tips = source["tips"];
+	    }
+	}
+	export class Factor {
+	    name: string;
+	    score: number;
+	    contribution: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Factor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.score = source["score"];
+	        this.contribution = source["contribution"];
+	    }
+	}
+	export class Flags {
+	    euphoria: boolean;
+	    chasing: boolean;
+	    fomo: boolean;
+	    stayOut: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Flags(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.euphoria = source["euphoria"];
+	        this.chasing = source["chasing"];
+	        this.fomo = source["fomo"];
+	        this.stayOut = source["stayOut"];
+	    }
+	}
+	export class Recommendation {
+	    percent: number;
+	    rawPercent: number;
+	    factors: Factor[];
+	    extremeCount: number;
+	    extremeMultiplier: number;
+	    moderateCount: number;
+	    moderateMultiplier: number;
+	    extremeScenario: boolean;
+	    flags: Flags;
+	    advice: Advice;
+	    maxDollarRisk: number;
+	    recommendedDollarRisk: number;
+	    drawdownBreached: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Recommendation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.percent = source["percent"];
+	        this.rawPercent = source["rawPercent"];
+	        this.factors = this.convertValues(source["factors"], Factor);
+	        this.extremeCount = source["extremeCount"];
+	        this.extremeMultiplier = source["extremeMultiplier"];
+	        this.moderateCount = source["moderateCount"];
+	        this.moderateMultiplier = source["moderateMultiplier"];
+	        this.extremeScenario = source["extremeScenario"];
+	        this.flags = this.convertValues(source["flags"], Flags);
+	        this.advice = this.convertValues(source["advice"], Advice);
+	        this.maxDollarRisk = source["maxDollarRisk"];
+	        this.recommendedDollarRisk = source["recommendedDollarRisk"];
+	        this.drawdownBreached = source["drawdownBreached"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace time {
+	
+	export class Time {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new Time(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
+	}
+
+}
+
+export namespace tradecsv {
+	
+	export class Mapping {
+	    columns: Record<string, string>;
+	    dateFormat: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Mapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = source["columns"];
+	        this.dateFormat = source["dateFormat"];
+	    }
+	}
+	export class RowResult {
+	    row: number;
+	    tradeId: string;
+	    id: string;
+	    symbol: string;
+	    status: string;
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RowResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.tradeId = source["tradeId"];
+	        this.id = source["id"];
+	        this.symbol = source["symbol"];
+	        this.status = source["status"];
+	        this.errors = source["errors"];
+	    }
+	}
+	export class Report {
+	    dateFormat: string;
+	    rows: RowResult[];
+	    imported: number;
+	    updated: number;
+	    rejected: number;
+	    saved: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dateFormat = source["dateFormat"];
+	        this.rows = this.convertValues(source["rows"], RowResult);
+	        this.imported = source["imported"];
+	        this.updated = source["updated"];
+	        this.rejected = source["rejected"];
+	        this.saved = source["saved"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
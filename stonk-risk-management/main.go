@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,9 +18,56 @@ import (
 var assets embed.FS
 
 func main() {
+	apiAddr := flag.String("api", "", "serve the JSON API on this loopback address, e.g. 127.0.0.1:8765")
+	apiToken := flag.String("api-token", os.Getenv("ORM_API_TOKEN"), "API bearer token (default $ORM_API_TOKEN, or a random token)")
+	headless := flag.Bool("headless", false, "run only the API server, without the window")
+	flag.Parse()
+
 	// Create an instance of the app structure
 	app := NewApp()
 
+	var api *apiServer
+	if *apiAddr != "" {
+		var err error
+		if api, err = newAPIServer(app, *apiAddr, *apiToken); err != nil {
+			log.Fatalf("Failed to create API server: %v", err)
+		}
+	} else if *headless {
+		log.Fatalf("-headless needs an -api address")
+	}
+
+	startup := func(ctx context.Context) {
+		app.startup(ctx)
+		if api == nil {
+			return
+		}
+		if err := api.Start(); err != nil {
+			log.Fatalf("Failed to start API server: %v", err)
+		}
+		if *apiToken == "" {
+			log.Printf("API listening on http://%s with token %s", *apiAddr, api.token)
+		} else {
+			log.Printf("API listening on http://%s", *apiAddr)
+		}
+	}
+	shutdown := func(ctx context.Context) {
+		if api != nil {
+			shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			_ = api.Shutdown(shutdownCtx)
+		}
+		app.shutdown(ctx)
+	}
+
+	if *headless {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		startup(ctx)
+		<-ctx.Done()
+		shutdown(context.Background())
+		return
+	}
+
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "Options Trading Dashboard",
@@ -24,7 +77,8 @@ func main() {
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
-		OnStartup:        app.startup,
+		OnStartup:        startup,
+		OnShutdown:       shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// tradingDaysPerYear annualizes the daily Sharpe and Sortino ratios
+const tradingDaysPerYear = 252
+
+// DailyPL is the P&L realized on one day
+type DailyPL struct {
+	Date time.Time `json:"date"`
+	PL   float64   `json:"pl"`
+}
+
+// dailyPL totals the P&L realized by the trades' events per day, including the
+// weekdays between the first and last event on which nothing was realized
+func dailyPL(histories []*models.TradeHistory) []DailyPL {
+	byDay := map[time.Time]float64{}
+	var first, last time.Time
+	for _, h := range histories {
+		for _, e := range h.Events {
+			day := startOfDay(e.Date)
+			byDay[day] += h.EventPL(e)
+			if first.IsZero() || day.Before(first) {
+				first = day
+			}
+			if day.After(last) {
+				last = day
+			}
+		}
+	}
+	if len(byDay) == 0 {
+		return []DailyPL{}
+	}
+
+	days := make([]DailyPL, 0, len(byDay))
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		pl, ok := byDay[day]
+		if !ok && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		days = append(days, DailyPL{Date: day, PL: pl})
+		delete(byDay, day)
+	}
+
+	// Events in other time zones may fall outside the calendar walk
+	for day, pl := range byDay {
+		days = append(days, DailyPL{Date: day, PL: pl})
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// ratios returns the annualized Sharpe and Sortino ratios of daily P&L, with a
+// zero risk-free rate. Either is 0 when there is too little data to compute it.
+func ratios(days []DailyPL) (sharpe, sortino float64) {
+	n := float64(len(days))
+	if n < 2 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	for _, d := range days {
+		mean += d.PL
+	}
+	mean /= n
+
+	variance, downside := 0.0, 0.0
+	for _, d := range days {
+		variance += (d.PL - mean) * (d.PL - mean)
+		if d.PL < 0 {
+			downside += d.PL * d.PL
+		}
+	}
+	annualize := math.Sqrt(tradingDaysPerYear)
+
+	if std := math.Sqrt(variance / (n - 1)); std > 0 {
+		sharpe = mean / std * annualize
+	}
+	if dd := math.Sqrt(downside / n); dd > 0 {
+		sortino = mean / dd * annualize
+	}
+	return sharpe, sortino
+}
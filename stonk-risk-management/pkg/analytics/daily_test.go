@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func TestDailyPL(t *testing.T) {
+	histories := []*models.TradeHistory{
+		closedTrade("friday", day("2026-10-05"), day("2026-10-09"), 8),    // +300
+		closedTrade("saturday", day("2026-10-05"), day("2026-10-10"), 1),  // -400
+		closedTrade("wednesday", day("2026-10-05"), day("2026-10-14"), 6), // +100
+		closedTrade("again", day("2026-10-06"), day("2026-10-14"), 4),     // -100
+	}
+
+	// Sunday is skipped; Monday and Tuesday are filled with zero
+	want := []struct {
+		date string
+		pl   float64
+	}{
+		{"2026-10-09", 300},
+		{"2026-10-10", -400},
+		{"2026-10-12", 0},
+		{"2026-10-13", 0},
+		{"2026-10-14", 0}, // Two trades that net to zero
+	}
+
+	days := dailyPL(histories)
+	if len(days) != len(want) {
+		t.Fatalf("days = %+v, want %d days", days, len(want))
+	}
+	for i, w := range want {
+		if !days[i].Date.Equal(day(w.date)) || math.Abs(days[i].PL-w.pl) > 1e-9 {
+			t.Errorf("day %d = %s %v, want %s %v", i, days[i].Date.Format("2006-01-02"), days[i].PL, w.date, w.pl)
+		}
+	}
+
+	if days := dailyPL(nil); len(days) != 0 {
+		t.Errorf("dailyPL(nil) = %v, want no days", days)
+	}
+}
+
+func TestRatios(t *testing.T) {
+	annualize := math.Sqrt(252)
+
+	tests := []struct {
+		name            string
+		pls             []float64
+		sharpe, sortino float64
+	}{
+		// Mean 25; squared deviations 5625+5625+625+625 = 12500 over n-1 = 3;
+		// downside deviation sqrt(50² / 4) = 25
+		{"mixed", []float64{100, -50, 50, 0}, 25 / math.Sqrt(12500.0/3) * annualize, 25.0 / 25 * annualize},
+		// Mean -10; squared deviations 100+100 over 1; downside sqrt((0 + 20²) / 2)
+		{"losing", []float64{0, -20}, -10 / math.Sqrt(200) * annualize, -10 / math.Sqrt(200) * annualize},
+		// No variance and no losses
+		{"flat", []float64{10, 10, 10}, 0, 0},
+		{"one day", []float64{100}, 0, 0},
+		{"no days", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := make([]DailyPL, len(tt.pls))
+			for i, pl := range tt.pls {
+				days[i] = DailyPL{PL: pl}
+			}
+			sharpe, sortino := ratios(days)
+			if math.Abs(sharpe-tt.sharpe) > 1e-9 || math.Abs(sortino-tt.sortino) > 1e-9 {
+				t.Errorf("ratios = %.6f, %.6f, want %.6f, %.6f", sharpe, sortino, tt.sharpe, tt.sortino)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+)
+
+// Filter selects the closed trades a report covers
+type Filter struct {
+	Start    time.Time `json:"start"`    // Earliest close date (inclusive), zero for no bound
+	End      time.Time `json:"end"`      // Latest close date (inclusive), zero for no bound
+	Symbol   string    `json:"symbol"`   // Exact symbol
+	Sector   string    `json:"sector"`   // Exact sector
+	Strategy string    `json:"strategy"` // Strategy category or type
+}
+
+// Outcome is the result of one closed trade
+type Outcome struct {
+	TradeID     string    `json:"tradeId"`
+	Symbol      string    `json:"symbol"`
+	Sector      string    `json:"sector"`
+	Strategy    string    `json:"strategy"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	EntryDate   time.Time `json:"entryDate"`
+	ClosedDate  time.Time `json:"closedDate"`
+	HoldingDays int       `json:"holdingDays"`
+	PL          float64   `json:"pl"`        // Realized P&L net of fees
+	Risk        float64   `json:"risk"`      // Max loss at entry, 0 when undefined or unlimited
+	RMultiple   *float64  `json:"rMultiple"` // PL divided by Risk, nil without a defined risk
+}
+
+// Stats summarizes a set of trade outcomes
+type Stats struct {
+	Trades             int     `json:"trades"`
+	Wins               int     `json:"wins"`
+	Losses             int     `json:"losses"`
+	Breakeven          int     `json:"breakeven"`
+	WinRate            float64 `json:"winRate"` // Percent of trades that made money
+	NetPL              float64 `json:"netPL"`
+	GrossProfit        float64 `json:"grossProfit"`
+	GrossLoss          float64 `json:"grossLoss"` // Sum of losses as a positive number
+	AverageWin         float64 `json:"averageWin"`
+	AverageLoss        float64 `json:"averageLoss"`  // Average loss as a positive number
+	ProfitFactor       float64 `json:"profitFactor"` // GrossProfit / GrossLoss, 0 without losses
+	PayoffRatio        float64 `json:"payoffRatio"`  // AverageWin / AverageLoss, 0 without losses
+	Expectancy         float64 `json:"expectancy"`   // Average P&L per trade
+	LargestWin         float64 `json:"largestWin"`
+	LargestLoss        float64 `json:"largestLoss"` // Largest loss as a positive number
+	MaxWinStreak       int     `json:"maxWinStreak"`
+	MaxLossStreak      int     `json:"maxLossStreak"`
+	AverageR           float64 `json:"averageR"` // Average R-multiple of the trades with a defined risk
+	RTrades            int     `json:"rTrades"`  // Trades with a defined risk
+	AverageHoldingDays float64 `json:"averageHoldingDays"`
+}
+
+// Slice is the statistics of the trades sharing one attribute
+type Slice struct {
+	Key   string `json:"key"`
+	Stats Stats  `json:"stats"`
+}
+
+// RBucket counts the trades whose R-multiple falls in [Min, Max)
+type RBucket struct {
+	Key    string   `json:"key"`
+	Min    *float64 `json:"min"` // Nil for the lowest bucket
+	Max    *float64 `json:"max"` // Nil for the highest bucket
+	Trades int      `json:"trades"`
+}
+
+// contains reports whether an R-multiple falls in the bucket
+func (b *RBucket) contains(r float64) bool {
+	return (b.Min == nil || r >= *b.Min) && (b.Max == nil || r < *b.Max)
+}
+
+// Report is the trading performance of the closed trades matching a filter
+type Report struct {
+	Filter          Filter     `json:"filter"`
+	Overall         Stats      `json:"overall"`
+	CurrentStreak   int        `json:"currentStreak"` // Consecutive wins (positive) or losses (negative) up to the latest close
+	DailyPL         []DailyPL  `json:"dailyPL"`
+	Sharpe          float64    `json:"sharpe"`  // Annualized Sharpe ratio of daily P&L
+	Sortino         float64    `json:"sortino"` // Annualized Sortino ratio of daily P&L
+	RMultiples      []RBucket  `json:"rMultiples"`
+	ByStrategy      []Slice    `json:"byStrategy"`
+	BySector        []Slice    `json:"bySector"`
+	BySymbol        []Slice    `json:"bySymbol"`
+	ByWeekday       []Slice    `json:"byWeekday"` // By the weekday the trade was entered
+	ByHoldingPeriod []Slice    `json:"byHoldingPeriod"`
+	Outcomes        []*Outcome `json:"outcomes"` // Oldest close first
+}
+
+// holdingPeriods are the holding period buckets, by their longest holding in days
+var holdingPeriods = []struct {
+	key     string
+	maxDays int
+}{
+	{"Same day", 0},
+	{"1-7 days", 7},
+	{"8-30 days", 30},
+	{"31-90 days", 90},
+	{"Over 90 days", -1},
+}
+
+// rBounds are the edges of the R-multiple distribution buckets
+var rBounds = []float64{-2, -1, 0, 1, 2, 3}
+
+// Performance builds a report from the trades that are fully closed and match the filter
+func Performance(histories []*models.TradeHistory, filter Filter) *Report {
+	report := &Report{Filter: filter, Outcomes: []*Outcome{}}
+
+	var closed []*models.TradeHistory
+	for _, h := range histories {
+		if h.ClosedDate == nil || !filter.matches(h) {
+			continue
+		}
+		closed = append(closed, h)
+		report.Outcomes = append(report.Outcomes, NewOutcome(h))
+	}
+	sort.SliceStable(report.Outcomes, func(i, j int) bool {
+		return report.Outcomes[i].ClosedDate.Before(report.Outcomes[j].ClosedDate)
+	})
+
+	report.Overall = Summarize(report.Outcomes)
+	report.CurrentStreak = currentStreak(report.Outcomes)
+	report.DailyPL = dailyPL(closed)
+	report.Sharpe, report.Sortino = ratios(report.DailyPL)
+	report.RMultiples = rDistribution(report.Outcomes)
+
+	report.ByStrategy = slices(report.Outcomes, func(o *Outcome) string { return o.Strategy })
+	report.BySector = slices(report.Outcomes, func(o *Outcome) string { return o.Sector })
+	report.BySymbol = slices(report.Outcomes, func(o *Outcome) string { return o.Symbol })
+	report.ByWeekday = slices(report.Outcomes, func(o *Outcome) string { return o.EntryDate.Weekday().String() })
+	report.ByHoldingPeriod = slices(report.Outcomes, holdingPeriod)
+
+	// Weekdays and holding periods read best in their natural order
+	sort.SliceStable(report.ByWeekday, func(i, j int) bool {
+		return weekdayIndex(report.ByWeekday[i].Key) < weekdayIndex(report.ByWeekday[j].Key)
+	})
+	sort.SliceStable(report.ByHoldingPeriod, func(i, j int) bool {
+		return holdingIndex(report.ByHoldingPeriod[i].Key) < holdingIndex(report.ByHoldingPeriod[j].Key)
+	})
+
+	return report
+}
+
+// NewOutcome derives the outcome of a closed trade, measuring R against its max loss at entry
+func NewOutcome(h *models.TradeHistory) *Outcome {
+	t := h.Trade
+	o := &Outcome{
+		TradeID:   t.ID,
+		Symbol:    t.Symbol,
+		Sector:    t.Sector,
+		Strategy:  t.Strategy,
+		Type:      t.Type,
+		Status:    h.Status,
+		EntryDate: t.EntryDate,
+		PL:        h.RealizedPL,
+	}
+	if h.ClosedDate != nil {
+		o.ClosedDate = *h.ClosedDate
+		o.HoldingDays = daysBetween(t.EntryDate, o.ClosedDate)
+	}
+
+	if maxLoss, unlimited, _ := risk.MaxLoss(t); maxLoss > 0 && !unlimited {
+		o.Risk = maxLoss
+		r := o.PL / maxLoss
+		o.RMultiple = &r
+	}
+	return o
+}
+
+// Summarize computes the statistics of outcomes sorted oldest close first
+func Summarize(outcomes []*Outcome) Stats {
+	var s Stats
+	winStreak, lossStreak := 0, 0
+	totalR, totalDays := 0.0, 0
+
+	for _, o := range outcomes {
+		s.Trades++
+		s.NetPL += o.PL
+		totalDays += o.HoldingDays
+
+		switch {
+		case o.PL > 0:
+			s.Wins++
+			s.GrossProfit += o.PL
+			if o.PL > s.LargestWin {
+				s.LargestWin = o.PL
+			}
+			winStreak, lossStreak = winStreak+1, 0
+		case o.PL < 0:
+			s.Losses++
+			s.GrossLoss -= o.PL
+			if -o.PL > s.LargestLoss {
+				s.LargestLoss = -o.PL
+			}
+			winStreak, lossStreak = 0, lossStreak+1
+		default:
+			s.Breakeven++
+			winStreak, lossStreak = 0, 0
+		}
+		if winStreak > s.MaxWinStreak {
+			s.MaxWinStreak = winStreak
+		}
+		if lossStreak > s.MaxLossStreak {
+			s.MaxLossStreak = lossStreak
+		}
+
+		if o.RMultiple != nil {
+			s.RTrades++
+			totalR += *o.RMultiple
+		}
+	}
+
+	if s.Trades == 0 {
+		return s
+	}
+	s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+	s.Expectancy = s.NetPL / float64(s.Trades)
+	s.AverageHoldingDays = float64(totalDays) / float64(s.Trades)
+	if s.Wins > 0 {
+		s.AverageWin = s.GrossProfit / float64(s.Wins)
+	}
+	if s.Losses > 0 {
+		s.AverageLoss = s.GrossLoss / float64(s.Losses)
+		s.ProfitFactor = s.GrossProfit / s.GrossLoss
+		s.PayoffRatio = s.AverageWin / s.AverageLoss
+	}
+	if s.RTrades > 0 {
+		s.AverageR = totalR / float64(s.RTrades)
+	}
+	return s
+}
+
+// matches reports whether a trade passes the filter
+func (f Filter) matches(h *models.TradeHistory) bool {
+	t := h.Trade
+	if f.Symbol != "" && t.Symbol != f.Symbol {
+		return false
+	}
+	if f.Sector != "" && t.Sector != f.Sector {
+		return false
+	}
+	if f.Strategy != "" && t.Strategy != f.Strategy && t.Type != f.Strategy {
+		return false
+	}
+	// Close dates are calendar dates at midnight UTC, so the bounds are compared as
+	// calendar days and the end date covers the whole day
+	if _, start, _ := risk.DayPeriod(f.Start); !f.Start.IsZero() && h.ClosedDate.Before(start) {
+		return false
+	}
+	if _, _, end := risk.DayPeriod(f.End); !f.End.IsZero() && !h.ClosedDate.Before(end) {
+		return false
+	}
+	return true
+}
+
+// currentStreak counts the wins or losses in a row ending at the latest outcome
+func currentStreak(outcomes []*Outcome) int {
+	streak := 0
+	for i := len(outcomes) - 1; i >= 0; i-- {
+		pl := outcomes[i].PL
+		switch {
+		case pl > 0 && streak >= 0:
+			streak++
+		case pl < 0 && streak <= 0:
+			streak--
+		default:
+			return streak
+		}
+	}
+	return streak
+}
+
+// slices groups outcomes by key, largest net P&L first
+func slices(outcomes []*Outcome, key func(*Outcome) string) []Slice {
+	byKey := map[string][]*Outcome{}
+	var keys []string
+	for _, o := range outcomes {
+		k := key(o)
+		if k == "" {
+			k = "Unknown"
+		}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], o)
+	}
+
+	result := make([]Slice, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, Slice{Key: k, Stats: Summarize(byKey[k])})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Stats.NetPL > result[j].Stats.NetPL
+	})
+	return result
+}
+
+// rDistribution counts the outcomes with a defined risk in each R-multiple bucket
+func rDistribution(outcomes []*Outcome) []RBucket {
+	buckets := make([]RBucket, 0, len(rBounds)+1)
+	for i := 0; i <= len(rBounds); i++ {
+		var b RBucket
+		switch {
+		case i == 0:
+			b = RBucket{Key: fmt.Sprintf("Below %gR", rBounds[0]), Max: &rBounds[0]}
+		case i == len(rBounds):
+			b = RBucket{Key: fmt.Sprintf("%gR and above", rBounds[i-1]), Min: &rBounds[i-1]}
+		default:
+			b = RBucket{Key: fmt.Sprintf("%gR to %gR", rBounds[i-1], rBounds[i]), Min: &rBounds[i-1], Max: &rBounds[i]}
+		}
+		buckets = append(buckets, b)
+	}
+
+	for _, o := range outcomes {
+		if o.RMultiple == nil {
+			continue
+		}
+		for i := range buckets {
+			if buckets[i].contains(*o.RMultiple) {
+				buckets[i].Trades++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// holdingPeriod returns the holding period bucket of an outcome
+func holdingPeriod(o *Outcome) string {
+	for _, p := range holdingPeriods {
+		if p.maxDays < 0 || o.HoldingDays <= p.maxDays {
+			return p.key
+		}
+	}
+	return ""
+}
+
+// holdingIndex returns the position of a holding period bucket
+func holdingIndex(key string) int {
+	for i, p := range holdingPeriods {
+		if p.key == key {
+			return i
+		}
+	}
+	return len(holdingPeriods)
+}
+
+// weekdayIndex orders weekdays Monday first
+func weekdayIndex(key string) int {
+	for d := time.Monday; d <= time.Saturday; d++ {
+		if d.String() == key {
+			return int(d)
+		}
+	}
+	if key == time.Sunday.String() {
+		return 7
+	}
+	return 8
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	days := int(startOfDay(b).Sub(startOfDay(a)).Hours()/24 + 0.5)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// startOfDay truncates a time to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// closedTrade is a long call bought at 5 and closed at a price: +$300 at 8, -$400 at 1
+func closedTrade(id string, entry, exit time.Time, price float64) *models.TradeHistory {
+	trade := &models.Trade{ID: id, Symbol: "AAPL", Type: "Long Call", EntryPrice: 5, EntryDate: entry}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return models.NewTradeHistory(trade, []*models.TradeEvent{
+		{ID: id + "-close", TradeID: id, Type: models.EventClose, Date: exit, Price: price, Quantity: 1},
+	})
+}
+
+// outcomes builds outcomes with the given P&L in close order
+func outcomes(pls ...float64) []*Outcome {
+	result := make([]*Outcome, len(pls))
+	for i, pl := range pls {
+		result[i] = &Outcome{PL: pl}
+	}
+	return result
+}
+
+func TestSummarizeStreaks(t *testing.T) {
+	tests := []struct {
+		name            string
+		pls             []float64
+		maxWin, maxLoss int
+		current         int
+	}{
+		{"empty", nil, 0, 0, 0},
+		// A breakeven trade ends both streaks
+		{"breakeven resets", []float64{10, 20, -5, 0, -5, -10, -1, 3}, 2, 3, 1},
+		{"ends on losses", []float64{10, 10, 10, -5, -10}, 3, 2, -2},
+		{"ends on breakeven", []float64{-5, -5, 0}, 0, 2, 0},
+		{"all wins", []float64{1, 2, 3, 4}, 4, 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := outcomes(tt.pls...)
+			s := Summarize(o)
+			if s.MaxWinStreak != tt.maxWin || s.MaxLossStreak != tt.maxLoss {
+				t.Errorf("streaks = %d wins, %d losses, want %d, %d", s.MaxWinStreak, s.MaxLossStreak, tt.maxWin, tt.maxLoss)
+			}
+			if got := currentStreak(o); got != tt.current {
+				t.Errorf("current streak = %d, want %d", got, tt.current)
+			}
+		})
+	}
+}
+
+func TestSummarizeTotals(t *testing.T) {
+	s := Summarize(outcomes(300, -100, 200, -300, 0))
+
+	// 500 profit over 2 wins, 400 loss over 2 losses
+	want := Stats{
+		Trades: 5, Wins: 2, Losses: 2, Breakeven: 1,
+		WinRate: 40, NetPL: 100, GrossProfit: 500, GrossLoss: 400,
+		AverageWin: 250, AverageLoss: 200, ProfitFactor: 1.25, PayoffRatio: 1.25,
+		Expectancy: 20, LargestWin: 300, LargestLoss: 300,
+		MaxWinStreak: 1, MaxLossStreak: 1,
+	}
+	if s != want {
+		t.Errorf("stats = %+v\nwant %+v", s, want)
+	}
+}
+
+func TestRDistribution(t *testing.T) {
+	var o []*Outcome
+	for _, r := range []float64{-3, -2, -1.5, -0.5, 0, 1, 2.5, 3, 7} {
+		o = append(o, &Outcome{RMultiple: &r})
+	}
+	o = append(o, &Outcome{PL: 100}) // No defined risk, not counted
+
+	// Each bucket holds its lower edge and not its upper one
+	want := []struct {
+		key    string
+		trades int
+	}{
+		{"Below -2R", 1},    // -3
+		{"-2R to -1R", 2},   // -2, -1.5
+		{"-1R to 0R", 1},    // -0.5
+		{"0R to 1R", 1},     // 0
+		{"1R to 2R", 1},     // 1
+		{"2R to 3R", 1},     // 2.5
+		{"3R and above", 2}, // 3, 7
+	}
+
+	buckets := rDistribution(o)
+	if len(buckets) != len(want) {
+		t.Fatalf("%d buckets, want %d", len(buckets), len(want))
+	}
+	for i, w := range want {
+		if buckets[i].Key != w.key || buckets[i].Trades != w.trades {
+			t.Errorf("bucket %d = %s with %d trades, want %s with %d", i, buckets[i].Key, buckets[i].Trades, w.key, w.trades)
+		}
+	}
+}
+
+// R is measured against the max loss at entry, the $500 paid for the call
+func TestNewOutcomeRMultiple(t *testing.T) {
+	o := NewOutcome(closedTrade("loss", day("2026-10-05"), day("2026-10-14"), 1))
+	if o.Risk != 500 || o.RMultiple == nil || math.Abs(*o.RMultiple-(-0.8)) > 1e-9 {
+		t.Errorf("risk = %v, R = %v, want 500 and -0.8R", o.Risk, o.RMultiple)
+	}
+	if o.HoldingDays != 9 {
+		t.Errorf("holding days = %d, want 9", o.HoldingDays)
+	}
+}
+
+// Close dates are calendar dates at midnight UTC. The start and end dates are
+// inclusive calendar days whatever the time of day or zone they are given in.
+func TestPerformanceFilterDates(t *testing.T) {
+	histories := []*models.TradeHistory{
+		closedTrade("before", day("2026-10-01"), day("2026-10-11"), 8),
+		closedTrade("start", day("2026-10-01"), day("2026-10-12"), 8),
+		closedTrade("end", day("2026-10-01"), day("2026-10-16"), 1),
+		closedTrade("after", day("2026-10-01"), day("2026-10-17"), 1),
+	}
+
+	zones := []*time.Location{
+		time.UTC,
+		time.FixedZone("Los Angeles", -7*3600),
+		time.FixedZone("Tokyo", 9*3600),
+	}
+	for _, loc := range zones {
+		for _, hour := range []int{0, 23} {
+			filter := Filter{
+				Start: time.Date(2026, 10, 12, hour, 0, 0, 0, loc),
+				End:   time.Date(2026, 10, 16, hour, 0, 0, 0, loc),
+			}
+			report := Performance(histories, filter)
+
+			var ids []string
+			for _, o := range report.Outcomes {
+				ids = append(ids, o.TradeID)
+			}
+			if len(ids) != 2 || ids[0] != "start" || ids[1] != "end" {
+				t.Errorf("%s %02d:00: trades = %v, want [start end]", loc, hour, ids)
+			}
+		}
+	}
+
+	if report := Performance(histories, Filter{}); len(report.Outcomes) != 4 {
+		t.Errorf("unfiltered report has %d trades, want 4", len(report.Outcomes))
+	}
+}
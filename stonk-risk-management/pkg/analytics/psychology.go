@@ -0,0 +1,215 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/sizing"
+)
+
+// PairedOutcome is a closed trade together with the assessment from its entry day
+type PairedOutcome struct {
+	Outcome
+	AssessmentID       string           `json:"assessmentId"`       // Empty when no assessment was recorded that day
+	RecommendedPercent int              `json:"recommendedPercent"` // Size the assessment recommended, 100 without one
+	Budget             float64          `json:"budget"`             // Per-trade risk budget of the entry day's equity, scaled by RecommendedPercent
+	Oversized          bool             `json:"oversized"`          // Max loss exceeded the budget
+	Violations         []risk.Violation `json:"violations"`
+}
+
+// ScoreBucket is the outcome of the trades entered on days a dimension scored within [Min, Max]
+type ScoreBucket struct {
+	Key           string  `json:"key"`
+	Min           int     `json:"min"`
+	Max           int     `json:"max"`
+	Stats         Stats   `json:"stats"`
+	Violations    int     `json:"violations"`    // Trades that broke a risk rule
+	ViolationRate float64 `json:"violationRate"` // Percent of trades that broke a risk rule
+}
+
+// Dimension is the outcome of trades by the score of one assessment dimension
+type Dimension struct {
+	Name    string        `json:"name"`
+	Buckets []ScoreBucket `json:"buckets"`
+}
+
+// SizeComparison compares trades taken above the recommended size with the rest
+type SizeComparison struct {
+	Oversized      Stats `json:"oversized"`
+	WithinSize     Stats `json:"withinSize"`
+	Underperformed bool  `json:"underperformed"` // Oversized trades had a lower average R (or expectancy without R)
+}
+
+// PsychologyReport relates the assessments of entry days to trade outcomes
+type PsychologyReport struct {
+	Filter     Filter           `json:"filter"`
+	Paired     int              `json:"paired"`   // Trades entered on a day with an assessment
+	Unpaired   int              `json:"unpaired"` // Trades entered on a day without one
+	Unassessed Stats            `json:"unassessed"`
+	Dimensions []Dimension      `json:"dimensions"`
+	Size       SizeComparison   `json:"size"`
+	Trades     []*PairedOutcome `json:"trades"` // Oldest close first
+}
+
+// scoreBuckets split every dimension into low, neutral and high scores
+var scoreBuckets = []struct {
+	key      string
+	min, max int
+}{
+	{"-2 or lower", models.MinAssessmentScore, -2},
+	{"-1 to +1", -1, 1},
+	{"+2 or higher", 2, models.MaxAssessmentScore},
+}
+
+// dimensions are the assessment scores the report buckets trades by
+var dimensions = []struct {
+	name  string
+	score func(*models.RiskAssessment) int
+}{
+	{"Overall", func(a *models.RiskAssessment) int { return a.OverallScore }},
+	{"Emotional", func(a *models.RiskAssessment) int { return a.EmotionalScore }},
+	{"FOMO", func(a *models.RiskAssessment) int { return a.FOMOScore }},
+	{"Physical", func(a *models.RiskAssessment) int { return a.PhysicalScore }},
+	{"P&L Impact", func(a *models.RiskAssessment) int { return a.PLImpactScore }},
+	{"Other", func(a *models.RiskAssessment) int { return a.OtherScore }},
+	{"Market Bias", func(a *models.RiskAssessment) int { return a.BiasScore }},
+}
+
+// Psychology pairs the closed trades matching the filter with the latest assessment
+// recorded on their entry day and reports outcomes and risk rule violations by score.
+// Trades are checked at the size their assessment recommended against the account
+// equity of their entry day, taken from the latest snapshot (oldest first) on or
+// before it, or the settings' account value for trades older than every snapshot.
+func Psychology(histories []*models.TradeHistory, assessments []*models.RiskAssessment, snapshots []*models.EquitySnapshot, settings *models.PositionSettings, filter Filter) *PsychologyReport {
+	report := &PsychologyReport{Filter: filter, Trades: []*PairedOutcome{}}
+
+	// Violations are reported whatever the enforcement mode
+	var checked *models.PositionSettings
+	if settings != nil {
+		copied := *settings
+		copied.RiskEnforcement = risk.EnforcementWarn
+		checked = &copied
+	}
+
+	byDay := map[string]*models.RiskAssessment{}
+	for _, a := range assessments {
+		day := dayKey(a.Date)
+		if latest, ok := byDay[day]; !ok || a.Date.After(latest.Date) {
+			byDay[day] = a
+		}
+	}
+
+	paired := map[*PairedOutcome]*models.RiskAssessment{}
+	for _, h := range histories {
+		if h.ClosedDate == nil || !filter.matches(h) {
+			continue
+		}
+
+		p := &PairedOutcome{Outcome: *NewOutcome(h), RecommendedPercent: int(sizing.MaximumPercent)}
+		assessment := byDay[dayKey(h.Trade.EntryDate)]
+		if assessment != nil {
+			p.AssessmentID = assessment.ID
+			p.RecommendedPercent = sizing.Recommend(assessment, settings).Percent
+			paired[p] = assessment
+		}
+
+		atEntry := checked
+		if equity, ok := equityOn(snapshots, h.Trade.EntryDate); ok && checked != nil {
+			copied := *checked
+			copied.AccountValue = equity
+			atEntry = &copied
+		}
+		check := risk.Evaluate(h.Trade, atEntry, p.RecommendedPercent)
+		p.Budget = check.Budget
+		p.Violations = check.Violations
+		for _, v := range check.Violations {
+			if v.Code == risk.CodeOverBudget {
+				p.Oversized = true
+			}
+		}
+		report.Trades = append(report.Trades, p)
+	}
+	sort.SliceStable(report.Trades, func(i, j int) bool {
+		return report.Trades[i].ClosedDate.Before(report.Trades[j].ClosedDate)
+	})
+
+	var unassessed, oversized, within []*Outcome
+	for _, p := range report.Trades {
+		if paired[p] == nil {
+			unassessed = append(unassessed, &p.Outcome)
+		}
+		if p.Oversized {
+			oversized = append(oversized, &p.Outcome)
+		} else {
+			within = append(within, &p.Outcome)
+		}
+	}
+	report.Paired = len(paired)
+	report.Unpaired = len(unassessed)
+	report.Unassessed = Summarize(unassessed)
+
+	for _, d := range dimensions {
+		dimension := Dimension{Name: d.name, Buckets: make([]ScoreBucket, 0, len(scoreBuckets))}
+		for _, b := range scoreBuckets {
+			bucket := ScoreBucket{Key: b.key, Min: b.min, Max: b.max}
+			var outcomes []*Outcome
+			for _, p := range report.Trades {
+				a := paired[p]
+				if a == nil {
+					continue
+				}
+				if score := d.score(a); score < b.min || score > b.max {
+					continue
+				}
+				outcomes = append(outcomes, &p.Outcome)
+				if len(p.Violations) > 0 {
+					bucket.Violations++
+				}
+			}
+			bucket.Stats = Summarize(outcomes)
+			if bucket.Stats.Trades > 0 {
+				bucket.ViolationRate = float64(bucket.Violations) / float64(bucket.Stats.Trades) * 100
+			}
+			dimension.Buckets = append(dimension.Buckets, bucket)
+		}
+		report.Dimensions = append(report.Dimensions, dimension)
+	}
+
+	report.Size = compareSize(Summarize(oversized), Summarize(within))
+	return report
+}
+
+// equityOn returns the equity of the latest snapshot on or before the day of t
+func equityOn(snapshots []*models.EquitySnapshot, t time.Time) (float64, bool) {
+	day := dayKey(t)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if dayKey(snapshots[i].Date) <= day {
+			return snapshots[i].Equity, true
+		}
+	}
+	return 0, false
+}
+
+// dayKey returns the calendar date a date was recorded for, as YYYY-MM-DD. Dates are
+// stored at midnight UTC; one written with another zone, e.g. by an API client, is
+// read in its own zone, so values for the same day match whatever their zones.
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// compareSize decides whether oversized trades did worse, by average R when both
+// groups have trades with a defined risk and by expectancy otherwise
+func compareSize(oversized, within Stats) SizeComparison {
+	c := SizeComparison{Oversized: oversized, WithinSize: within}
+	if oversized.Trades == 0 || within.Trades == 0 {
+		return c
+	}
+	if oversized.RTrades > 0 && within.RTrades > 0 {
+		c.Underperformed = oversized.AverageR < within.AverageR
+	} else {
+		c.Underperformed = oversized.Expectancy < within.Expectancy
+	}
+	return c
+}
@@ -0,0 +1,655 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/rules"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// Format identifies backup documents written by this app
+const Format = "options-risk-management-backup"
+
+// FormatVersion is the version of the backup document layout
+const FormatVersion = 1
+
+// Import modes
+const (
+	ModeReplace = "replace" // The backup replaces all stored data
+	ModeMerge   = "merge"   // Records are added or updated by ID; others are kept
+)
+
+// Document is the file written by Export. Data is checksummed as compact JSON.
+type Document struct {
+	Format        string          `json:"format"`
+	Version       int             `json:"version"`       // Backup layout version
+	SchemaVersion int             `json:"schemaVersion"` // Database schema version of the records
+	CreatedAt     time.Time       `json:"createdAt"`
+	Checksum      string          `json:"checksum"` // "sha256:<hex>" of the compact Data
+	Data          json.RawMessage `json:"data"`
+}
+
+// Data holds every record in the database
+type Data struct {
+	Settings           *models.PositionSettings    `json:"settings"`
+	RiskAssessments    []*models.RiskAssessment    `json:"riskAssessments"`
+	StockRatings       []*models.StockRating       `json:"stockRatings"`
+	Trades             []*models.Trade             `json:"trades"`
+	TradeEvents        []*models.TradeEvent        `json:"tradeEvents"`
+	JournalEntries     []*models.JournalEntry      `json:"journalEntries"`
+	EquitySnapshots    []*models.EquitySnapshot    `json:"equitySnapshots"`
+	CashFlows          []*models.CashFlow          `json:"cashFlows"`
+	LossLimitOverrides []*models.LossLimitOverride `json:"lossLimitOverrides"`
+	Rules              []*models.Rule              `json:"rules"`
+}
+
+// Summary describes a written backup
+type Summary struct {
+	Path          string         `json:"path"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Checksum      string         `json:"checksum"`
+	Counts        map[string]int `json:"counts"` // Records per collection
+}
+
+// CollectionPreview is what an import does to one collection
+type CollectionPreview struct {
+	Name      string `json:"name"`
+	Added     int    `json:"added"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"` // Identical records that are skipped
+	Removed   int    `json:"removed"`   // Existing records dropped in replace mode
+}
+
+// Preview is what an import will do, or did once Applied is set
+type Preview struct {
+	Path          string              `json:"path"`
+	Mode          string              `json:"mode"`
+	CreatedAt     time.Time           `json:"createdAt"`     // When the backup was written
+	SchemaVersion int                 `json:"schemaVersion"` // Schema version of the backup
+	Migrated      bool                `json:"migrated"`      // The records were upgraded from SchemaVersion before the diff
+	Collections   []CollectionPreview `json:"collections"`
+	BadKeys       []string            `json:"badKeys"` // Existing records that could not be read and are left untouched
+	Applied       bool                `json:"applied"`
+}
+
+// Store exports and imports the whole database
+type Store struct {
+	db                   *database.DB
+	riskRepository       *database.RiskRepository
+	stockRepository      *database.StockRepository
+	tradeRepository      *database.TradeRepository
+	tradeEventRepository *database.TradeEventRepository
+	journalRepository    *database.JournalRepository
+	equityRepository     *database.EquityRepository
+	cashFlowRepository   *database.CashFlowRepository
+	lossLimitRepository  *database.LossLimitRepository
+	positionRepository   *database.PositionRepository
+	ruleRepository       *database.RuleRepository
+}
+
+// NewStore creates a backup store for a database
+func NewStore(db *database.DB) *Store {
+	return &Store{
+		db:                   db,
+		riskRepository:       database.NewRiskRepository(db),
+		stockRepository:      database.NewStockRepository(db),
+		tradeRepository:      database.NewTradeRepository(db),
+		tradeEventRepository: database.NewTradeEventRepository(db),
+		journalRepository:    database.NewJournalRepository(db),
+		equityRepository:     database.NewEquityRepository(db),
+		cashFlowRepository:   database.NewCashFlowRepository(db),
+		lossLimitRepository:  database.NewLossLimitRepository(db),
+		positionRepository:   database.NewPositionRepository(db),
+		ruleRepository:       database.NewRuleRepository(db),
+	}
+}
+
+// Export writes every record to path. Paths ending in .gz are gzip-compressed.
+func (s *Store) Export(path string) (*Summary, error) {
+	data, err := s.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion, err := s.db.SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup data: %w", err)
+	}
+
+	doc := &Document{
+		Format:        Format,
+		Version:       FormatVersion,
+		SchemaVersion: schemaVersion,
+		CreatedAt:     time.Now(),
+		Checksum:      checksum(raw),
+		Data:          raw,
+	}
+	if err := writeDocument(path, doc); err != nil {
+		return nil, err
+	}
+
+	return &Summary{
+		Path:          path,
+		CreatedAt:     doc.CreatedAt,
+		SchemaVersion: doc.SchemaVersion,
+		Checksum:      doc.Checksum,
+		Counts: map[string]int{
+			"riskAssessments":    len(data.RiskAssessments),
+			"stockRatings":       len(data.StockRatings),
+			"trades":             len(data.Trades),
+			"tradeEvents":        len(data.TradeEvents),
+			"journalEntries":     len(data.JournalEntries),
+			"equitySnapshots":    len(data.EquitySnapshots),
+			"cashFlows":          len(data.CashFlows),
+			"lossLimitOverrides": len(data.LossLimitOverrides),
+			"rules":              len(data.Rules),
+		},
+	}, nil
+}
+
+// Read loads a backup and verifies its format, schema version and checksum
+func Read(path string) (*Document, *Data, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	// Gzip files are detected by their magic number rather than the extension
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress backup: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	doc := &Document{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode backup: %w", err)
+	}
+
+	if doc.Format != Format {
+		return nil, nil, fmt.Errorf("not a backup file")
+	}
+	if doc.Version > FormatVersion {
+		return nil, nil, fmt.Errorf("backup format v%d is newer than supported v%d", doc.Version, FormatVersion)
+	}
+	if latest := database.LatestSchemaVersion(); doc.SchemaVersion > latest {
+		return nil, nil, fmt.Errorf("backup schema v%d is newer than supported v%d; update the app first", doc.SchemaVersion, latest)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, doc.Data); err != nil {
+		return nil, nil, fmt.Errorf("failed to read backup data: %w", err)
+	}
+	if checksum(compact.Bytes()) != doc.Checksum {
+		return nil, nil, fmt.Errorf("backup checksum does not match; the file is corrupt or was edited")
+	}
+
+	data := &Data{}
+	if err := json.Unmarshal(doc.Data, data); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode backup data: %w", err)
+	}
+
+	return doc, data, nil
+}
+
+// Preview reports what importing a backup would add, update, skip and remove
+func (s *Store) Preview(path, mode string) (*Preview, error) {
+	preview, _, err := s.plan(path, mode)
+	return preview, err
+}
+
+// importChunk is the number of records written per transaction on import, well
+// within badger's transaction size limit even for records with indexes
+const importChunk = 500
+
+// Import applies a backup. The changes are written in transactions of importChunk
+// records, so backups of any size fit badger's transaction limit. A snapshot of the
+// database is taken first and restored if a transaction fails, so either every change
+// is written or none is.
+func (s *Store) Import(path, mode string) (*Preview, error) {
+	preview, collections, err := s.plan(path, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []change
+	for _, c := range collections {
+		changes = append(changes, c.changes()...)
+	}
+
+	snapshot, err := os.CreateTemp("", "import-*.bak")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-import snapshot: %w", err)
+	}
+	snapshot.Close()
+	defer os.Remove(snapshot.Name())
+	if err := s.db.Snapshot(snapshot.Name()); err != nil {
+		return nil, fmt.Errorf("failed to write pre-import snapshot: %w", err)
+	}
+
+	for start := 0; start < len(changes); start += importChunk {
+		chunk := changes[start:min(start+importChunk, len(changes))]
+		err := s.db.Batch(func(tx *database.Tx) error {
+			for _, apply := range chunk {
+				if err := apply(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			continue
+		}
+		if restoreErr := s.db.RestoreSnapshot(snapshot.Name()); restoreErr != nil {
+			return nil, fmt.Errorf("failed to import backup after %d of %d changes (%v), and failed to restore the database: %w",
+				start, len(changes), err, restoreErr)
+		}
+		return nil, fmt.Errorf("failed to import backup, the database was restored: %w", err)
+	}
+
+	preview.Applied = true
+	return preview, nil
+}
+
+// plan reads a backup and diffs each collection against the database. Backups
+// of an older schema are migrated first. Collections the backup does not contain
+// are left untouched, even in replace mode.
+func (s *Store) plan(path, mode string) (*Preview, []collection, error) {
+	if mode != ModeReplace && mode != ModeMerge {
+		return nil, nil, fmt.Errorf("unknown import mode %q", mode)
+	}
+
+	doc, data, err := Read(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var contained map[string]json.RawMessage
+	if err := json.Unmarshal(doc.Data, &contained); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode backup data: %w", err)
+	}
+
+	migrated := doc.SchemaVersion < database.LatestSchemaVersion()
+	if migrated {
+		if data, err = migrate(doc.SchemaVersion, contained); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate backup from schema v%d: %w", doc.SchemaVersion, err)
+		}
+	}
+
+	preview := &Preview{
+		Path:          path,
+		Mode:          mode,
+		CreatedAt:     doc.CreatedAt,
+		SchemaVersion: doc.SchemaVersion,
+		Migrated:      migrated,
+		Collections:   []CollectionPreview{},
+		BadKeys:       []string{},
+	}
+	var collections []collection
+	for _, c := range s.collections(data) {
+		// Migrations can add records, such as the default rules, to a collection
+		// that an older backup did not have
+		if _, ok := contained[c.name()]; !ok && c.empty() {
+			continue
+		}
+
+		p, badKeys, err := c.diff(mode)
+		if err != nil {
+			return nil, nil, err
+		}
+		collections = append(collections, c)
+		preview.Collections = append(preview.Collections, p)
+		preview.BadKeys = append(preview.BadKeys, badKeys...)
+	}
+
+	return preview, collections, nil
+}
+
+// collections returns the import of every collection in data into the store
+func (s *Store) collections(data *Data) []collection {
+	return []collection{
+		&settingsCollection{repo: s.positionRepository, incoming: data.Settings},
+		newRecords("riskAssessments", s.riskRepository.Repository, data.RiskAssessments),
+		newRecords("stockRatings", s.stockRepository.Repository, data.StockRatings),
+		newRecords("trades", s.tradeRepository.Repository, data.Trades),
+		newRecords("tradeEvents", s.tradeEventRepository.Repository, data.TradeEvents),
+		newRecords("journalEntries", s.journalRepository.Repository, data.JournalEntries),
+		newRecords("equitySnapshots", s.equityRepository.Repository, data.EquitySnapshots),
+		newRecords("cashFlows", s.cashFlowRepository.Repository, data.CashFlows),
+		newRecords("lossLimitOverrides", s.lossLimitRepository.Repository, data.LossLimitOverrides),
+		newRecords("rules", s.ruleRepository.Repository, data.Rules),
+	}
+}
+
+// migrate loads the records of a backup, exactly as they were exported, into an
+// in-memory database at the backup's schema version, runs the migrations from
+// there to the current schema and reads the upgraded records back
+func migrate(schemaVersion int, contained map[string]json.RawMessage) (*Data, error) {
+	scratch, err := database.OpenMemory()
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	staging := NewStore(scratch)
+	err = scratch.Batch(func(tx *database.Tx) error {
+		for _, c := range staging.collections(&Data{}) {
+			if raw, ok := contained[c.name()]; ok {
+				if err := c.stage(tx, raw); err != nil {
+					return fmt.Errorf("failed to load %s: %w", c.name(), err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := scratch.SetSchemaVersion(schemaVersion); err != nil {
+		return nil, err
+	}
+	if _, err := scratch.Migrate(database.MigrationOptions{DefaultRules: rules.Defaults()}); err != nil {
+		return nil, err
+	}
+
+	data, err := staging.collect()
+	if err != nil {
+		return nil, err
+	}
+	// collect falls back to the default settings, which the backup did not have
+	if raw, ok := contained["settings"]; !ok || bytes.Equal(raw, []byte("null")) {
+		data.Settings = nil
+	}
+	return data, nil
+}
+
+// collect reads every record from the database
+func (s *Store) collect() (*Data, error) {
+	data := &Data{}
+	var err error
+
+	if data.Settings, err = s.positionRepository.GetSettings(); err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if data.RiskAssessments, _, err = s.riskRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read risk assessments: %w", err)
+	}
+	if data.StockRatings, _, err = s.stockRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read stock ratings: %w", err)
+	}
+	if data.Trades, _, err = s.tradeRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read trades: %w", err)
+	}
+	if data.TradeEvents, _, err = s.tradeEventRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read trade events: %w", err)
+	}
+	if data.JournalEntries, _, err = s.journalRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read journal entries: %w", err)
+	}
+	if data.EquitySnapshots, _, err = s.equityRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read equity snapshots: %w", err)
+	}
+	if data.CashFlows, _, err = s.cashFlowRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read cash flows: %w", err)
+	}
+	if data.LossLimitOverrides, _, err = s.lossLimitRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read loss limit overrides: %w", err)
+	}
+	if data.Rules, _, err = s.ruleRepository.GetAll(); err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	return data, nil
+}
+
+// writeDocument writes a backup to a temporary file and renames it into place,
+// so a failed export never leaves a truncated backup at path
+func writeDocument(path string, doc *Document) error {
+	if path == "" {
+		return fmt.Errorf("backup path cannot be empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".backup-*")
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz := gzip.NewWriter(tmp)
+		if _, err = gz.Write(encoded); err == nil {
+			err = gz.Close()
+		}
+	} else {
+		_, err = tmp.Write(encoded)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// checksum returns the sha256 of data in the Document.Checksum format
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// collection is one kind of record being imported
+type collection interface {
+	name() string                                     // Field name in Data
+	empty() bool                                      // The backup has no records for the collection
+	stage(tx *database.Tx, raw json.RawMessage) error // Writes the exported records unchanged, for migrating them
+	diff(mode string) (CollectionPreview, []string, error)
+	changes() []change // The writes planned by diff
+}
+
+// change is a single planned write of an import
+type change func(tx *database.Tx) error
+
+// records imports the records of one repository
+type records[T any] struct {
+	field    string
+	repo     *database.Repository[T]
+	incoming []*T
+	save     []*T     // Added and updated records
+	remove   []string // Keys of records removed in replace mode
+}
+
+// newRecords creates the import of one repository's records
+func newRecords[T any](name string, repo *database.Repository[T], incoming []*T) *records[T] {
+	return &records[T]{field: name, repo: repo, incoming: incoming}
+}
+
+func (c *records[T]) name() string { return c.field }
+
+func (c *records[T]) empty() bool { return len(c.incoming) == 0 }
+
+// stage writes each exported record as it is
+func (c *records[T]) stage(tx *database.Tx, raw json.RawMessage) error {
+	var encoded []json.RawMessage
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return err
+	}
+	for _, record := range encoded {
+		if bytes.Equal(record, []byte("null")) {
+			continue
+		}
+		if err := c.repo.PutEncodedTx(tx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diff compares the incoming records with the stored ones by key
+func (c *records[T]) diff(mode string) (CollectionPreview, []string, error) {
+	preview := CollectionPreview{Name: c.field}
+
+	existing, badKeys, err := c.repo.GetAll()
+	if err != nil {
+		return preview, nil, fmt.Errorf("failed to read %s: %w", c.field, err)
+	}
+
+	stored := make(map[string][]byte, len(existing))
+	for _, record := range existing {
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return preview, nil, err
+		}
+		stored[c.repo.KeyOf(record)] = encoded
+	}
+
+	seen := make(map[string]bool, len(c.incoming))
+	for _, record := range c.incoming {
+		if record == nil {
+			continue
+		}
+		key := c.repo.KeyOf(record)
+		seen[key] = true
+
+		current, ok := stored[key]
+		if !ok {
+			preview.Added++
+			c.save = append(c.save, record)
+			continue
+		}
+
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return preview, nil, err
+		}
+		if bytes.Equal(current, encoded) {
+			preview.Unchanged++
+			continue
+		}
+		preview.Updated++
+		c.save = append(c.save, record)
+	}
+
+	if mode == ModeReplace {
+		for key := range stored {
+			if !seen[key] {
+				preview.Removed++
+				c.remove = append(c.remove, key)
+			}
+		}
+	}
+
+	return preview, badKeys, nil
+}
+
+// changes removes and saves the planned records
+func (c *records[T]) changes() []change {
+	changes := make([]change, 0, len(c.remove)+len(c.save))
+	for _, key := range c.remove {
+		changes = append(changes, func(tx *database.Tx) error {
+			if err := c.repo.DeleteTx(tx, key); err != nil {
+				return fmt.Errorf("failed to remove %s %s: %w", c.field, key, err)
+			}
+			return nil
+		})
+	}
+	for _, record := range c.save {
+		changes = append(changes, func(tx *database.Tx) error {
+			if err := c.repo.SaveTx(tx, record); err != nil {
+				return fmt.Errorf("failed to save %s %s: %w", c.field, c.repo.KeyOf(record), err)
+			}
+			return nil
+		})
+	}
+	return changes
+}
+
+// settingsCollection imports the position settings, a single record
+type settingsCollection struct {
+	repo     *database.PositionRepository
+	incoming *models.PositionSettings
+	save     bool
+}
+
+func (c *settingsCollection) name() string { return "settings" }
+
+func (c *settingsCollection) empty() bool { return c.incoming == nil }
+
+// stage writes the exported settings
+func (c *settingsCollection) stage(tx *database.Tx, raw json.RawMessage) error {
+	var settings *models.PositionSettings
+	if err := json.Unmarshal(raw, &settings); err != nil || settings == nil {
+		return err
+	}
+	return c.repo.SaveSettingsTx(tx, settings)
+}
+
+// diff compares the incoming settings with the stored ones
+func (c *settingsCollection) diff(mode string) (CollectionPreview, []string, error) {
+	preview := CollectionPreview{Name: "settings"}
+	if c.incoming == nil {
+		return preview, nil, nil
+	}
+
+	current, err := c.repo.GetStored()
+	switch {
+	case err == badger.ErrKeyNotFound:
+		preview.Added = 1
+		c.save = true
+	case err != nil:
+		return preview, nil, fmt.Errorf("failed to read settings: %w", err)
+	case *current == *c.incoming:
+		preview.Unchanged = 1
+	default:
+		preview.Updated = 1
+		c.save = true
+	}
+
+	return preview, nil, nil
+}
+
+// changes writes the settings if they changed
+func (c *settingsCollection) changes() []change {
+	if !c.save {
+		return nil
+	}
+	return []change{func(tx *database.Tx) error { return c.repo.SaveSettingsTx(tx, c.incoming) }}
+}
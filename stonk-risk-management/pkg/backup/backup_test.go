@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/rules"
+)
+
+// openMigrated opens a database at the current schema, with the default rules seeded
+func openMigrated(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(database.MigrationOptions{SnapshotDir: t.TempDir(), DefaultRules: rules.Defaults()}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// writeBackup writes a backup document holding data at a schema version
+func writeBackup(t *testing.T, schemaVersion int, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.json")
+	doc := &Document{
+		Format:        Format,
+		Version:       FormatVersion,
+		SchemaVersion: schemaVersion,
+		CreatedAt:     time.Now(),
+		Checksum:      checksum([]byte(data)),
+		Data:          json.RawMessage(data),
+	}
+	if err := writeDocument(path, doc); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// A schema v1 backup has single-row trades and predates the warning rules
+func TestImportMigratesOlderBackups(t *testing.T) {
+	db := openMigrated(t)
+	store := NewStore(db)
+	rules, _, err := store.ruleRepository.GetAll()
+	if err != nil || len(rules) == 0 {
+		t.Fatalf("default rules = %d, %v", len(rules), err)
+	}
+
+	path := writeBackup(t, 1, `{"trades":[{"id":"t1","symbol":"AAPL","type":"Long Call",`+
+		`"entryPrice":5,"entryDate":"2026-10-01T00:00:00Z","expirationDate":"2026-11-20T00:00:00Z","status":"open"}]}`)
+
+	preview, err := store.Import(path, ModeReplace)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !preview.Migrated {
+		t.Error("preview.Migrated = false, want true")
+	}
+	for _, c := range preview.Collections {
+		if c.Name == "rules" && c.Removed > 0 {
+			t.Errorf("rules removed = %d, want the default rules kept", c.Removed)
+		}
+		if c.Name == "riskAssessments" || c.Name == "settings" {
+			t.Errorf("collection %s is not in the backup but was imported: %+v", c.Name, c)
+		}
+	}
+
+	after, _, err := store.ruleRepository.GetAll()
+	if err != nil || len(after) != len(rules) {
+		t.Errorf("rules after import = %d, %v, want %d", len(after), err, len(rules))
+	}
+	trade, err := store.tradeRepository.Get("t1")
+	if err != nil {
+		t.Fatalf("Get trade: %v", err)
+	}
+	if len(trade.Legs) != 1 || trade.Legs[0].FillPrice != 5 || trade.LegNumber != 1 {
+		t.Errorf("legs = %+v, want the summary leg added by the v2 migration", trade.Legs)
+	}
+}
+
+// Replace mode leaves collections the backup does not mention alone
+func TestImportKeepsMissingCollections(t *testing.T) {
+	db := openMigrated(t)
+	store := NewStore(db)
+	entry := &models.JournalEntry{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Outcome: models.OutcomeWin}
+	if err := store.journalRepository.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeBackup(t, database.LatestSchemaVersion(), `{"riskAssessments":[]}`)
+	if _, err := store.Import(path, ModeReplace); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	if _, err := store.journalRepository.Get(entry.ID); err != nil {
+		t.Errorf("journal entry removed by a backup without journal entries: %v", err)
+	}
+}
+
+// journalBackup writes a backup of journal entries, each with about 1 KB of notes
+func journalBackup(t *testing.T, entries []*models.JournalEntry) string {
+	t.Helper()
+	data, err := json.Marshal(&Data{JournalEntries: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeBackup(t, database.LatestSchemaVersion(), string(data))
+}
+
+func journalEntries(n int) []*models.JournalEntry {
+	entries := make([]*models.JournalEntry, n)
+	for i := range entries {
+		entries[i] = &models.JournalEntry{
+			ID:             fmt.Sprintf("entry-%05d", i),
+			Date:           time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Outcome:        models.OutcomeWin,
+			LessonsLearned: strings.Repeat("x", 1024),
+		}
+	}
+	return entries
+}
+
+// A backup larger than one badger transaction is imported in chunks
+func TestImportLargeBackup(t *testing.T) {
+	store := NewStore(openMigrated(t))
+
+	// About 15 MB of records, past the 9.6 MB a transaction holds by default
+	path := journalBackup(t, journalEntries(15000))
+	if _, err := store.Import(path, ModeReplace); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	entries, _, err := store.journalRepository.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 15000 {
+		t.Errorf("%d journal entries imported, want 15000", len(entries))
+	}
+}
+
+// When a later chunk fails, the chunks already written are rolled back
+func TestImportFailureRestoresDatabase(t *testing.T) {
+	store := NewStore(openMigrated(t))
+	kept := &models.JournalEntry{ID: "kept", Date: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Outcome: models.OutcomeLoss}
+	if err := store.journalRepository.Save(kept); err != nil {
+		t.Fatal(err)
+	}
+
+	// The last entry fails validation, after the first chunk is written
+	entries := journalEntries(importChunk + 10)
+	entries[len(entries)-1].Outcome = "great"
+	path := journalBackup(t, entries)
+
+	_, err := store.Import(path, ModeReplace)
+	if err == nil || !strings.Contains(err.Error(), "the database was restored") {
+		t.Fatalf("err = %v, want the import to fail and restore the database", err)
+	}
+
+	after, _, err := store.journalRepository.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != 1 || after[0].ID != "kept" {
+		t.Errorf("%d journal entries after the failed import, want only the one saved before", len(after))
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+const cashFlowPrefix = "cashflow:"
+
+// CashFlowRepository handles database operations for deposits and withdrawals
+type CashFlowRepository struct {
+	*Repository[models.CashFlow]
+}
+
+// NewCashFlowRepository creates a new cash flow repository
+func NewCashFlowRepository(db *DB) *CashFlowRepository {
+	return &CashFlowRepository{NewRepository(db, RepositoryConfig[models.CashFlow]{
+		Prefix:   cashFlowPrefix,
+		Key:      func(c *models.CashFlow) string { return c.ID },
+		Prepare:  func(c *models.CashFlow) { ensureID(&c.ID) },
+		Validate: (*models.CashFlow).Validate,
+		// Oldest first
+		Less: func(a, b *models.CashFlow) bool { return a.Date.Before(b.Date) },
+	})}
+}
+
+// SaveOpeningBalance records amount as the opening deposit, dated today, unless
+// cash flows have already been recorded. Later changes to the account value in
+// the settings then no longer move the equity curve.
+func (r *CashFlowRepository) SaveOpeningBalance(amount float64) error {
+	if amount <= 0 {
+		return nil
+	}
+	keys, err := r.db.GetKeysWithPrefix(cashFlowPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to read cash flows: %w", err)
+	}
+	if len(keys) > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return r.Save(&models.CashFlow{
+		Date:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Amount: amount,
+		Notes:  "Opening balance",
+	})
+}
+
+// seedOpeningBalance records the opening balance of databases created before cash
+// flows were tracked. It is the starting value of the oldest equity snapshot, the
+// account value the curve started from, or the saved account value when there are
+// no snapshots.
+func seedOpeningBalance(tx *Tx) (int, error) {
+	keys, err := tx.KeysWithPrefix(cashFlowPrefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) > 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	opening := &models.CashFlow{
+		Date:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Notes: "Opening balance",
+	}
+
+	snapshotKeys, err := tx.KeysWithPrefix(equityPrefix)
+	if err != nil {
+		return 0, err
+	}
+	// Keys sort by date, oldest first
+	found := false
+	for _, key := range snapshotKeys {
+		var oldest models.EquitySnapshot
+		if err := tx.Get(key, &oldest); err != nil {
+			tx.skip(key)
+			continue
+		}
+		y, m, d := oldest.Date.Date()
+		opening.Date = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		opening.Amount = oldest.StartingValue
+		found = true
+		break
+	}
+	if !found {
+		var settings models.PositionSettings
+		if err := tx.Get(positionSettingsKey, &settings); err == nil {
+			opening.Amount = settings.AccountValue
+		}
+	}
+	if opening.Amount <= 0 {
+		return 0, nil
+	}
+
+	ensureID(&opening.ID)
+	if err := tx.Put(cashFlowPrefix+opening.ID, opening); err != nil {
+		return 0, fmt.Errorf("failed to write opening balance: %w", err)
+	}
+	return 1, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"time"
 
@@ -11,10 +12,36 @@ import (
 // DB encapsulates the badger database
 type DB struct {
 	db       *badger.DB
+	path     string
 	gcTicker *time.Ticker
 	stopGC   chan struct{}
 }
 
+// storageErrors are the badger errors that mean the database itself failed,
+// rather than the request made of it
+var storageErrors = []error{
+	badger.ErrDBClosed,
+	badger.ErrBlockedWrites,
+	badger.ErrConflict,
+	badger.ErrTxnTooBig,
+	badger.ErrDiscardedTxn,
+	badger.ErrReadOnlyTxn,
+	badger.ErrTruncateNeeded,
+	badger.ErrRejected,
+	badger.ErrGCInMemoryMode,
+}
+
+// IsStorageError reports whether err is a failure of the database, such as a
+// closed database or a transaction conflict
+func IsStorageError(err error) bool {
+	for _, target := range storageErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // New creates a new database instance
 func New(dbPath string) (*DB, error) {
 	// Create directory if it doesn't exist
@@ -38,6 +65,7 @@ func New(dbPath string) (*DB, error) {
 	// Create DB instance
 	dbInstance := &DB{
 		db:     db,
+		path:   dbPath,
 		stopGC: make(chan struct{}),
 	}
 
@@ -91,6 +119,14 @@ func (d *DB) Put(key string, value interface{}) error {
 	})
 }
 
+// Batch runs fn in a single read-write transaction. Everything fn writes is
+// committed together, or nothing is if fn returns an error.
+func (d *DB) Batch(fn func(tx *Tx) error) error {
+	return d.db.Update(func(txn *badger.Txn) error {
+		return fn(&Tx{txn: txn})
+	})
+}
+
 // Get retrieves a value from the database
 func (d *DB) Get(key string, value interface{}) error {
 	var data []byte
@@ -142,6 +178,30 @@ func (d *DB) GetAllWithPrefix(prefix string) ([][]byte, error) {
 	return values, err
 }
 
+// GetRecordsWithPrefix retrieves the keys and values matching a given prefix
+func (d *DB) GetRecordsWithPrefix(prefix string) ([]Record, error) {
+	var records []Record
+
+	err := d.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefixBytes := []byte(prefix)
+		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
+			item := it.Item()
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			records = append(records, Record{Key: string(item.KeyCopy(nil)), Value: val})
+		}
+		return nil
+	})
+
+	return records, err
+}
+
 // GetKeysWithPrefix retrieves all keys matching a given prefix
 func (d *DB) GetKeysWithPrefix(prefix string) ([]string, error) {
 	var keys []string
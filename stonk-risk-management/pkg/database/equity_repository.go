@@ -0,0 +1,31 @@
+package database
+
+import (
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+const equityPrefix = "equity:"
+
+// EquityRepository handles database operations for daily equity snapshots
+type EquityRepository struct {
+	*Repository[models.EquitySnapshot]
+}
+
+// NewEquityRepository creates a new equity repository.
+// There is one snapshot per day; saving another for the same day replaces it.
+func NewEquityRepository(db *DB) *EquityRepository {
+	return &EquityRepository{NewRepository(db, RepositoryConfig[models.EquitySnapshot]{
+		// Format: equity:<YYYY-MM-DD>
+		Prefix: equityPrefix,
+		Key:    func(s *models.EquitySnapshot) string { return equityKey(s.Date) },
+		// Oldest first
+		Less: func(a, b *models.EquitySnapshot) bool { return a.Date.Before(b.Date) },
+	})}
+}
+
+// equityKey builds the key of the snapshot for a day after the prefix
+func equityKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
@@ -0,0 +1,279 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// indexPrefix is the key prefix of all secondary index entries. Each entry maps
+// idx:<record>:<field>:<value>:...:<id> to the primary key of the record it indexes.
+const indexPrefix = "idx:"
+
+// indexTimeLayout formats times so that index keys sort chronologically
+const indexTimeLayout = "20060102T150405.000000000"
+
+// indexSet describes the secondary indexes of one record type
+type indexSet struct {
+	name   string                              // Record name in index keys, e.g. "stock"
+	prefix string                              // Prefix of the primary keys
+	keys   func(data []byte) ([]string, error) // Index keys for a stored record
+}
+
+// indexSets lists every indexed record type, used to rebuild indexes
+var indexSets = []indexSet{
+	stockIndexes,
+	riskIndexes,
+	tradeIndexes,
+}
+
+// IndexRange selects index entries under a prefix, optionally bounded
+type IndexRange struct {
+	Prefix  string // Prefix shared by the entries, e.g. "idx:stock:symbol:AAPL:"
+	From    string // Lower bound appended to Prefix (inclusive), empty for none
+	To      string // Upper bound appended to Prefix (exclusive), empty for none
+	After   string // Full index key to resume after (exclusive), empty to start at the beginning
+	Reverse bool   // Return the largest (newest) keys first
+	Limit   int    // Maximum number of records, 0 for all
+}
+
+// indexKey joins the parts of an index key, escaping values so they cannot contain the separator
+func indexKey(name, field string, parts ...string) string {
+	escaped := make([]string, 0, len(parts)+2)
+	escaped = append(escaped, name, field)
+	for _, p := range parts {
+		escaped = append(escaped, url.QueryEscape(p))
+	}
+	return indexPrefix + strings.Join(escaped, ":")
+}
+
+// indexValuePrefix returns the prefix of the entries of an index with the given leading values
+func indexValuePrefix(name, field string, values ...string) string {
+	return indexKey(name, field, values...) + ":"
+}
+
+// indexTime formats a time for use in an index key
+func indexTime(t time.Time) string {
+	return t.UTC().Format(indexTimeLayout)
+}
+
+// GetIndexed returns the records referenced by the index entries in a range.
+// Entries whose record no longer exists are skipped.
+func (d *DB) GetIndexed(r IndexRange) ([]Record, error) {
+	var records []Record
+	err := d.ScanIndex(r, func(_ string, record Record) bool {
+		records = append(records, record)
+		return r.Limit <= 0 || len(records) < r.Limit
+	})
+	return records, err
+}
+
+// ScanIndex walks the index entries in a range in order, calling visit with each
+// entry's key and the record it references until visit returns false. Limit is
+// ignored; entries whose record no longer exists are skipped.
+func (d *DB) ScanIndex(r IndexRange, visit func(indexKey string, record Record) bool) error {
+	return d.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = r.Reverse
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(r.Prefix)
+		from, to := r.Prefix+r.From, r.Prefix+r.To
+
+		// Reverse iteration seeks to the largest key at or below the seek key
+		seek := from
+		if r.Reverse {
+			seek = r.Prefix + "\xff"
+			if r.To != "" {
+				seek = to
+			}
+		}
+		if r.After != "" && (r.Reverse && r.After < seek || !r.Reverse && r.After > seek) {
+			seek = r.After
+		}
+
+		for it.Seek([]byte(seek)); it.ValidForPrefix(prefix); it.Next() {
+			key := string(it.Item().Key())
+			if key == r.After {
+				continue
+			}
+			if r.From != "" && key < from {
+				if r.Reverse {
+					break
+				}
+				continue
+			}
+			if r.To != "" && key >= to {
+				if r.Reverse {
+					continue
+				}
+				break
+			}
+
+			primary, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			item, err := txn.Get(primary)
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if !visit(key, Record{Key: string(primary), Value: value}) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// ReindexReport summarizes an index rebuild
+type ReindexReport struct {
+	Entries int      `json:"entries"` // Index entries written
+	BadKeys []string `json:"badKeys"` // Records that could not be read and are left out of the indexes
+}
+
+// RebuildIndexes drops every secondary index entry and recreates them from the
+// stored records. Records that cannot be decoded are skipped and reported.
+func (d *DB) RebuildIndexes() (*ReindexReport, error) {
+	report := &ReindexReport{BadKeys: []string{}}
+	err := d.db.Update(func(txn *badger.Txn) error {
+		tx := &Tx{txn: txn}
+		var err error
+		report.Entries, err = rebuildIndexes(tx)
+		report.BadKeys = append(report.BadKeys, tx.badKeys...)
+		return err
+	})
+	return report, err
+}
+
+// rebuildIndexes recreates all secondary indexes within a transaction. A record
+// that cannot be decoded is skipped with tx.skip rather than failing the rebuild,
+// which runs as a migration when the app starts.
+func rebuildIndexes(tx *Tx) (int, error) {
+	stale, err := tx.KeysWithPrefix(indexPrefix)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range stale {
+		if err := tx.Delete(key); err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+
+	written := 0
+	for _, set := range indexSets {
+		keys, err := tx.KeysWithPrefix(set.prefix)
+		if err != nil {
+			return written, err
+		}
+		for _, key := range keys {
+			data, err := tx.raw(key)
+			if err != nil {
+				return written, fmt.Errorf("failed to read %s: %w", key, err)
+			}
+			if _, err := set.keys(data); err != nil {
+				tx.skip(key)
+				continue
+			}
+			n, err := tx.putIndexEntries(key, data, set)
+			if err != nil {
+				return written, err
+			}
+			written += n
+		}
+	}
+
+	return written, nil
+}
+
+// putIndexed stores a record, removing the index entries of the previous version
+func (t *Tx) putIndexed(key string, value interface{}, set indexSet) error {
+	if err := t.deleteIndexEntries(key, set); err != nil {
+		return err
+	}
+	if err := t.Put(key, value); err != nil {
+		return err
+	}
+
+	data, err := t.raw(key)
+	if err != nil {
+		return err
+	}
+	_, err = t.putIndexEntries(key, data, set)
+	return err
+}
+
+// deleteIndexed removes a record along with its index entries
+func (t *Tx) deleteIndexed(key string, set indexSet) error {
+	if err := t.deleteIndexEntries(key, set); err != nil {
+		return err
+	}
+	return t.Delete(key)
+}
+
+// indexedKeys returns the primary keys referenced by the index entries under a prefix
+func (t *Tx) indexedKeys(prefix string) ([]string, error) {
+	indexKeys, err := t.KeysWithPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(indexKeys))
+	for _, k := range indexKeys {
+		primary, err := t.raw(k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, string(primary))
+	}
+	return keys, nil
+}
+
+// putIndexEntries writes the index entries of a record
+func (t *Tx) putIndexEntries(key string, data []byte, set indexSet) (int, error) {
+	indexKeys, err := set.keys(data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to index %s: %w", key, err)
+	}
+	for _, k := range indexKeys {
+		if err := t.set(k, []byte(key)); err != nil {
+			return 0, err
+		}
+	}
+	return len(indexKeys), nil
+}
+
+// deleteIndexEntries removes the index entries of the stored version of a record, if any
+func (t *Tx) deleteIndexEntries(key string, set indexSet) error {
+	data, err := t.raw(key)
+	if err == badger.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// A record that cannot be parsed has no index entries to remove
+	indexKeys, err := set.keys(data)
+	if err != nil {
+		return nil
+	}
+	for _, k := range indexKeys {
+		if err := t.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/rules"
+)
+
+// seedUnreadable opens a database at schema v2, before indexing, holding one good and one corrupt trade
+func seedUnreadable(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	trade := &models.Trade{ID: "good", Symbol: "AAPL", Type: "Long Call", EntryDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	if err := db.Put(tradePrefix+"good", trade); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Batch(func(tx *Tx) error { return tx.set(tradePrefix+"bad", []byte("{not json")) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(schemaVersionKey, 2); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRebuildIndexesSkipsUnreadableRecords(t *testing.T) {
+	db := seedUnreadable(t)
+
+	report, err := db.RebuildIndexes()
+	if err != nil {
+		t.Fatalf("RebuildIndexes: %v", err)
+	}
+	if want := []string{tradePrefix + "bad"}; !reflect.DeepEqual(report.BadKeys, want) {
+		t.Errorf("bad keys = %v, want %v", report.BadKeys, want)
+	}
+	if report.Entries == 0 {
+		t.Error("no index entries written for the readable trade")
+	}
+}
+
+func TestMigrateReportsUnreadableRecords(t *testing.T) {
+	db := seedUnreadable(t)
+
+	report, err := db.Migrate(MigrationOptions{SnapshotDir: t.TempDir(), DefaultRules: rules.Defaults()})
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if report.ToVersion != LatestSchemaVersion() {
+		t.Errorf("migrated to v%d, want v%d", report.ToVersion, LatestSchemaVersion())
+	}
+
+	skipped := map[int][]string{}
+	for _, m := range report.Applied {
+		if len(m.BadKeys) > 0 {
+			skipped[m.Version] = m.BadKeys
+		}
+	}
+	want := map[int][]string{3: {tradePrefix + "bad"}, 4: {tradePrefix + "bad"}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
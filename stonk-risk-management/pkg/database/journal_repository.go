@@ -0,0 +1,41 @@
+package database
+
+import (
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+const journalPrefix = "journal:"
+
+// JournalRepository handles database operations for trade journal entries
+type JournalRepository struct {
+	*Repository[models.JournalEntry]
+}
+
+// NewJournalRepository creates a new journal repository
+func NewJournalRepository(db *DB) *JournalRepository {
+	return &JournalRepository{NewRepository(db, RepositoryConfig[models.JournalEntry]{
+		Prefix:   journalPrefix,
+		Key:      func(e *models.JournalEntry) string { return e.ID },
+		Prepare:  func(e *models.JournalEntry) { ensureID(&e.ID) },
+		Validate: (*models.JournalEntry).Validate,
+		// Sort by date (most recent first)
+		Less: func(a, b *models.JournalEntry) bool { return a.Date.After(b.Date) },
+	})}
+}
+
+// GetByTrade retrieves the journal entries for a trade
+func (r *JournalRepository) GetByTrade(tradeID string) ([]*models.JournalEntry, []string, error) {
+	return r.Filter(func(e *models.JournalEntry) bool {
+		return e.TradeID == tradeID
+	})
+}
+
+// GetByDateRange retrieves journal entries within a date range
+func (r *JournalRepository) GetByDateRange(start, end time.Time) ([]*models.JournalEntry, []string, error) {
+	return r.Filter(func(e *models.JournalEntry) bool {
+		return (e.Date.Equal(start) || e.Date.After(start)) &&
+			(e.Date.Equal(end) || e.Date.Before(end))
+	})
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+
+	"stonk-risk-management/pkg/models"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+const lossOverridePrefix = "loss_override:"
+
+// LossLimitRepository handles database operations for loss-limit overrides
+type LossLimitRepository struct {
+	*Repository[models.LossLimitOverride]
+}
+
+// NewLossLimitRepository creates a new loss-limit repository.
+// Overrides are keyed by their period so each period has at most one.
+func NewLossLimitRepository(db *DB) *LossLimitRepository {
+	return &LossLimitRepository{NewRepository(db, RepositoryConfig[models.LossLimitOverride]{
+		Prefix:  lossOverridePrefix,
+		Key:     func(o *models.LossLimitOverride) string { return overrideKey(o.Period, o.PeriodKey) },
+		Prepare: func(o *models.LossLimitOverride) { ensureID(&o.ID) },
+	})}
+}
+
+// GetOverride returns the override for a period, or nil if there is none
+func (r *LossLimitRepository) GetOverride(period, periodKey string) (*models.LossLimitOverride, error) {
+	override, err := r.Get(overrideKey(period, periodKey))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	return override, err
+}
+
+// overrideKey builds the key of the override for a period after the prefix
+func overrideKey(period, periodKey string) string {
+	return fmt.Sprintf("%s:%s", period, periodKey)
+}
@@ -0,0 +1,337 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+const schemaVersionKey = "meta:schema_version"
+
+// Migration upgrades the stored data from Version-1 to Version.
+// Up returns the number of records it changed. Records it cannot decode are
+// left as they are and reported with tx.skip.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *Tx) (int, error)
+}
+
+// migrations is the ordered registry of schema migrations.
+// New migrations must be appended with the next version number.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Move physical, P&L impact and other scores out of risk assessment notes",
+		Up:          migrateRiskExtendedScores,
+	},
+	{
+		Version:     2,
+		Description: "Convert single-row trades into one-leg trades",
+		Up:          migrateTradeLegs,
+	},
+	{
+		Version:     3,
+		Description: "Index trades, stock ratings and risk assessments for paged queries",
+		Up:          rebuildIndexes,
+	},
+	{
+		Version:     4,
+		Description: "Add OCC symbols to option legs",
+		Up:          migrateLegSymbols,
+	},
+	{
+		Version:     5,
+		Description: "Add the default warning rules",
+		Up:          seedDefaultRules,
+	},
+	{
+		Version:     6,
+		Description: "Record the opening balance as a deposit",
+		Up:          seedOpeningBalance,
+	},
+}
+
+// LatestSchemaVersion returns the schema version the code expects
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// MigrationOptions controls how pending migrations are run
+type MigrationOptions struct {
+	DryRun      bool   // Run the migrations but discard the changes
+	SnapshotDir string // Where to write the pre-migration snapshot, defaults to a sibling of the database directory
+
+	// DefaultRules are the warning rules migration 5 seeds, usually rules.Defaults().
+	// They are passed in so that storage does not depend on the rule engine.
+	DefaultRules []*models.Rule
+}
+
+// AppliedMigration reports a single migration that ran
+type AppliedMigration struct {
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	Records     int      `json:"records"`
+	BadKeys     []string `json:"badKeys"` // Records that could not be read and were left unchanged
+}
+
+// MigrationReport summarizes a migration run
+type MigrationReport struct {
+	FromVersion  int                `json:"fromVersion"`
+	ToVersion    int                `json:"toVersion"`
+	DryRun       bool               `json:"dryRun"`
+	SnapshotPath string             `json:"snapshotPath"` // Empty when nothing ran or in dry-run mode
+	Applied      []AppliedMigration `json:"applied"`
+}
+
+// SetSchemaVersion records the schema version of data that was loaded without
+// migrating it, so that Migrate upgrades it from that version
+func (d *DB) SetSchemaVersion(version int) error {
+	return d.Put(schemaVersionKey, version)
+}
+
+// SchemaVersion returns the schema version stored in the database (0 if never migrated)
+func (d *DB) SchemaVersion() (int, error) {
+	var version int
+	err := d.Get(schemaVersionKey, &version)
+	if err == badger.ErrKeyNotFound {
+		return 0, nil
+	}
+	return version, err
+}
+
+// Migrate runs every pending migration in order. Each migration writes its changes
+// together with its schema version in a single transaction, so a migration that fails
+// writes nothing and the data stays at the previous version. A migration whose changes
+// do not fit in one transaction fails with badger.ErrTxnTooBig in the same way.
+//
+// Unless running dry, the snapshot is written first so the upgrade can be rolled back
+// with RestoreSnapshot. A dry run migrates an in-memory copy of the database and
+// leaves the database itself untouched.
+func (d *DB) Migrate(opts MigrationOptions) (*MigrationReport, error) {
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	report := &MigrationReport{
+		FromVersion: current,
+		ToVersion:   current,
+		DryRun:      opts.DryRun,
+		Applied:     []AppliedMigration{},
+	}
+
+	if current > LatestSchemaVersion() {
+		return report, fmt.Errorf("database schema version %d is newer than supported version %d", current, LatestSchemaVersion())
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return report, nil
+	}
+
+	if opts.DryRun {
+		scratch, err := d.memoryCopy()
+		if err != nil {
+			return report, fmt.Errorf("failed to copy database for dry run: %w", err)
+		}
+		defer scratch.Close()
+		return report, scratch.apply(pending, opts, report)
+	}
+
+	// An in-memory database has nothing to roll back to
+	if d.path == "" {
+		return report, d.apply(pending, opts, report)
+	}
+
+	dir := opts.SnapshotDir
+	if dir == "" {
+		dir = d.SnapshotDir()
+	}
+	name := fmt.Sprintf("schema-v%d-%s.bak", current, time.Now().Format("20060102-150405"))
+	report.SnapshotPath = filepath.Join(dir, name)
+	if err := d.Snapshot(report.SnapshotPath); err != nil {
+		return report, fmt.Errorf("failed to write pre-migration snapshot: %w", err)
+	}
+
+	return report, d.apply(pending, opts, report)
+}
+
+// apply runs migrations one after the other, recording each in the report
+func (d *DB) apply(pending []Migration, opts MigrationOptions, report *MigrationReport) error {
+	for _, m := range pending {
+		records, badKeys, err := d.applyMigration(m, opts)
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		report.Applied = append(report.Applied, AppliedMigration{
+			Version:     m.Version,
+			Description: m.Description,
+			Records:     records,
+			BadKeys:     badKeys,
+		})
+		report.ToVersion = m.Version
+	}
+	return nil
+}
+
+// applyMigration runs a single migration and writes its changes and schema version
+// in one transaction. It returns the number of records changed and the keys of the
+// records that were skipped because they could not be read.
+func (d *DB) applyMigration(m Migration, opts MigrationOptions) (int, []string, error) {
+	records, badKeys := 0, []string{}
+	err := d.db.Update(func(txn *badger.Txn) error {
+		tx := &Tx{txn: txn, badKeys: []string{}, rules: opts.DefaultRules}
+		var err error
+		records, err = m.Up(tx)
+		badKeys = tx.badKeys
+		if err != nil {
+			return err
+		}
+		return tx.Put(schemaVersionKey, m.Version)
+	})
+	if errors.Is(err, badger.ErrTxnTooBig) {
+		return records, badKeys, fmt.Errorf("changes are too large for one transaction, nothing was written: %w", err)
+	}
+	return records, badKeys, err
+}
+
+// memoryCopy loads a full backup of the database into an in-memory database
+func (d *DB) memoryCopy() (*DB, error) {
+	var buf bytes.Buffer
+	if _, err := d.db.Backup(&buf, 0); err != nil {
+		return nil, err
+	}
+
+	db, err := openMemory()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Load(&buf, 256); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// OpenMemory opens an empty in-memory database, e.g. to migrate the records of
+// an older backup before importing them. Migrate writes no snapshot for it.
+func OpenMemory() (*DB, error) {
+	db, err := openMemory()
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// SnapshotDir returns the directory pre-migration snapshots are written to by default
+func (d *DB) SnapshotDir() string {
+	return d.path + "-snapshots"
+}
+
+// ListSnapshots returns the paths of the snapshots in SnapshotDir, newest first
+func (d *DB) ListSnapshots() ([]string, error) {
+	entries, err := os.ReadDir(d.SnapshotDir())
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type snapshot struct {
+		path    string
+		modTime time.Time
+	}
+	var found []snapshot
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".bak" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, snapshot{filepath.Join(d.SnapshotDir(), e.Name()), info.ModTime()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+
+	paths := make([]string, len(found))
+	for i, s := range found {
+		paths[i] = s.path
+	}
+	return paths, nil
+}
+
+// Snapshot writes a full backup of the database to path
+func (d *DB) Snapshot(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.Backup(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// RestoreSnapshot replaces the entire contents of the database with a snapshot
+// previously written by Snapshot. The database is left at the snapshot's schema
+// version and is migrated again the next time it is opened by the app or orm.
+func (d *DB) RestoreSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Check the snapshot loads before clearing the database
+	if err := validateSnapshot(data); err != nil {
+		return fmt.Errorf("%s is not a readable snapshot: %w", path, err)
+	}
+
+	if err := d.db.DropAll(); err != nil {
+		return fmt.Errorf("failed to clear database: %w", err)
+	}
+
+	return d.db.Load(bytes.NewReader(data), 256)
+}
+
+// validateSnapshot loads a snapshot into a throwaway in-memory database
+func validateSnapshot(data []byte) error {
+	scratch, err := openMemory()
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+	return scratch.Load(bytes.NewReader(data), 256)
+}
+
+// openMemory opens an empty in-memory badger database
+func openMemory() (*badger.DB, error) {
+	options := badger.DefaultOptions("").WithInMemory(true)
+	options.Logger = nil
+	return badger.Open(options)
+}
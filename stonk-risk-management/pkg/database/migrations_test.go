@@ -0,0 +1,82 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// A migration that fails writes nothing, and the stored version stays where it was
+func TestApplyMigrationIsAtomic(t *testing.T) {
+	failing := errors.New("upgrade failed")
+	tooBig := func(tx *Tx) (int, error) {
+		value := []byte(strings.Repeat("x", 1024))
+		for i := 0; ; i++ {
+			if err := tx.set(fmt.Sprintf("test:%06d", i), value); err != nil {
+				return i, err
+			}
+		}
+	}
+
+	tests := []struct {
+		name string
+		up   func(tx *Tx) (int, error)
+		want error
+	}{
+		{"error part way", func(tx *Tx) (int, error) {
+			if err := tx.Put("test:written", 1); err != nil {
+				return 0, err
+			}
+			return 1, failing
+		}, failing},
+		{"too large for one transaction", tooBig, badger.ErrTxnTooBig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := OpenMemory()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if err := db.SetSchemaVersion(2); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = db.applyMigration(Migration{Version: 3, Up: tt.up}, MigrationOptions{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+
+			var keys []string
+			err = db.Batch(func(tx *Tx) error {
+				keys, err = tx.KeysWithPrefix("test:")
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != 0 {
+				t.Errorf("%d keys written, want none", len(keys))
+			}
+			if version, err := db.SchemaVersion(); err != nil || version != 2 {
+				t.Errorf("schema version = %d, %v, want 2", version, err)
+			}
+		})
+	}
+}
+
+// Versions are consecutive from 1, so none is kept only to be skipped
+func TestMigrationVersions(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Up == nil || m.Description == "" {
+			t.Errorf("migration %d has no Up or description", m.Version)
+		}
+	}
+}
@@ -38,6 +38,7 @@ func (r *PositionRepository) GetSettings() (*models.PositionSettings, error) {
 				CorrelationAdjustment: 1,
 				VolatilityMultiplier:  1,
 				MaxDrawdownTolerance:  15,
+				RiskEnforcement:       "warn",
 			}, nil
 		}
 		return nil, err
@@ -46,7 +47,22 @@ func (r *PositionRepository) GetSettings() (*models.PositionSettings, error) {
 	return settings, nil
 }
 
+// GetStored reads the saved settings without falling back to the defaults.
+// It returns badger.ErrKeyNotFound if no settings have been saved.
+func (r *PositionRepository) GetStored() (*models.PositionSettings, error) {
+	settings := &models.PositionSettings{}
+	if err := r.db.Get(positionSettingsKey, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
 // SaveSettings saves the user's position settings
 func (r *PositionRepository) SaveSettings(settings *models.PositionSettings) error {
 	return r.db.Put(positionSettingsKey, settings)
 }
+
+// SaveSettingsTx saves the user's position settings as part of a batch
+func (r *PositionRepository) SaveSettingsTx(tx *Tx, settings *models.PositionSettings) error {
+	return tx.Put(positionSettingsKey, settings)
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Sort directions
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Page size limits
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Query selects one page of records. Filters that do not apply to a record type are ignored.
+type Query struct {
+	Start    time.Time `json:"start"`    // Earliest date (inclusive), zero for no bound
+	End      time.Time `json:"end"`      // Latest date (inclusive), zero for no bound
+	Symbol   string    `json:"symbol"`   // Exact symbol
+	Sector   string    `json:"sector"`   // Exact sector
+	Strategy string    `json:"strategy"` // Strategy category or type
+	SortBy   string    `json:"sortBy"`   // Field to sort by, defaults to the record's date
+	SortDir  string    `json:"sortDir"`  // asc or desc, defaults to desc
+	Cursor   string    `json:"cursor"`   // NextCursor of the previous page, empty for the first page
+	Limit    int       `json:"limit"`    // Page size, defaults to DefaultPageSize
+}
+
+// TradePage is one page of trades
+type TradePage struct {
+	Items      []*models.Trade `json:"items"`
+	NextCursor string          `json:"nextCursor"` // Empty on the last page
+}
+
+// StockRatingPage is one page of stock ratings
+type StockRatingPage struct {
+	Items      []*models.StockRating `json:"items"`
+	NextCursor string                `json:"nextCursor"` // Empty on the last page
+}
+
+// RiskAssessmentPage is one page of risk assessments
+type RiskAssessmentPage struct {
+	Items      []*models.RiskAssessment `json:"items"`
+	NextCursor string                   `json:"nextCursor"` // Empty on the last page
+}
+
+// limit returns the page size of the query
+func (q *Query) limit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultPageSize
+	case q.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return q.Limit
+	}
+}
+
+// reverse reports whether the query sorts in descending order
+func (q *Query) reverse() (bool, error) {
+	switch strings.ToLower(q.SortDir) {
+	case "", SortDesc:
+		return true, nil
+	case SortAsc:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown sort direction %q", q.SortDir)
+	}
+}
+
+// inRange reports whether a date falls within the query's date range
+func (q *Query) inRange(date time.Time) bool {
+	if !q.Start.IsZero() && date.Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && date.After(q.End) {
+		return false
+	}
+	return true
+}
+
+// timeBounds returns the index bounds of the date range, for indexes whose
+// entries continue with the record's time after the prefix
+func (q *Query) timeBounds() (from, to string) {
+	if !q.Start.IsZero() {
+		from = indexTime(q.Start)
+	}
+	if !q.End.IsZero() {
+		to = indexTime(q.End.Add(time.Nanosecond))
+	}
+	return from, to
+}
+
+// plan fills in the cursor, direction and date bounds of an index range.
+// timeOrdered is true when the entries under the prefix are ordered by the record's date.
+func (q *Query) plan(rng IndexRange, timeOrdered bool) (IndexRange, error) {
+	reverse, err := q.reverse()
+	if err != nil {
+		return rng, err
+	}
+	rng.Reverse = reverse
+
+	if timeOrdered {
+		rng.From, rng.To = q.timeBounds()
+	}
+
+	if q.Cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil || !strings.HasPrefix(string(after), rng.Prefix) {
+			return rng, fmt.Errorf("invalid cursor")
+		}
+		rng.After = string(after)
+	}
+
+	return rng, nil
+}
+
+// queryPage reads up to limit records matching keep from an index range, returning
+// them with the cursor of the next page and the keys of records that could not be decoded
+func queryPage[T any](r *Repository[T], rng IndexRange, limit int, keep func(record *T) bool) ([]*T, string, []string, error) {
+	items := make([]*T, 0, limit)
+	var badKeys []string
+	var lastKey, next string
+
+	err := r.db.ScanIndex(rng, func(indexKey string, stored Record) bool {
+		records, bad := decodeRecords[T]([]Record{stored})
+		badKeys = append(badKeys, bad...)
+		if len(records) == 0 || !keep(records[0]) {
+			return true
+		}
+
+		// A match past the page means there is a next page
+		if len(items) == limit {
+			next = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+			return false
+		}
+		items = append(items, records[0])
+		lastKey = indexKey
+		return true
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return items, next, badKeys, nil
+}
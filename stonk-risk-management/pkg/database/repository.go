@@ -0,0 +1,234 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Record is a stored key and its raw JSON value
+type Record struct {
+	Key   string
+	Value []byte
+}
+
+// RepositoryConfig describes how a Repository stores one record type
+type RepositoryConfig[T any] struct {
+	Prefix   string                 // Key prefix of the records, e.g. "trade:"
+	Key      func(record *T) string // Key of a record after the prefix, usually its ID
+	Prepare  func(record *T)        // Optional, assigns IDs and defaults before validation
+	Validate func(record *T) error  // Optional, rejects records before they are saved
+	Less     func(a, b *T) bool     // Optional, order of the records returned by GetAll
+	Indexes  *indexSet              // Optional secondary indexes written with each record
+}
+
+// Repository stores JSON records of one type under a key prefix.
+// Reads that return several records skip records that cannot be decoded and
+// return their keys alongside the records, so callers can report them.
+type Repository[T any] struct {
+	db     *DB
+	config RepositoryConfig[T]
+}
+
+// NewRepository creates a new repository for a record type
+func NewRepository[T any](db *DB, config RepositoryConfig[T]) *Repository[T] {
+	return &Repository[T]{db: db, config: config}
+}
+
+// Save prepares, validates and stores a record, replacing any record with the same key
+func (r *Repository[T]) Save(record *T) error {
+	return r.db.Batch(func(tx *Tx) error {
+		return r.SaveTx(tx, record)
+	})
+}
+
+// SaveTx prepares, validates and stores a record as part of a batch
+func (r *Repository[T]) SaveTx(tx *Tx, record *T) error {
+	if r.config.Prepare != nil {
+		r.config.Prepare(record)
+	}
+	if r.config.Validate != nil {
+		if err := r.config.Validate(record); err != nil {
+			return err
+		}
+	}
+
+	key := r.key(r.config.Key(record))
+	if r.config.Indexes != nil {
+		return tx.putIndexed(key, record, *r.config.Indexes)
+	}
+	return tx.Put(key, record)
+}
+
+// PutEncodedTx stores a record exactly as encoded, without preparing, validating or
+// indexing it, so that migrations see it as an older version of the app stored it
+func (r *Repository[T]) PutEncodedTx(tx *Tx, data json.RawMessage) error {
+	record := new(T)
+	if err := json.Unmarshal(data, record); err != nil {
+		return err
+	}
+	return tx.set(r.key(r.KeyOf(record)), data)
+}
+
+// Get retrieves a record by the part of its key after the prefix
+func (r *Repository[T]) Get(id string) (*T, error) {
+	record := new(T)
+	if err := r.db.Get(r.key(id), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Delete removes a record by the part of its key after the prefix
+func (r *Repository[T]) Delete(id string) error {
+	return r.db.Batch(func(tx *Tx) error {
+		return r.DeleteTx(tx, id)
+	})
+}
+
+// DeleteTx removes a record as part of a batch
+func (r *Repository[T]) DeleteTx(tx *Tx, id string) error {
+	return r.deleteKey(tx, r.key(id))
+}
+
+// GetAll retrieves all records in the repository's sort order, along with the
+// keys of records that could not be decoded
+func (r *Repository[T]) GetAll() ([]*T, []string, error) {
+	return r.GetWithPrefix("")
+}
+
+// GetWithPrefix retrieves the records whose key continues with a prefix, e.g. the
+// events of one trade, in the repository's sort order
+func (r *Repository[T]) GetWithPrefix(prefix string) ([]*T, []string, error) {
+	stored, err := r.db.GetRecordsWithPrefix(r.key(prefix))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, badKeys := decodeRecords[T](stored)
+	if r.config.Less != nil {
+		sort.SliceStable(records, func(i, j int) bool {
+			return r.config.Less(records[i], records[j])
+		})
+	}
+	return records, badKeys, nil
+}
+
+// Filter retrieves the records for which keep returns true, in the repository's sort order
+func (r *Repository[T]) Filter(keep func(record *T) bool) ([]*T, []string, error) {
+	all, badKeys, err := r.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filtered := make([]*T, 0, len(all))
+	for _, record := range all {
+		if keep(record) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, badKeys, nil
+}
+
+// GetIndexed retrieves the records referenced by a secondary index range, in index order
+func (r *Repository[T]) GetIndexed(rng IndexRange) ([]*T, []string, error) {
+	stored, err := r.db.GetIndexed(rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, badKeys := decodeRecords[T](stored)
+	return records, badKeys, nil
+}
+
+// FirstIndexed retrieves the first readable record of an index range, or nil if there is none
+func (r *Repository[T]) FirstIndexed(rng IndexRange) (*T, []string, error) {
+	// Widen the scan past records that cannot be decoded
+	for limit := 1; ; limit++ {
+		rng.Limit = limit
+		stored, err := r.db.GetIndexed(rng)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		records, badKeys := decodeRecords[T](stored)
+		if len(records) > 0 {
+			return records[0], badKeys, nil
+		}
+		if len(stored) < limit {
+			return nil, badKeys, nil
+		}
+	}
+}
+
+// DeleteWithPrefix removes the records whose key continues with a prefix
+func (r *Repository[T]) DeleteWithPrefix(prefix string) error {
+	return r.db.Batch(func(tx *Tx) error {
+		keys, err := tx.KeysWithPrefix(r.key(prefix))
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := r.deleteKey(tx, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteKey removes a record and its index entries by full key
+func (r *Repository[T]) deleteKey(tx *Tx, key string) error {
+	if r.config.Indexes != nil {
+		return tx.deleteIndexed(key, *r.config.Indexes)
+	}
+	return tx.Delete(key)
+}
+
+// KeyOf returns the key of a record after the prefix, as accepted by Get and Delete
+func (r *Repository[T]) KeyOf(record *T) string {
+	if r.config.Prepare != nil {
+		r.config.Prepare(record)
+	}
+	return r.config.Key(record)
+}
+
+// key builds the full key of a record
+func (r *Repository[T]) key(id string) string {
+	return fmt.Sprintf("%s%s", r.config.Prefix, id)
+}
+
+// decodeRecords unmarshals stored records, returning the keys of those that fail
+func decodeRecords[T any](stored []Record) ([]*T, []string) {
+	records := make([]*T, 0, len(stored))
+	var badKeys []string
+	for _, s := range stored {
+		record := new(T)
+		if err := json.Unmarshal(s.Value, record); err != nil {
+			badKeys = append(badKeys, s.Key)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, badKeys
+}
+
+// ensureID assigns a new ID if one has not been set
+func ensureID(id *string) {
+	if *id == "" {
+		*id = uuid.New().String()
+	}
+}
+
+// LogBadKeys passes through the readable records of a repository read, logging the
+// keys of records that were skipped because they could not be decoded
+func LogBadKeys[T any](records T, badKeys []string, err error) (T, error) {
+	if len(badKeys) > 0 {
+		log.Printf("Skipped %d unreadable records: %s", len(badKeys), strings.Join(badKeys, ", "))
+	}
+	return records, err
+}
@@ -3,90 +3,199 @@ package database
 import (
 	"encoding/json"
 	"fmt"
-	"sort"
 	"time"
 
 	"stonk-risk-management/pkg/models"
-
-	"github.com/google/uuid"
 )
 
 const riskPrefix = "risk:"
 
 // RiskRepository handles database operations for risk assessments
 type RiskRepository struct {
-	db *DB
+	*Repository[models.RiskAssessment]
 }
 
 // NewRiskRepository creates a new risk repository
 func NewRiskRepository(db *DB) *RiskRepository {
-	return &RiskRepository{db: db}
+	return &RiskRepository{NewRepository(db, RepositoryConfig[models.RiskAssessment]{
+		Prefix:   riskPrefix,
+		Key:      func(a *models.RiskAssessment) string { return a.ID },
+		Prepare:  func(a *models.RiskAssessment) { ensureID(&a.ID) },
+		Validate: (*models.RiskAssessment).Validate,
+		// Sort by date with newest last
+		Less:    func(a, b *models.RiskAssessment) bool { return a.Date.Before(b.Date) },
+		Indexes: &riskIndexes,
+	})}
 }
 
-// Save saves a risk assessment to the database
-func (r *RiskRepository) Save(assessment *models.RiskAssessment) error {
-	if assessment.ID == "" {
-		assessment.ID = uuid.New().String()
-	}
-	key := fmt.Sprintf("%s%s", riskPrefix, assessment.ID)
-	return r.db.Put(key, assessment)
+// Risk assessment index fields
+const (
+	riskIndexName = "risk"
+	riskByDate    = "date" // idx:risk:date:<time>:<id>
+)
+
+// riskIndexes indexes risk assessments by time
+var riskIndexes = indexSet{
+	name:   riskIndexName,
+	prefix: riskPrefix,
+	keys: func(data []byte) ([]string, error) {
+		assessment := &models.RiskAssessment{}
+		if err := json.Unmarshal(data, assessment); err != nil {
+			return nil, err
+		}
+		return []string{
+			indexKey(riskIndexName, riskByDate, indexTime(assessment.Date), assessment.ID),
+		}, nil
+	},
 }
 
-// Get retrieves a risk assessment by ID
-func (r *RiskRepository) Get(id string) (*models.RiskAssessment, error) {
-	key := fmt.Sprintf("%s%s", riskPrefix, id)
-	assessment := &models.RiskAssessment{}
-	err := r.db.Get(key, assessment)
+// Query returns a page of risk assessments, sorted by "date"
+func (r *RiskRepository) Query(q Query) (*RiskAssessmentPage, []string, error) {
+	if q.SortBy != "" && q.SortBy != "date" {
+		return nil, nil, fmt.Errorf("cannot sort risk assessments by %q", q.SortBy)
+	}
+
+	rng, err := q.plan(IndexRange{Prefix: indexValuePrefix(riskIndexName, riskByDate)}, true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return assessment, nil
+
+	items, next, badKeys, err := queryPage(r.Repository, rng, q.limit(), func(a *models.RiskAssessment) bool {
+		return q.inRange(a.Date)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &RiskAssessmentPage{Items: items, NextCursor: next}, badKeys, nil
 }
 
-// Delete removes a risk assessment from the database
-func (r *RiskRepository) Delete(id string) error {
-	key := fmt.Sprintf("%s%s", riskPrefix, id)
-	return r.db.Delete(key)
+// GetByDateRange retrieves risk assessments within a date range (inclusive), oldest first
+func (r *RiskRepository) GetByDateRange(start, end time.Time) ([]*models.RiskAssessment, []string, error) {
+	return r.GetIndexed(IndexRange{
+		Prefix: indexValuePrefix(riskIndexName, riskByDate),
+		From:   indexTime(start),
+		To:     indexTime(end.Add(time.Nanosecond)),
+	})
+}
+
+// GetLatest retrieves the most recent risk assessment, or nil if there is none
+func (r *RiskRepository) GetLatest() (*models.RiskAssessment, []string, error) {
+	return r.FirstIndexed(IndexRange{
+		Prefix:  indexValuePrefix(riskIndexName, riskByDate),
+		Reverse: true,
+	})
 }
 
-// GetAll retrieves all risk assessments
-func (r *RiskRepository) GetAll() ([]*models.RiskAssessment, error) {
-	values, err := r.db.GetAllWithPrefix(riskPrefix)
+// migrateRiskExtendedScores upgrades assessments saved before the physical, P&L impact
+// and other scores were part of the model. The UI used to store those scores inside
+// the notes field as {"userNotes": ..., "extraData": {...}}; they are moved into their
+// own fields and the notes are restored to the user's text. Records from the old
+// 1..10 scale are rescaled, records without the extended scores get defaults (see
+// upgradeLegacyAssessment) and the overall score is recomputed.
+func migrateRiskExtendedScores(tx *Tx) (int, error) {
+	keys, err := tx.KeysWithPrefix(riskPrefix)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	assessments := make([]*models.RiskAssessment, 0, len(values))
-	for _, v := range values {
+	migrated := 0
+	for _, key := range keys {
+		var raw map[string]json.RawMessage
+		if err := tx.Get(key, &raw); err != nil {
+			tx.skip(key)
+			continue
+		}
+
+		// Records that already carry the new fields are up to date
+		if _, ok := raw["physicalScore"]; ok {
+			continue
+		}
+
 		assessment := &models.RiskAssessment{}
-		if err := json.Unmarshal(v, assessment); err != nil {
-			return nil, err
+		if err := tx.Get(key, assessment); err != nil {
+			tx.skip(key)
+			continue
 		}
-		assessments = append(assessments, assessment)
-	}
 
-	// Sort by date with newest last
-	sort.Slice(assessments, func(i, j int) bool {
-		return assessments[i].Date.Before(assessments[j].Date)
-	})
+		upgradeLegacyAssessment(assessment)
 
-	return assessments, nil
+		if err := tx.Put(key, assessment); err != nil {
+			return migrated, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
 }
 
-// GetByDateRange retrieves risk assessments within a date range
-func (r *RiskRepository) GetByDateRange(start, end time.Time) ([]*models.RiskAssessment, error) {
-	all, err := r.GetAll()
-	if err != nil {
-		return nil, err
+// upgradeLegacyAssessment fills the extended scores of an assessment from its legacy notes envelope.
+//
+// The envelope was only written by the -3..+3 form, so its scores are taken as-is. Records
+// without it predate the extended scores and may still be on the 1..10 scale the model
+// used to document; when any of their scores lies outside -3..+3 all of them are rescaled
+// proportionally. A legacy record whose scores all happen to be 1..3 cannot be told apart
+// from a -3..+3 one and is left unscaled.
+//
+// The physical, P&L impact and other scores of records without the envelope were never
+// asked for, so all three are copied from the record's overall score, the trader's own
+// summary of the day. The copy is an estimate: the dimensions do not share a direction
+// (FOMO and emotional state are at their worst at +3, and P&L impact is best at +1), but
+// the overall score is the only judgment such a record holds, and a zero P&L impact
+// would cost each old assessment 3 points of size.
+func upgradeLegacyAssessment(assessment *models.RiskAssessment) {
+	var envelope struct {
+		UserNotes *string `json:"userNotes"`
+		ExtraData *struct {
+			PhysicalScore int `json:"physicalScore"`
+			PLImpactScore int `json:"plImpactScore"`
+			OtherScore    int `json:"otherScore"`
+		} `json:"extraData"`
 	}
 
-	var filtered []*models.RiskAssessment
-	for _, a := range all {
-		if (a.Date.Equal(start) || a.Date.After(start)) &&
-			(a.Date.Equal(end) || a.Date.Before(end)) {
-			filtered = append(filtered, a)
+	// Notes that are not the envelope are plain user text and are kept as-is
+	if err := json.Unmarshal([]byte(assessment.Notes), &envelope); err == nil && envelope.ExtraData != nil {
+		assessment.PhysicalScore = envelope.ExtraData.PhysicalScore
+		assessment.PLImpactScore = envelope.ExtraData.PLImpactScore
+		assessment.OtherScore = envelope.ExtraData.OtherScore
+		if envelope.UserNotes != nil {
+			assessment.Notes = *envelope.UserNotes
+		} else {
+			assessment.Notes = ""
 		}
+	} else {
+		if isLegacyScale(assessment) {
+			assessment.EmotionalScore = models.RescaleLegacyScore(assessment.EmotionalScore)
+			assessment.FOMOScore = models.RescaleLegacyScore(assessment.FOMOScore)
+			assessment.BiasScore = models.RescaleLegacyScore(assessment.BiasScore)
+			assessment.OverallScore = models.RescaleLegacyScore(assessment.OverallScore)
+		}
+		fallback := models.ClampScore(assessment.OverallScore)
+		assessment.PhysicalScore = fallback
+		assessment.PLImpactScore = fallback
+		assessment.OtherScore = fallback
 	}
 
-	return filtered, nil
+	assessment.EmotionalScore = models.ClampScore(assessment.EmotionalScore)
+	assessment.FOMOScore = models.ClampScore(assessment.FOMOScore)
+	assessment.BiasScore = models.ClampScore(assessment.BiasScore)
+	assessment.PhysicalScore = models.ClampScore(assessment.PhysicalScore)
+	assessment.PLImpactScore = models.ClampScore(assessment.PLImpactScore)
+	assessment.OtherScore = models.ClampScore(assessment.OtherScore)
+	assessment.CalculateOverall()
+}
+
+// isLegacyScale reports whether an assessment's scores are on the old 1..10 scale:
+// none is negative and at least one is above +3
+func isLegacyScale(assessment *models.RiskAssessment) bool {
+	legacy := false
+	for _, score := range []int{assessment.EmotionalScore, assessment.FOMOScore, assessment.BiasScore, assessment.OverallScore} {
+		if score < 0 {
+			return false
+		}
+		if score > models.MaxAssessmentScore {
+			legacy = true
+		}
+	}
+	return legacy
 }
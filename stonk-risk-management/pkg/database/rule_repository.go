@@ -0,0 +1,52 @@
+package database
+
+import (
+	"fmt"
+
+	"stonk-risk-management/pkg/models"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+const rulePrefix = "rule:"
+
+// RuleRepository handles database operations for warning rules
+type RuleRepository struct {
+	*Repository[models.Rule]
+}
+
+// NewRuleRepository creates a new rule repository
+func NewRuleRepository(db *DB) *RuleRepository {
+	return &RuleRepository{NewRepository(db, RepositoryConfig[models.Rule]{
+		Prefix:   rulePrefix,
+		Key:      func(r *models.Rule) string { return r.ID },
+		Prepare:  func(r *models.Rule) { ensureID(&r.ID) },
+		Validate: (*models.Rule).Validate,
+		Less:     func(a, b *models.Rule) bool { return a.Name < b.Name },
+	})}
+}
+
+// seedDefaultRules saves the default warning rules passed in MigrationOptions that
+// are not stored yet
+func seedDefaultRules(tx *Tx) (int, error) {
+	if len(tx.rules) == 0 {
+		return 0, fmt.Errorf("no default rules given to seed")
+	}
+
+	seeded := 0
+	for _, rule := range tx.rules {
+		key := rulePrefix + rule.ID
+		_, err := tx.raw(key)
+		if err == nil {
+			continue
+		}
+		if err != badger.ErrKeyNotFound {
+			return seeded, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if err := tx.Put(key, rule); err != nil {
+			return seeded, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		seeded++
+	}
+	return seeded, nil
+}
@@ -3,126 +3,197 @@ package database
 import (
 	"encoding/json"
 	"fmt"
-	"sort"
 	"time"
 
 	"stonk-risk-management/pkg/models"
-
-	"github.com/google/uuid"
 )
 
 const stockPrefix = "stock:"
 
 // StockRepository handles database operations for stock ratings
 type StockRepository struct {
-	db *DB
+	*Repository[models.StockRating]
 }
 
 // NewStockRepository creates a new stock repository
 func NewStockRepository(db *DB) *StockRepository {
-	return &StockRepository{db: db}
-}
-
-// Save saves a stock rating to the database
-func (r *StockRepository) Save(rating *models.StockRating) error {
-	if rating.ID == "" {
-		rating.ID = uuid.New().String()
-	}
-	key := fmt.Sprintf("%s%s", stockPrefix, rating.ID)
-	return r.db.Put(key, rating)
-}
-
-// Get retrieves a stock rating by ID
-func (r *StockRepository) Get(id string) (*models.StockRating, error) {
-	key := fmt.Sprintf("%s%s", stockPrefix, id)
-	rating := &models.StockRating{}
-	err := r.db.Get(key, rating)
-	if err != nil {
-		return nil, err
-	}
-	return rating, nil
-}
-
-// Delete removes a stock rating from the database
-func (r *StockRepository) Delete(id string) error {
-	key := fmt.Sprintf("%s%s", stockPrefix, id)
-	return r.db.Delete(key)
+	return &StockRepository{NewRepository(db, RepositoryConfig[models.StockRating]{
+		Prefix:  stockPrefix,
+		Key:     func(r *models.StockRating) string { return r.ID },
+		Prepare: func(r *models.StockRating) { ensureID(&r.ID) },
+		// Sort by date
+		Less:    func(a, b *models.StockRating) bool { return a.Date.Before(b.Date) },
+		Indexes: &stockIndexes,
+	})}
 }
 
-// GetAll retrieves all stock ratings
-func (r *StockRepository) GetAll() ([]*models.StockRating, error) {
-	values, err := r.db.GetAllWithPrefix(stockPrefix)
-	if err != nil {
-		return nil, err
-	}
+// Stock rating index fields
+const (
+	stockIndexName         = "stock"
+	stockBySymbol          = "symbol"        // idx:stock:symbol:<symbol>:<time>:<id>
+	stockBySector          = "sector"        // idx:stock:sector:<sector>:<time>:<id>
+	stockBySymbolAndSector = "symbol_sector" // idx:stock:symbol_sector:<symbol>:<sector>:<time>:<id>
+	stockByDate            = "date"          // idx:stock:date:<YYYY-MM-DD>:<time>:<id>
+	stockByTime            = "time"          // idx:stock:time:<time>:<id>
+)
 
-	ratings := make([]*models.StockRating, 0, len(values))
-	for _, v := range values {
+// stockIndexes indexes stock ratings by symbol, sector and day, each ordered by time
+var stockIndexes = indexSet{
+	name:   stockIndexName,
+	prefix: stockPrefix,
+	keys: func(data []byte) ([]string, error) {
 		rating := &models.StockRating{}
-		if err := json.Unmarshal(v, rating); err != nil {
+		if err := json.Unmarshal(data, rating); err != nil {
 			return nil, err
 		}
-		ratings = append(ratings, rating)
-	}
 
-	// Sort by date
-	sort.Slice(ratings, func(i, j int) bool {
-		return ratings[i].Date.Before(ratings[j].Date)
-	})
+		at := indexTime(rating.Date)
+		return []string{
+			indexKey(stockIndexName, stockBySymbol, rating.Symbol, at, rating.ID),
+			indexKey(stockIndexName, stockBySector, rating.Sector, at, rating.ID),
+			indexKey(stockIndexName, stockBySymbolAndSector, rating.Symbol, rating.Sector, at, rating.ID),
+			indexKey(stockIndexName, stockByDate, rating.Date.Format("2006-01-02"), at, rating.ID),
+			indexKey(stockIndexName, stockByTime, at, rating.ID),
+		}, nil
+	},
+}
 
-	return ratings, nil
+// SaveAll saves several stock ratings in one transaction; either all are saved or none are
+func (r *StockRepository) SaveAll(ratings []*models.StockRating) error {
+	return r.db.Batch(func(tx *Tx) error {
+		for _, rating := range ratings {
+			if err := r.SaveTx(tx, rating); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// GetByDate retrieves stock ratings for a specific date
-func (r *StockRepository) GetByDate(date time.Time) ([]*models.StockRating, error) {
-	all, err := r.GetAll()
-	if err != nil {
-		return nil, err
+// ReplaceForDate saves ratings for a day in one transaction, first deleting the
+// ratings already recorded that day for the same symbol and sector. Ratings
+// without a date are given the day's date.
+func (r *StockRepository) ReplaceForDate(date time.Time, ratings []*models.StockRating) error {
+	day := date.Format("2006-01-02")
+	for _, rating := range ratings {
+		if rating.Date.IsZero() {
+			rating.Date = date
+		}
+		if rating.Date.Format("2006-01-02") != day {
+			return fmt.Errorf("rating for %s %s is dated %s, not %s", rating.Symbol, rating.Sector, rating.Date.Format("2006-01-02"), day)
+		}
 	}
 
-	// Format date to compare only year, month, day
-	targetDate := date.Format("2006-01-02")
+	replaced := make(map[string]bool, len(ratings))
+	for _, rating := range ratings {
+		replaced[rating.Symbol+"\x00"+rating.Sector] = true
+	}
 
-	var filtered []*models.StockRating
-	for _, a := range all {
-		if a.Date.Format("2006-01-02") == targetDate {
-			filtered = append(filtered, a)
+	return r.db.Batch(func(tx *Tx) error {
+		keys, err := tx.indexedKeys(indexValuePrefix(stockIndexName, stockByDate, day))
+		if err != nil {
+			return err
 		}
-	}
 
-	return filtered, nil
+		for _, key := range keys {
+			// Records that are missing or cannot be decoded are left alone
+			existing := &models.StockRating{}
+			if err := tx.Get(key, existing); err != nil {
+				continue
+			}
+			if !replaced[existing.Symbol+"\x00"+existing.Sector] {
+				continue
+			}
+			if err := tx.deleteIndexed(key, stockIndexes); err != nil {
+				return err
+			}
+		}
+
+		for _, rating := range ratings {
+			// The replacement is a new record even if the caller passed an old ID
+			rating.ID = ""
+			if err := r.SaveTx(tx, rating); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// GetBySector retrieves stock ratings for a specific sector
-func (r *StockRepository) GetBySector(sector string) ([]*models.StockRating, error) {
-	all, err := r.GetAll()
+// Query returns a page of stock ratings, sorted by "date" (default), "symbol" or "sector"
+func (r *StockRepository) Query(q Query) (*StockRatingPage, []string, error) {
+	var rng IndexRange
+	timeOrdered := true
+
+	switch {
+	case q.SortBy != "" && q.SortBy != "date" && q.SortBy != "symbol" && q.SortBy != "sector":
+		return nil, nil, fmt.Errorf("cannot sort stock ratings by %q", q.SortBy)
+	case q.Symbol != "" && q.SortBy != "sector":
+		// One symbol's ratings are ordered by date
+		rng.Prefix = indexValuePrefix(stockIndexName, stockBySymbol, q.Symbol)
+	case q.Sector != "" && q.SortBy != "symbol":
+		rng.Prefix = indexValuePrefix(stockIndexName, stockBySector, q.Sector)
+	case q.SortBy == "symbol":
+		rng.Prefix = indexValuePrefix(stockIndexName, stockBySymbol)
+		timeOrdered = false
+	case q.SortBy == "sector":
+		rng.Prefix = indexValuePrefix(stockIndexName, stockBySector)
+		timeOrdered = false
+	default:
+		rng.Prefix = indexValuePrefix(stockIndexName, stockByTime)
+	}
+
+	rng, err := q.plan(rng, timeOrdered)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var filtered []*models.StockRating
-	for _, a := range all {
-		if a.Sector == sector {
-			filtered = append(filtered, a)
-		}
+	items, next, badKeys, err := queryPage(r.Repository, rng, q.limit(), func(s *models.StockRating) bool {
+		return q.inRange(s.Date) &&
+			(q.Symbol == "" || s.Symbol == q.Symbol) &&
+			(q.Sector == "" || s.Sector == q.Sector)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return filtered, nil
+	return &StockRatingPage{Items: items, NextCursor: next}, badKeys, nil
 }
 
-// GetBySymbol retrieves stock ratings for a specific symbol
-func (r *StockRepository) GetBySymbol(symbol string) ([]*models.StockRating, error) {
-	all, err := r.GetAll()
-	if err != nil {
-		return nil, err
-	}
+// GetByDate retrieves stock ratings for a specific date, oldest first
+func (r *StockRepository) GetByDate(date time.Time) ([]*models.StockRating, []string, error) {
+	return r.GetIndexed(IndexRange{
+		Prefix: indexValuePrefix(stockIndexName, stockByDate, date.Format("2006-01-02")),
+	})
+}
 
-	var filtered []*models.StockRating
-	for _, a := range all {
-		if a.Symbol == symbol {
-			filtered = append(filtered, a)
-		}
-	}
+// GetBySector retrieves stock ratings for a specific sector, oldest first
+func (r *StockRepository) GetBySector(sector string) ([]*models.StockRating, []string, error) {
+	return r.GetIndexed(IndexRange{
+		Prefix: indexValuePrefix(stockIndexName, stockBySector, sector),
+	})
+}
 
-	return filtered, nil
+// GetBySymbol retrieves stock ratings for a specific symbol, oldest first
+func (r *StockRepository) GetBySymbol(symbol string) ([]*models.StockRating, []string, error) {
+	return r.GetIndexed(IndexRange{
+		Prefix: indexValuePrefix(stockIndexName, stockBySymbol, symbol),
+	})
+}
+
+// GetLatestBySymbol retrieves the most recent rating for a symbol, or nil if there is none
+func (r *StockRepository) GetLatestBySymbol(symbol string) (*models.StockRating, []string, error) {
+	return r.FirstIndexed(IndexRange{
+		Prefix:  indexValuePrefix(stockIndexName, stockBySymbol, symbol),
+		Reverse: true,
+	})
+}
+
+// GetLatestBySymbolAndSector retrieves the most recent rating for a symbol within a
+// sector, or nil if there is none. Sector ratings use the symbol "SECTOR".
+func (r *StockRepository) GetLatestBySymbolAndSector(symbol, sector string) (*models.StockRating, []string, error) {
+	return r.FirstIndexed(IndexRange{
+		Prefix:  indexValuePrefix(stockIndexName, stockBySymbolAndSector, symbol, sector),
+		Reverse: true,
+	})
 }
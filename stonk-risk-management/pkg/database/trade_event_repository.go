@@ -0,0 +1,62 @@
+package database
+
+import (
+	"fmt"
+
+	"stonk-risk-management/pkg/models"
+)
+
+const tradeEventPrefix = "event:"
+
+// TradeEventRepository handles database operations for trade lifecycle events
+type TradeEventRepository struct {
+	*Repository[models.TradeEvent]
+}
+
+// NewTradeEventRepository creates a new trade event repository
+func NewTradeEventRepository(db *DB) *TradeEventRepository {
+	return &TradeEventRepository{NewRepository(db, RepositoryConfig[models.TradeEvent]{
+		Prefix:  tradeEventPrefix,
+		Key:     func(e *models.TradeEvent) string { return eventKey(e.TradeID, e.ID) },
+		Prepare: func(e *models.TradeEvent) { ensureID(&e.ID) },
+		Validate: func(e *models.TradeEvent) error {
+			if e.TradeID == "" {
+				return fmt.Errorf("trade event must reference a trade")
+			}
+			return nil
+		},
+		// Oldest first
+		Less: func(a, b *models.TradeEvent) bool { return a.Date.Before(b.Date) },
+	})}
+}
+
+// eventKey builds the key for an event after the prefix
+// Format: event:<tradeID>:<eventID>
+func eventKey(tradeID, eventID string) string {
+	return fmt.Sprintf("%s:%s", tradeID, eventID)
+}
+
+// GetByTrade retrieves all events for a trade, oldest first
+func (r *TradeEventRepository) GetByTrade(tradeID string) ([]*models.TradeEvent, []string, error) {
+	return r.GetWithPrefix(eventKey(tradeID, ""))
+}
+
+// GetAllByTrade retrieves all trade events grouped by trade ID
+func (r *TradeEventRepository) GetAllByTrade() (map[string][]*models.TradeEvent, []string, error) {
+	all, badKeys, err := r.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(map[string][]*models.TradeEvent)
+	for _, event := range all {
+		events[event.TradeID] = append(events[event.TradeID], event)
+	}
+
+	return events, badKeys, nil
+}
+
+// DeleteByTrade removes all events for a trade
+func (r *TradeEventRepository) DeleteByTrade(tradeID string) error {
+	return r.DeleteWithPrefix(eventKey(tradeID, ""))
+}
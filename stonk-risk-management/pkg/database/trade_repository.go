@@ -3,68 +3,191 @@ package database
 import (
 	"encoding/json"
 	"fmt"
-	"sort"
 
 	"stonk-risk-management/pkg/models"
-
-	"github.com/google/uuid"
+	"stonk-risk-management/pkg/occ"
 )
 
 const tradePrefix = "trade:"
 
 // TradeRepository handles database operations for trades
 type TradeRepository struct {
-	db *DB
+	*Repository[models.Trade]
 }
 
-// NewTradeRepository creates a new trade repository
+// NewTradeRepository creates a new trade repository.
+// Saving a trade with an existing ID updates the existing record.
 func NewTradeRepository(db *DB) *TradeRepository {
-	return &TradeRepository{db: db}
+	return &TradeRepository{NewRepository(db, RepositoryConfig[models.Trade]{
+		// Format: trade:<ID>
+		Prefix:   tradePrefix,
+		Key:      func(t *models.Trade) string { return t.ID },
+		Prepare:  prepareTrade,
+		Validate: validateTrade,
+		// Sort by entry date (most recent first)
+		Less:    func(a, b *models.Trade) bool { return a.EntryDate.After(b.EntryDate) },
+		Indexes: &tradeIndexes,
+	})}
+}
+
+// Trade index fields
+const (
+	tradeIndexName    = "trade"
+	tradeByEntry      = "entry"      // idx:trade:entry:<entry time>:<id>
+	tradeByExpiration = "expiration" // idx:trade:expiration:<expiration time>:<id>
+	tradeBySymbol     = "symbol"     // idx:trade:symbol:<symbol>:<entry time>:<id>
+)
+
+// tradeIndexes indexes trades by entry date, expiration date and symbol
+var tradeIndexes = indexSet{
+	name:   tradeIndexName,
+	prefix: tradePrefix,
+	keys: func(data []byte) ([]string, error) {
+		trade := &models.Trade{}
+		if err := json.Unmarshal(data, trade); err != nil {
+			return nil, err
+		}
+
+		entry := indexTime(trade.EntryDate)
+		return []string{
+			indexKey(tradeIndexName, tradeByEntry, entry, trade.ID),
+			indexKey(tradeIndexName, tradeByExpiration, indexTime(trade.ExpirationDate), trade.ID),
+			indexKey(tradeIndexName, tradeBySymbol, trade.Symbol, entry, trade.ID),
+		}, nil
+	},
 }
 
-// Save saves a trade to the database
-// If the trade ID already exists, it updates the existing record.
-func (r *TradeRepository) Save(trade *models.Trade) error {
-	// Use the existing ID if provided (for updates), otherwise generate a new one.
-	if trade.ID == "" {
-		trade.ID = uuid.New().String()
+// Query returns a page of trades. The date range applies to the entry date;
+// trades sort by "entryDate" (default), "expirationDate" or "symbol".
+func (r *TradeRepository) Query(q Query) (*TradePage, []string, error) {
+	var rng IndexRange
+	timeOrdered := true
+
+	switch q.SortBy {
+	case "", "entryDate", "date", "symbol":
+		switch {
+		case q.Symbol != "":
+			// One symbol's trades are ordered by entry date
+			rng.Prefix = indexValuePrefix(tradeIndexName, tradeBySymbol, q.Symbol)
+		case q.SortBy == "symbol":
+			rng.Prefix = indexValuePrefix(tradeIndexName, tradeBySymbol)
+			timeOrdered = false
+		default:
+			rng.Prefix = indexValuePrefix(tradeIndexName, tradeByEntry)
+		}
+	case "expirationDate":
+		rng.Prefix = indexValuePrefix(tradeIndexName, tradeByExpiration)
+		timeOrdered = false
+	default:
+		return nil, nil, fmt.Errorf("cannot sort trades by %q", q.SortBy)
 	}
 
-	// Create a unique key for this trade
-	// Format: trade:<ID>
-	key := fmt.Sprintf("%s%s", tradePrefix, trade.ID)
+	rng, err := q.plan(rng, timeOrdered)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return r.db.Put(key, trade)
+	items, next, badKeys, err := queryPage(r.Repository, rng, q.limit(), func(t *models.Trade) bool {
+		return q.inRange(t.EntryDate) &&
+			(q.Symbol == "" || t.Symbol == q.Symbol) &&
+			(q.Sector == "" || t.Sector == q.Sector) &&
+			(q.Strategy == "" || t.Strategy == q.Strategy || t.Type == q.Strategy)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &TradePage{Items: items, NextCursor: next}, badKeys, nil
 }
 
-// Delete removes a trade from the database
-func (r *TradeRepository) Delete(id string) error {
-	key := fmt.Sprintf("%s%s", tradePrefix, id)
-	return r.db.Delete(key)
+// prepareTrade assigns an ID and gives trades entered without leg detail a single summary leg
+func prepareTrade(trade *models.Trade) {
+	ensureID(&trade.ID)
+
+	if len(trade.Legs) == 0 {
+		trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	}
+	if len(trade.Legs) > 1 {
+		trade.IsMultiLeg = true
+	}
+	occ.AssignSymbols(trade)
 }
 
-// GetAll retrieves all trades from the database
-func (r *TradeRepository) GetAll() ([]*models.Trade, error) {
-	values, err := r.db.GetAllWithPrefix(tradePrefix)
+// validateTrade checks the legs of a trade against its strategy
+func validateTrade(trade *models.Trade) error {
+	if err := trade.ValidateLegs(); err != nil {
+		return fmt.Errorf("invalid trade legs: %w", err)
+	}
+	return nil
+}
+
+// migrateTradeLegs converts trades stored as a single row without legs into
+// one-leg trades carrying the trade's expiration and entry price
+func migrateTradeLegs(tx *Tx) (int, error) {
+	keys, err := tx.KeysWithPrefix(tradePrefix)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	trades := make([]*models.Trade, 0, len(values))
-	for _, v := range values {
+	migrated := 0
+	for _, key := range keys {
 		trade := &models.Trade{}
-		if err := json.Unmarshal(v, trade); err != nil {
-			// Log the problematic entry but try to continue
-			fmt.Printf("Error unmarshalling trade data: %v\nData: %s\n", err, string(v))
+		if err := tx.Get(key, trade); err != nil {
+			// Unreadable trades are skipped here just like in GetAll
+			tx.skip(key)
+			continue
+		}
+
+		if len(trade.Legs) > 0 {
 			continue
 		}
-		trades = append(trades, trade)
+
+		trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+		trade.LegNumber = 1
+
+		if err := tx.Put(key, trade); err != nil {
+			return migrated, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		migrated++
 	}
 
-	// Sort by entry date (most recent first)
-	sort.Slice(trades, func(i, j int) bool {
-		return trades[i].EntryDate.After(trades[j].EntryDate)
-	})
+	return migrated, nil
+}
+
+// migrateLegSymbols sets the OCC symbol of every option leg of stored trades
+func migrateLegSymbols(tx *Tx) (int, error) {
+	keys, err := tx.KeysWithPrefix(tradePrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, key := range keys {
+		trade := &models.Trade{}
+		if err := tx.Get(key, trade); err != nil {
+			tx.skip(key)
+			continue
+		}
+
+		before := make([]string, len(trade.Legs))
+		for i, leg := range trade.Legs {
+			before[i] = leg.Symbol
+		}
+		occ.AssignSymbols(trade)
+
+		changed := false
+		for i, leg := range trade.Legs {
+			changed = changed || leg.Symbol != before[i]
+		}
+		if !changed {
+			continue
+		}
+
+		if err := tx.Put(key, trade); err != nil {
+			return migrated, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		migrated++
+	}
 
-	return trades, nil
+	return migrated, nil
 }
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"encoding/json"
+
+	"stonk-risk-management/pkg/models"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// Tx wraps a badger transaction with the same JSON helpers as DB
+type Tx struct {
+	txn     *badger.Txn
+	badKeys []string       // Records a migration could not read and left untouched
+	rules   []*models.Rule // Default warning rules, for the migration that seeds them
+}
+
+// skip records a key whose value could not be decoded
+func (t *Tx) skip(key string) {
+	t.badKeys = append(t.badKeys, key)
+}
+
+// Put stores a value in the transaction
+func (t *Tx) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return t.set(key, data)
+}
+
+// set stores raw bytes in the transaction
+func (t *Tx) set(key string, data []byte) error {
+	return t.txn.Set([]byte(key), data)
+}
+
+// Get retrieves a value visible to the transaction
+func (t *Tx) Get(key string, value interface{}) error {
+	data, err := t.raw(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+// raw retrieves the stored bytes of a key
+func (t *Tx) raw(key string) ([]byte, error) {
+	item, err := t.txn.Get([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// Delete removes a key in the transaction
+func (t *Tx) Delete(key string) error {
+	return t.txn.Delete([]byte(key))
+}
+
+// KeysWithPrefix retrieves all keys matching a given prefix
+func (t *Tx) KeysWithPrefix(prefix string) ([]string, error) {
+	var keys []string
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false // We only need keys
+	it := t.txn.NewIterator(opts)
+	defer it.Close()
+
+	prefixBytes := []byte(prefix)
+	for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
+		keys = append(keys, string(it.Item().KeyCopy(nil)))
+	}
+
+	return keys, nil
+}
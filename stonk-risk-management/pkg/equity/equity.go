@@ -0,0 +1,155 @@
+package equity
+
+import (
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Drawdown describes how far the equity curve is below its running peak
+type Drawdown struct {
+	Peak              float64    `json:"peak"`              // Highest equity so far
+	PeakDate          time.Time  `json:"peakDate"`          // Day the peak was set
+	Equity            float64    `json:"equity"`            // Latest equity
+	Current           float64    `json:"current"`           // Dollars below the peak
+	CurrentPercent    float64    `json:"currentPercent"`    // Percent below the peak
+	Max               float64    `json:"max"`               // Largest drop from a peak in dollars
+	MaxPercent        float64    `json:"maxPercent"`        // Largest drop from a peak in percent
+	MaxDate           time.Time  `json:"maxDate"`           // Day of the deepest point
+	UnderWaterSince   *time.Time `json:"underWaterSince"`   // Day of the peak the curve has not recovered to, nil at a peak
+	DaysUnderWater    int        `json:"daysUnderWater"`    // Days since UnderWaterSince
+	MaxDaysUnderWater int        `json:"maxDaysUnderWater"` // Longest stretch below a peak
+	TolerancePercent  float64    `json:"tolerancePercent"`  // MaxDrawdownTolerance from the settings
+	Breached          bool       `json:"breached"`          // CurrentPercent exceeds the tolerance
+}
+
+// Curve is the equity history together with its drawdown statistics
+type Curve struct {
+	Snapshots []*models.EquitySnapshot `json:"snapshots"`
+	Drawdown  Drawdown                 `json:"drawdown"`
+}
+
+// Snapshot computes the equity at the end of a day from the deposits and
+// withdrawals and the P&L realized by the trade events up to that day
+func Snapshot(histories []*models.TradeHistory, flows []*models.CashFlow, day time.Time) *models.EquitySnapshot {
+	year, month, d := day.Date()
+	start := time.Date(year, month, d, 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	realized := 0.0
+	for _, h := range histories {
+		realized += h.RealizedBetween(time.Time{}, end)
+	}
+
+	// Cash flow dates are calendar days at midnight UTC
+	flowEnd := time.Date(year, month, d+1, 0, 0, 0, 0, time.UTC)
+	deposits := 0.0
+	for _, f := range flows {
+		if f.Date.Before(flowEnd) {
+			deposits += f.Amount
+		}
+	}
+
+	return &models.EquitySnapshot{
+		Date:          start,
+		StartingValue: deposits,
+		RealizedPL:    realized,
+		Equity:        deposits + realized,
+	}
+}
+
+// Flows returns the recorded cash flows, or the account value from the settings
+// as a single opening deposit while none have been recorded
+func Flows(recorded []*models.CashFlow, accountValue float64) []*models.CashFlow {
+	if len(recorded) > 0 {
+		return recorded
+	}
+	return []*models.CashFlow{{Amount: accountValue, Notes: "Account value"}}
+}
+
+// Analyze computes the running peak, drawdowns and time under water of the
+// snapshots, which must be sorted oldest first. A change in StartingValue between
+// snapshots is a deposit or withdrawal and moves the peak with it, so only trading
+// losses count as a drawdown.
+func Analyze(snapshots []*models.EquitySnapshot, tolerancePercent float64) Curve {
+	curve := Curve{
+		Snapshots: snapshots,
+		Drawdown:  Drawdown{TolerancePercent: tolerancePercent},
+	}
+	if len(snapshots) == 0 {
+		return curve
+	}
+
+	dd := &curve.Drawdown
+	for i, s := range snapshots {
+		if i > 0 {
+			dd.Peak += s.StartingValue - snapshots[i-1].StartingValue
+		}
+		if i == 0 || s.Equity >= dd.Peak {
+			dd.Peak = s.Equity
+			dd.PeakDate = s.Date
+			dd.UnderWaterSince = nil
+			continue
+		}
+
+		if dd.UnderWaterSince == nil {
+			since := dd.PeakDate
+			dd.UnderWaterSince = &since
+		}
+		if days := daysBetween(*dd.UnderWaterSince, s.Date); days > dd.MaxDaysUnderWater {
+			dd.MaxDaysUnderWater = days
+		}
+
+		drop := dd.Peak - s.Equity
+		if drop > dd.Max {
+			dd.Max = drop
+			dd.MaxPercent = percentOf(drop, dd.Peak)
+			dd.MaxDate = s.Date
+		}
+	}
+
+	last := snapshots[len(snapshots)-1]
+	dd.Equity = last.Equity
+	dd.Current = dd.Peak - last.Equity
+	dd.CurrentPercent = percentOf(dd.Current, dd.Peak)
+	if dd.UnderWaterSince != nil {
+		dd.DaysUnderWater = daysBetween(*dd.UnderWaterSince, last.Date)
+	}
+	dd.Breached = tolerancePercent > 0 && dd.CurrentPercent > tolerancePercent
+
+	return curve
+}
+
+// WithSnapshot returns the snapshots with s added, replacing a snapshot for the same day
+func WithSnapshot(snapshots []*models.EquitySnapshot, s *models.EquitySnapshot) []*models.EquitySnapshot {
+	result := make([]*models.EquitySnapshot, 0, len(snapshots)+1)
+	for _, existing := range snapshots {
+		if existing.Date.Format("2006-01-02") != s.Date.Format("2006-01-02") {
+			result = append(result, existing)
+		}
+	}
+
+	// Keep the result sorted oldest first
+	i := len(result)
+	for i > 0 && result[i-1].Date.After(s.Date) {
+		i--
+	}
+	result = append(result, nil)
+	copy(result[i+1:], result[i:])
+	result[i] = s
+
+	return result
+}
+
+// percentOf returns part as a percent of whole
+func percentOf(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return part / whole * 100
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours()/24 + 0.5)
+}
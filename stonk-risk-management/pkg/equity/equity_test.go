@@ -0,0 +1,110 @@
+package equity
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// closedTrade is a long call bought at 5 and sold at exit on the given date
+func closedTrade(id string, exit float64, date time.Time) *models.TradeHistory {
+	trade := &models.Trade{ID: id, Type: "Long Call", EntryPrice: 5, EntryDate: date}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return models.NewTradeHistory(trade, []*models.TradeEvent{
+		{ID: id + "-close", TradeID: id, Type: models.EventClose, Date: date, Price: exit, Quantity: 1},
+	})
+}
+
+func TestSnapshotCountsCashFlowsThroughTheDay(t *testing.T) {
+	histories := []*models.TradeHistory{closedTrade("t1", 8, day("2026-10-05"))} // +$300
+	flows := []*models.CashFlow{
+		{Date: day("2026-10-01"), Amount: 10000},
+		{Date: day("2026-10-07"), Amount: -2000},
+	}
+
+	tests := []struct {
+		date                   string
+		deposits, realized, eq float64
+	}{
+		{"2026-10-01", 10000, 0, 10000},
+		{"2026-10-05", 10000, 300, 10300},
+		{"2026-10-07", 8000, 300, 8300},
+	}
+	for _, tt := range tests {
+		// Evening in a zone west of UTC is still the same calendar day
+		asOf := day(tt.date).Add(20 * time.Hour).In(time.FixedZone("New York", -4*3600))
+		s := Snapshot(histories, flows, asOf)
+		if s.StartingValue != tt.deposits || s.RealizedPL != tt.realized || s.Equity != tt.eq {
+			t.Errorf("%s: got %v + %v = %v, want %v + %v = %v", tt.date,
+				s.StartingValue, s.RealizedPL, s.Equity, tt.deposits, tt.realized, tt.eq)
+		}
+	}
+}
+
+// Once cash flows are recorded, the account value in the settings no longer adds
+// to the equity, so setting it to a balance that includes the P&L does not count
+// the P&L twice
+func TestFlowsIgnoreAccountValueOnceRecorded(t *testing.T) {
+	recorded := []*models.CashFlow{{Date: day("2026-10-01"), Amount: 10000}}
+	if got := Flows(recorded, 10300); len(got) != 1 || got[0].Amount != 10000 {
+		t.Errorf("Flows(recorded) = %+v, want the recorded deposit", got)
+	}
+	if got := Flows(nil, 25000); len(got) != 1 || got[0].Amount != 25000 {
+		t.Errorf("Flows(nil) = %+v, want the account value as the opening deposit", got)
+	}
+}
+
+func TestAnalyzeIgnoresCashFlows(t *testing.T) {
+	snapshot := func(date string, deposits, realized float64) *models.EquitySnapshot {
+		return &models.EquitySnapshot{Date: day(date), StartingValue: deposits, RealizedPL: realized, Equity: deposits + realized}
+	}
+
+	t.Run("withdrawal", func(t *testing.T) {
+		curve := Analyze([]*models.EquitySnapshot{
+			snapshot("2026-10-01", 10000, 0),
+			snapshot("2026-10-02", 10000, 500),
+			snapshot("2026-10-03", 5000, 500), // Half the account withdrawn
+		}, 15)
+		dd := curve.Drawdown
+		if dd.Current != 0 || dd.Max != 0 || dd.Breached || dd.UnderWaterSince != nil {
+			t.Errorf("drawdown = %+v, want none after a withdrawal", dd)
+		}
+		if dd.Peak != 5500 {
+			t.Errorf("peak = %v, want 5500", dd.Peak)
+		}
+	})
+
+	t.Run("loss after a withdrawal", func(t *testing.T) {
+		curve := Analyze([]*models.EquitySnapshot{
+			snapshot("2026-10-01", 10000, 500),
+			snapshot("2026-10-02", 5000, 500),
+			snapshot("2026-10-03", 5000, -500), // $1,000 lost from a $5,500 peak
+		}, 15)
+		dd := curve.Drawdown
+		if dd.Current != 1000 || math.Abs(dd.CurrentPercent-1000.0/5500*100) > 1e-9 || !dd.Breached {
+			t.Errorf("drawdown = %+v, want $1,000 (18.2%%) breached", dd)
+		}
+	})
+
+	t.Run("deposit", func(t *testing.T) {
+		curve := Analyze([]*models.EquitySnapshot{
+			snapshot("2026-10-01", 10000, 0),
+			snapshot("2026-10-02", 10000, -1000),
+			snapshot("2026-10-03", 20000, -1000), // A deposit is not a recovery
+		}, 15)
+		dd := curve.Drawdown
+		if dd.Current != 1000 || dd.Peak != 20000 || dd.UnderWaterSince == nil {
+			t.Errorf("drawdown = %+v, want still $1,000 under a $20,000 peak", dd)
+		}
+	})
+}
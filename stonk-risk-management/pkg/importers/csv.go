@@ -0,0 +1,184 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// table is a CSV file whose rows are read by header name
+type table struct {
+	columns map[string]int
+	rows    [][]string
+	lines   []int
+}
+
+// readTable reads a CSV file, skipping any lines above the first row isHeader
+// accepts. Blank rows are dropped.
+func readTable(r io.Reader, isHeader func(header []string) bool) (*table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	t := &table{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if t.columns == nil {
+			if isHeader(record) {
+				t.columns = map[string]int{}
+				for i, h := range record {
+					t.columns[columnKey(h)] = i
+				}
+			}
+			continue
+		}
+
+		if blankRow(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		t.rows = append(t.rows, record)
+		t.lines = append(t.lines, line)
+	}
+
+	if t.columns == nil {
+		return nil, fmt.Errorf("header row not found")
+	}
+	return t, nil
+}
+
+// rowFills converts every row of a table into a fill. The converter returns a
+// reason for rows that are not fills and an error for rows it cannot read;
+// both are reported as skipped rows.
+func rowFills(t *table, convert func(t *table, row []string) (Fill, string, error)) ([]Fill, []Skipped) {
+	var fills []Fill
+	var skipped []Skipped
+	for i, row := range t.rows {
+		line := t.lines[i]
+		fill, reason, err := convert(t, row)
+		switch {
+		case err != nil:
+			skipped = append(skipped, Skipped{Row: line, Reason: err.Error()})
+		case reason != "":
+			skipped = append(skipped, Skipped{Row: line, Reason: reason})
+		default:
+			fill.Row = line
+			fills = append(fills, fill)
+		}
+	}
+	return fills, skipped
+}
+
+// get returns the trimmed value of a column in a row, or "" if the column is absent
+func (t *table) get(row []string, name string) string {
+	i, ok := t.columns[columnKey(name)]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// hasColumns reports whether a header contains every named column
+func hasColumns(header []string, names ...string) bool {
+	present := map[string]bool{}
+	for _, h := range header {
+		present[columnKey(h)] = true
+	}
+	for _, name := range names {
+		if !present[columnKey(name)] {
+			return false
+		}
+	}
+	return true
+}
+
+// columnKey lowercases a header and drops everything but letters and digits
+func columnKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// blankRow reports whether every value of a row is empty
+func blankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAmount parses a broker amount such as "$1,250.00", "-0.65" or "(12.50)".
+// Empty values are zero.
+func parseAmount(value string) (float64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		f = -f
+	}
+	return f, nil
+}
+
+// parseQuantity parses a whole number of contracts or shares, returning its
+// absolute value and whether it was negative
+func parseQuantity(value string) (int, bool, error) {
+	f, err := parseAmount(value)
+	if err != nil {
+		return 0, false, err
+	}
+	if f == 0 || f != float64(int(f)) {
+		return 0, false, fmt.Errorf("invalid quantity %q", value)
+	}
+	if f < 0 {
+		return int(-f), true, nil
+	}
+	return int(f), false, nil
+}
+
+// parseTime parses a value with the first layout that accepts it
+func parseTime(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// day truncates a time to its calendar date
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,520 @@
+package importers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+
+	"github.com/google/uuid"
+)
+
+// contract identifies an option series (or the shares) of an underlying
+type contract struct {
+	right      string
+	strike     float64
+	expiration string
+}
+
+func (c contract) String() string {
+	if c.right == models.RightStock {
+		return "shares"
+	}
+	return fmt.Sprintf("%s %g %s", c.expiration, c.strike, c.right)
+}
+
+// contractOf returns the contract of a fill
+func contractOf(f Fill) contract {
+	if f.Right == models.RightStock {
+		return contract{right: models.RightStock}
+	}
+	return contract{right: f.Right, strike: f.Strike, expiration: f.Expiration.Format("2006-01-02")}
+}
+
+// contractOfLeg returns the contract of a trade leg
+func contractOfLeg(l models.Leg) contract {
+	if l.Right == models.RightStock {
+		return contract{right: models.RightStock}
+	}
+	return contract{right: l.Right, strike: l.Strike, expiration: l.Expiration.Format("2006-01-02")}
+}
+
+// order is the fills of one broker order with a single position effect
+type order struct {
+	key   string
+	fills []Fill
+}
+
+// openTrade is a trade built from an opening order and what is still open of it
+type openTrade struct {
+	trade *models.Trade
+	units int              // Units still open
+	held  map[contract]int // Contracts (shares for stock) still open in each leg
+}
+
+// newOpenTrade tracks a trade with all of its legs open
+func newOpenTrade(trade *models.Trade) *openTrade {
+	held := map[contract]int{}
+	for _, leg := range trade.Legs {
+		held[contractOfLeg(leg)] += leg.Quantity
+	}
+	return &openTrade{trade: trade, units: trade.Quantity(), held: held}
+}
+
+// contracts returns the contracts of the trade's legs, in leg order
+func (ot *openTrade) contracts() []contract {
+	var contracts []contract
+	seen := map[contract]bool{}
+	for _, leg := range ot.trade.Legs {
+		if c := contractOfLeg(leg); !seen[c] {
+			seen[c] = true
+			contracts = append(contracts, c)
+		}
+	}
+	return contracts
+}
+
+// ratio returns the contracts (shares for stock) of c in one unit of the trade
+func (ot *openTrade) ratio(c contract) int {
+	total := 0
+	for _, leg := range ot.trade.Legs {
+		if contractOfLeg(leg) == c {
+			total += leg.Quantity
+		}
+	}
+	return total / ot.trade.Quantity()
+}
+
+// flat reports whether every leg of the trade has been closed
+func (ot *openTrade) flat() bool {
+	for _, n := range ot.held {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// delivery adds the shares that assigned or exercised options of the trade deliver
+// at their strike to the contracts and prices of an assignment order. Only shares
+// the trade holds are delivered from it; shares delivered for an option without
+// them, such as an assigned cash-secured put, are not tracked.
+func (ot *openTrade) delivery(remaining map[contract]int, price map[contract]float64) (map[contract]int, map[contract]float64) {
+	shares := contract{right: models.RightStock}
+	if ot.held[shares] == 0 {
+		return remaining, price
+	}
+
+	delivered, cash := 0, 0.0
+	for _, leg := range ot.trade.Legs {
+		c := contractOfLeg(leg)
+		if leg.Right == models.RightStock || remaining[c] == 0 || ot.held[c] == 0 {
+			continue
+		}
+		n := min(remaining[c], ot.held[c]) * models.ContractMultiplier
+		// Short calls and long puts deliver the shares; short puts and long calls take them
+		sold := (leg.Right == models.RightCall) == (leg.Side == models.SideSell)
+		if sold {
+			cash += leg.Strike * float64(n)
+		} else {
+			cash -= leg.Strike * float64(n)
+		}
+		delivered += n
+	}
+	if delivered == 0 {
+		return remaining, price
+	}
+
+	withShares := make(map[contract]int, len(remaining)+1)
+	withPrice := make(map[contract]float64, len(price)+1)
+	for c, n := range remaining {
+		withShares[c] = n
+	}
+	for c, p := range price {
+		withPrice[c] = p
+	}
+	withShares[shares] = min(delivered, ot.held[shares])
+	withPrice[shares] = cash / float64(delivered)
+	return withShares, withPrice
+}
+
+// exit returns the events that close units of the trade for net, the signed cash
+// per unit (positive for a credit). The exit price of an event is in the direction
+// of the trade, so an exit against it is booked as a zero-price close plus an
+// adjustment for the cash.
+func (ot *openTrade) exit(event *models.TradeEvent, net float64) []*models.TradeEvent {
+	direction := 1.0
+	if ot.trade.EntryValue() < 0 {
+		direction = -1
+	}
+
+	event.Price = direction * net
+	if event.Type == models.EventExpire || event.Price >= 0 {
+		event.Price = math.Max(event.Price, 0)
+		return []*models.TradeEvent{event}
+	}
+
+	adjust := &models.TradeEvent{
+		ID:       event.ID + "-cash",
+		TradeID:  event.TradeID,
+		Type:     models.EventAdjust,
+		Date:     event.Date,
+		Price:    net,
+		Quantity: event.Quantity,
+		Notes:    event.Notes,
+	}
+	event.Price = 0
+	return []*models.TradeEvent{event, adjust}
+}
+
+// Group combines fills into trades and events. Opening fills of one order become
+// one trade, with the strategy inferred from its legs; closing, expiring and
+// assigned fills are matched first-in first-out to open trades holding the same
+// contracts. Fills without an order ID are grouped by day, underlying and effect.
+// IDs are derived from the broker and order, so importing a file twice updates
+// the same records instead of duplicating them.
+func Group(broker string, fills []Fill) *Result {
+	result := &Result{Broker: broker}
+
+	var orders []*order
+	byKey := map[string]*order{}
+	for _, f := range fills {
+		key := f.OrderID
+		if key == "" {
+			key = f.Date.Format("2006-01-02") + "|" + f.Underlying
+		}
+		key = strings.Join([]string{key, f.Underlying, f.Effect}, "|")
+
+		o := byKey[key]
+		if o == nil {
+			o = &order{key: key}
+			byKey[key] = o
+			orders = append(orders, o)
+		}
+		o.fills = append(o.fills, f)
+	}
+
+	// Files are often newest first; process by date, opening orders before closing
+	// ones on the same date so day trades match
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i].fills[0], orders[j].fills[0]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Effect == EffectOpen && b.Effect != EffectOpen
+	})
+
+	open := map[string][]*openTrade{}
+	for _, o := range orders {
+		underlying := o.fills[0].Underlying
+		if o.fills[0].Effect == EffectOpen {
+			trade, ok := openOrder(broker, o, result)
+			if ok {
+				open[underlying] = append(open[underlying], newOpenTrade(trade))
+			}
+			continue
+		}
+		closeOrder(broker, o, open[underlying], result)
+	}
+
+	return result
+}
+
+// openOrder builds a trade from the fills of an opening order
+func openOrder(broker string, o *order, result *Result) (*models.Trade, bool) {
+	first := o.fills[0]
+
+	stockOnly := true
+	for _, f := range o.fills {
+		if f.Right != models.RightStock {
+			stockOnly = false
+		}
+	}
+	if stockOnly {
+		for _, f := range o.fills {
+			result.Skipped = append(result.Skipped, Skipped{Row: f.Row, Reason: "stock-only fills are not tracked as trades"})
+		}
+		return nil, false
+	}
+
+	// Opening commissions and fees are part of the cost of the trade, so they are
+	// folded into the leg fill prices
+	trade := &models.Trade{
+		ID:        deriveID(broker, o.key),
+		Symbol:    first.Underlying,
+		EntryDate: day(first.Date),
+		LegNumber: 1,
+		Legs:      mergeLegs(o.fills, true),
+		Notes:     fmt.Sprintf("Imported from %s", broker),
+	}
+	if first.OrderID != "" {
+		trade.Notes += fmt.Sprintf(" order %s", first.OrderID)
+	}
+	if fees := totalFees(o.fills); fees > 0 {
+		trade.Notes += fmt.Sprintf("; fills include $%.2f of opening fees", fees)
+	}
+
+	for _, leg := range trade.Legs {
+		if leg.Expiration.After(trade.ExpirationDate) {
+			trade.ExpirationDate = leg.Expiration
+		}
+	}
+	trade.EntryPrice = math.Abs(trade.EntryValue())
+	trade.IsMultiLeg = len(trade.Legs) > 1
+	occ.AssignSymbols(trade)
+
+	if t, ok := models.InferStrategyType(trade.Legs); ok {
+		trade.Type = t
+		trade.Strategy = models.StrategyCategoryFor(t)
+	} else {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s trade opened %s (row %d): could not infer the strategy of %d legs",
+			trade.Symbol, trade.EntryDate.Format("2006-01-02"), first.Row, len(trade.Legs)))
+	}
+
+	result.Trades = append(result.Trades, trade)
+	return trade, true
+}
+
+// closeOrder matches the fills of a closing, expiring or assigned order to open
+// trades. Whole units of a trade whose open legs are all in the order are closed
+// by an event; legs closed without the rest of their trade are recorded as an
+// adjustment with the cash of those legs, until the last leg closes the trade.
+// Assigned and exercised options close the shares of their trade at the strike.
+func closeOrder(broker string, o *order, open []*openTrade, result *Result) {
+	remaining := map[contract]int{}
+	price := map[contract]float64{} // Signed per-share price, positive when sold
+	for _, leg := range mergeLegs(o.fills, false) {
+		c := contractOfLeg(leg)
+		remaining[c] += leg.Quantity
+		p := leg.FillPrice
+		if leg.Side == models.SideBuy {
+			p = -p
+		}
+		price[c] = p
+	}
+
+	total := 0
+	for _, q := range remaining {
+		total += q
+	}
+	fees := totalFees(o.fills)
+	feesOf := func(contracts int) float64 {
+		return fees * float64(contracts) / float64(total)
+	}
+
+	first := o.fills[0]
+	notes := fmt.Sprintf("Imported from %s", broker)
+	var touched []*openTrade // Trades the order closed something of
+
+	for _, ot := range open {
+		if ot.units == 0 {
+			continue
+		}
+		available, prices := remaining, price
+		if first.Effect == EffectAssign {
+			available, prices = ot.delivery(remaining, price)
+		}
+
+		// Units closed is limited by the open leg the order holds the fewest units of
+		units := ot.units
+		for _, c := range ot.contracts() {
+			if ot.held[c] == 0 {
+				continue
+			}
+			if n := available[c] / ot.ratio(c); n < units {
+				units = n
+			}
+		}
+		if units == 0 {
+			continue
+		}
+
+		net, contracts := 0.0, 0
+		for _, c := range ot.contracts() {
+			if ot.held[c] == 0 {
+				continue
+			}
+			n := ot.ratio(c) * units
+			ot.held[c] -= n
+			net += prices[c] * perContract(c, n)
+			// Delivered shares are not fills of the order
+			if _, filled := remaining[c]; filled {
+				remaining[c] -= n
+				contracts += n
+			}
+		}
+		ot.units -= units
+		touched = append(touched, ot)
+
+		event := &models.TradeEvent{
+			ID:       deriveID(broker, o.key, ot.trade.ID),
+			TradeID:  ot.trade.ID,
+			Type:     eventType(first.Effect),
+			Date:     day(first.Date),
+			Quantity: units,
+			Fees:     feesOf(contracts),
+			Notes:    notes,
+		}
+		result.Events = append(result.Events, ot.exit(event, net/float64(units))...)
+	}
+
+	for _, ot := range open {
+		if ot.units == 0 {
+			continue
+		}
+
+		cash, contracts := 0.0, 0
+		var closed []string
+		for _, c := range ot.contracts() {
+			n := min(remaining[c], ot.held[c])
+			if n <= 0 {
+				continue
+			}
+			ot.held[c] -= n
+			remaining[c] -= n
+			contracts += n
+			cash += price[c] * perContract(c, n)
+			closed = append(closed, fmt.Sprintf("%d %s", n, c))
+		}
+		if contracts == 0 {
+			continue
+		}
+		if len(touched) == 0 || touched[len(touched)-1] != ot {
+			touched = append(touched, ot)
+		}
+
+		event := &models.TradeEvent{
+			ID:       deriveID(broker, o.key, ot.trade.ID, "legs"),
+			TradeID:  ot.trade.ID,
+			Date:     day(first.Date),
+			Quantity: ot.units,
+			Fees:     feesOf(contracts),
+		}
+		if ot.flat() {
+			// The last open legs close the trade
+			event.Type = eventType(first.Effect)
+			event.Notes = notes
+			ot.units = 0
+			result.Events = append(result.Events, ot.exit(event, cash/float64(event.Quantity))...)
+			continue
+		}
+
+		event.Type = models.EventAdjust
+		event.Price = cash / float64(event.Quantity)
+		event.Notes = fmt.Sprintf("%s: %s %s", notes, first.Effect, strings.Join(closed, ", "))
+		result.Events = append(result.Events, event)
+	}
+
+	if first.Effect == EffectAssign {
+		for _, ot := range touched {
+			if ot.ratio(contract{right: models.RightStock}) == 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s trade opened %s (row %d): the shares delivered by the assignment on %s are not tracked",
+					ot.trade.Symbol, ot.trade.EntryDate.Format("2006-01-02"), first.Row, first.Date.Format("2006-01-02")))
+			}
+		}
+	}
+
+	for _, f := range o.fills {
+		c := contractOf(f)
+		if remaining[c] <= 0 {
+			continue
+		}
+		reason := fmt.Sprintf("no open %s trade holds %d %s to %s", f.Underlying, remaining[c], c, f.Effect)
+		result.Skipped = append(result.Skipped, Skipped{Row: f.Row, Reason: reason})
+		remaining[c] = 0
+	}
+}
+
+// perContract converts a quantity of a contract at a per-share price into the
+// cash of one option contract, the unit of event prices
+func perContract(c contract, quantity int) float64 {
+	if c.right == models.RightStock {
+		return float64(quantity) / models.ContractMultiplier
+	}
+	return float64(quantity)
+}
+
+// mergeLegs combines fills of the same contract and side into legs with a
+// quantity-weighted price, ordered by expiration, right and strike. With
+// withFees, the fees of each fill are added to the price of buys and taken off
+// the price of sells.
+func mergeLegs(fills []Fill, withFees bool) []models.Leg {
+	type legKey struct {
+		contract
+		side string
+	}
+
+	var keys []legKey
+	legs := map[legKey]*models.Leg{}
+	for _, f := range fills {
+		k := legKey{contractOf(f), f.Side}
+		leg := legs[k]
+		if leg == nil {
+			leg = &models.Leg{Right: f.Right, Side: f.Side, Strike: f.Strike, Expiration: f.Expiration}
+			legs[k] = leg
+			keys = append(keys, k)
+		}
+		price := f.Price
+		if withFees && f.Quantity > 0 {
+			perShare := f.Fees / float64(f.Quantity)
+			if f.Right != models.RightStock {
+				perShare /= models.ContractMultiplier
+			}
+			if f.Side == models.SideSell {
+				perShare = -perShare
+			}
+			price += perShare
+		}
+		cost := leg.FillPrice*float64(leg.Quantity) + price*float64(f.Quantity)
+		leg.Quantity += f.Quantity
+		if leg.Quantity > 0 {
+			leg.FillPrice = cost / float64(leg.Quantity)
+		}
+	}
+
+	merged := make([]models.Leg, len(keys))
+	for i, k := range keys {
+		merged[i] = *legs[k]
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if !a.Expiration.Equal(b.Expiration) {
+			return a.Expiration.Before(b.Expiration)
+		}
+		if a.Right != b.Right {
+			return a.Right > b.Right // stock, then puts, then calls
+		}
+		return a.Strike < b.Strike
+	})
+	return merged
+}
+
+// totalFees sums the fees of fills
+func totalFees(fills []Fill) float64 {
+	total := 0.0
+	for _, f := range fills {
+		total += f.Fees
+	}
+	return total
+}
+
+// eventType maps a fill effect to a trade event type
+func eventType(effect string) string {
+	switch effect {
+	case EffectExpire:
+		return models.EventExpire
+	case EffectAssign:
+		return models.EventAssign
+	default:
+		return models.EventClose
+	}
+}
+
+// deriveID returns a stable ID for a record imported from a broker order
+func deriveID(broker string, parts ...string) string {
+	name := broker + "|" + strings.Join(parts, "|")
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
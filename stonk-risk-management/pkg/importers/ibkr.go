@@ -0,0 +1,113 @@
+package importers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+)
+
+// ibkr reads an Interactive Brokers Flex Query trades report exported as CSV
+type ibkr struct{}
+
+func (ibkr) Name() string { return "ibkr" }
+
+func (ibkr) Detect(header []string) bool {
+	return hasColumns(header, "AssetClass", "Symbol", "TradeDate", "Buy/Sell", "Open/CloseIndicator", "Quantity", "TradePrice")
+}
+
+func (i ibkr) Fills(r io.Reader) ([]Fill, []Skipped, error) {
+	t, err := readTable(r, i.Detect)
+	if err != nil {
+		return nil, nil, err
+	}
+	fills, skipped := rowFills(t, i.fill)
+	return fills, skipped, nil
+}
+
+// fill converts a row into a fill, or returns why the row is not one
+func (ibkr) fill(t *table, row []string) (Fill, string, error) {
+	var f Fill
+
+	codes := map[string]bool{}
+	for _, code := range strings.Split(t.get(row, "Notes/Codes"), ";") {
+		codes[strings.TrimSpace(code)] = true
+	}
+
+	switch class := t.get(row, "AssetClass"); class {
+	case "OPT":
+	case "STK":
+		f.Right = models.RightStock
+		if codes["A"] || codes["Ex"] {
+			return f, "share delivery from assignment is not tracked", nil
+		}
+	default:
+		return f, fmt.Sprintf("%s row is not a stock or option fill", class), nil
+	}
+
+	switch strings.ToUpper(t.get(row, "Buy/Sell")) {
+	case "BUY":
+		f.Side = models.SideBuy
+	case "SELL":
+		f.Side = models.SideSell
+	default:
+		return f, "", fmt.Errorf("unknown Buy/Sell %q", t.get(row, "Buy/Sell"))
+	}
+
+	indicator := strings.ToUpper(t.get(row, "Open/CloseIndicator"))
+	switch {
+	case codes["Ep"]:
+		f.Effect = EffectExpire
+	case codes["A"] || codes["Ex"]:
+		f.Effect = EffectAssign
+	case strings.HasPrefix(indicator, "O"):
+		f.Effect = EffectOpen
+	case strings.HasPrefix(indicator, "C"):
+		f.Effect = EffectClose
+	default:
+		return f, "", fmt.Errorf("unknown Open/CloseIndicator %q", indicator)
+	}
+
+	var err error
+	if f.Date, err = parseTime(t.get(row, "TradeDate"), "20060102", "2006-01-02", "01/02/2006"); err != nil {
+		return f, "", err
+	}
+	if f.Quantity, _, err = parseQuantity(t.get(row, "Quantity")); err != nil {
+		return f, "", err
+	}
+	f.OrderID = t.get(row, "IBOrderID")
+	if f.OrderID == "" {
+		f.OrderID = t.get(row, "OrderID")
+	}
+
+	f.Underlying = strings.ToUpper(t.get(row, "UnderlyingSymbol"))
+	if f.Right == models.RightStock {
+		f.Underlying = strings.ToUpper(t.get(row, "Symbol"))
+	} else {
+		c, err := occ.Parse(t.get(row, "Symbol"))
+		if err != nil {
+			return f, "", err
+		}
+		f.Right, f.Strike, f.Expiration = c.Right, c.Strike, c.Expiration
+		if f.Underlying == "" {
+			f.Underlying = c.Root
+		}
+	}
+
+	price, err := parseAmount(t.get(row, "TradePrice"))
+	if err != nil {
+		return f, "", err
+	}
+	f.Price = math.Abs(price)
+
+	commission, err := parseAmount(t.get(row, "IBCommission"))
+	if err != nil {
+		return f, "", err
+	}
+	f.Fees = math.Abs(commission)
+
+	return f, "", nil
+}
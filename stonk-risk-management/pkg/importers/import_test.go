@@ -0,0 +1,170 @@
+package importers
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"stonk-risk-management/pkg/models"
+)
+
+type wantTrade struct {
+	symbol     string
+	status     string
+	quantity   int
+	open       int
+	entryValue float64
+	realizedPL float64
+	events     []string // Event types in date order
+}
+
+func importFile(t *testing.T, path string) *Result {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	result, err := Import(f, "")
+	if err != nil {
+		t.Fatalf("Import(%s): %v", path, err)
+	}
+	return result
+}
+
+func checkTrades(t *testing.T, result *Result, want []wantTrade) {
+	t.Helper()
+	if len(result.Trades) != len(want) {
+		t.Fatalf("%d trades, want %d", len(result.Trades), len(want))
+	}
+
+	events := map[string][]*models.TradeEvent{}
+	for _, e := range result.Events {
+		events[e.TradeID] = append(events[e.TradeID], e)
+	}
+
+	for i, w := range want {
+		trade := result.Trades[i]
+		h := models.NewTradeHistory(trade, events[trade.ID])
+
+		if trade.Symbol != w.symbol {
+			t.Errorf("trade %d symbol = %s, want %s", i, trade.Symbol, w.symbol)
+			continue
+		}
+		if h.Status != w.status || h.Quantity != w.quantity || h.OpenQuantity != w.open {
+			t.Errorf("%s status = %s, %d of %d open, want %s, %d of %d open",
+				w.symbol, h.Status, h.OpenQuantity, h.Quantity, w.status, w.open, w.quantity)
+		}
+		if math.Abs(h.EntryValue-w.entryValue) > 1e-6 {
+			t.Errorf("%s entry value = %.4f, want %.4f", w.symbol, h.EntryValue, w.entryValue)
+		}
+		if math.Abs(h.RealizedPL-w.realizedPL) > 1e-6 {
+			t.Errorf("%s realized P&L = %.2f, want %.2f", w.symbol, h.RealizedPL, w.realizedPL)
+		}
+
+		var types []string
+		for _, e := range h.Events {
+			types = append(types, e.Type)
+		}
+		if strings.Join(types, ",") != strings.Join(w.events, ",") {
+			t.Errorf("%s events = %v, want %v", w.symbol, types, w.events)
+		}
+	}
+}
+
+func checkSkipped(t *testing.T, result *Result, want []Skipped) {
+	t.Helper()
+	if len(result.Skipped) != len(want) {
+		t.Fatalf("skipped = %+v, want %+v", result.Skipped, want)
+	}
+	for i, w := range want {
+		if result.Skipped[i] != w {
+			t.Errorf("skipped[%d] = %+v, want %+v", i, result.Skipped[i], w)
+		}
+	}
+}
+
+func TestImportIBKR(t *testing.T) {
+	result := importFile(t, "testdata/ibkr.csv")
+	if result.Broker != "ibkr" {
+		t.Fatalf("broker = %s, want ibkr", result.Broker)
+	}
+
+	checkTrades(t, result, []wantTrade{
+		// Opening fees are folded into the fills: 12.40 + 0.0065 - (4.10 - 0.0065)
+		{"QQQ", models.StatusOpen, 3, 2, 8.313, (9.80-8.313)*100 - 1.30, []string{models.EventClose}},
+		// The call expiring closes one leg; the shares stay open
+		{"MSFT", models.StatusOpen, 1, 1, 410.01 - 4.4935, 0, []string{models.EventAdjust}},
+	})
+	checkSkipped(t, result, []Skipped{
+		{Row: 9, Reason: "CASH row is not a stock or option fill"},
+		{Row: 10, Reason: "no open IWM trade holds 1 2025-03-21 200 put to close"},
+	})
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", result.Warnings)
+	}
+}
+
+func TestImportSchwab(t *testing.T) {
+	result := importFile(t, "testdata/schwab.csv")
+
+	checkTrades(t, result, []wantTrade{
+		{"NVDA", models.StatusAssigned, 1, 0, -2.7434, 274.34, []string{models.EventAssign}},
+		{"TSLA", models.StatusExpired, 1, 0, -6.1934, 619.34, []string{models.EventExpire}},
+	})
+	checkSkipped(t, result, []Skipped{
+		{Row: 5, Reason: "stock-only fills are not tracked as trades"},
+		{Row: 8, Reason: "Qualified Dividend row is not a fill"},
+		{Row: 9, Reason: "row is not a transaction"},
+	})
+	// The put was assigned without shares in the trade to deliver against
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "NVDA") {
+		t.Errorf("warnings = %v, want one about the NVDA shares", result.Warnings)
+	}
+}
+
+func TestImportTastytrade(t *testing.T) {
+	result := importFile(t, "testdata/tastytrade.csv")
+
+	checkTrades(t, result, []wantTrade{
+		{"AAPL", models.StatusExpired, 1, 0, -1.9772, 197.72, []string{models.EventExpire}},
+		{"SPY", models.StatusClosed, 2, 0, -1.7544, (1.7544-1.00)*200 - 1.04, []string{models.EventClose}},
+	})
+	checkSkipped(t, result, []Skipped{
+		{Row: 14, Reason: "Money Movement Deposit row is not a stock or option fill"},
+	})
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", result.Warnings)
+	}
+}
+
+// An assigned covered call delivers the trade's shares at the strike
+func TestImportCoveredCallAssignment(t *testing.T) {
+	data, err := os.ReadFile("testdata/ibkr.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	csv := strings.Join([]string{
+		lines[0], lines[5], lines[6],
+		`"U1234567","OPT","MSFT  250307C00430000","MSFT","C","430","20250307","100","20250307","BUY","C","1","0","0","","A"`,
+		`"U1234567","STK","MSFT","MSFT","","","","1","20250307","SELL","C","-100","430.00","0","","A"`,
+	}, "\n")
+
+	result, err := Import(strings.NewReader(csv), "ibkr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTrades(t, result, []wantTrade{
+		{"MSFT", models.StatusAssigned, 1, 0, 405.5165, (430 - 405.5165) * 100, []string{models.EventAssign}},
+	})
+	if e := result.Events[0]; math.Abs(e.Price-430) > 1e-9 {
+		t.Errorf("assignment price = %v, want 430", e.Price)
+	}
+	checkSkipped(t, result, []Skipped{
+		{Row: 5, Reason: "share delivery from assignment is not tracked"},
+	})
+}
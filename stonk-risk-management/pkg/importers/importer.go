@@ -0,0 +1,151 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Position effects of a fill
+const (
+	EffectOpen   = "open"
+	EffectClose  = "close"
+	EffectExpire = "expire"
+	EffectAssign = "assign"
+)
+
+// Importer reads one broker's trade-history CSV layout
+type Importer interface {
+	// Name is the broker name used to select the importer
+	Name() string
+	// Detect reports whether a CSV header belongs to the importer's layout
+	Detect(header []string) bool
+	// Fills parses the rows of a file into fills. Rows that are not option or
+	// stock fills, such as deposits, are returned as skipped rows.
+	Fills(r io.Reader) ([]Fill, []Skipped, error)
+}
+
+// Fill is a single execution (or expiration or assignment) of one contract
+type Fill struct {
+	Row        int       `json:"row"`        // Line number in the file
+	Date       time.Time `json:"date"`       // Execution date
+	OrderID    string    `json:"orderId"`    // Broker order ID; fills of one order form one trade or event
+	Underlying string    `json:"underlying"` // Underlying symbol
+	Right      string    `json:"right"`      // call, put or stock
+	Strike     float64   `json:"strike"`
+	Expiration time.Time `json:"expiration"`
+	Side       string    `json:"side"`     // buy or sell; may be empty for expirations
+	Effect     string    `json:"effect"`   // open, close, expire or assign
+	Quantity   int       `json:"quantity"` // Contracts (shares for stock)
+	Price      float64   `json:"price"`    // Per-share price
+	Fees       float64   `json:"fees"`     // Commissions and fees, positive
+}
+
+// Skipped is a row that did not become part of a trade or event
+type Skipped struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// Result is the outcome of importing a broker file
+type Result struct {
+	Broker   string               `json:"broker"`
+	Trades   []*models.Trade      `json:"trades"`   // Trades built from opening fills
+	Events   []*models.TradeEvent `json:"events"`   // Closes, expirations, assignments and legs closed on their own
+	Skipped  []Skipped            `json:"skipped"`  // Rows that were ignored or could not be matched
+	Warnings []string             `json:"warnings"` // Trades imported with incomplete information
+	Saved    bool                 `json:"saved"`    // Whether the result was written (false for a dry run)
+}
+
+// importers are the registered importers, in detection order
+var importers []Importer
+
+// Register adds an importer. Importers registered first win detection ties.
+func Register(i Importer) {
+	importers = append(importers, i)
+}
+
+// Names returns the names of the registered importers
+func Names() []string {
+	names := make([]string, len(importers))
+	for i, imp := range importers {
+		names[i] = imp.Name()
+	}
+	return names
+}
+
+// Get returns the importer with the given name, ignoring case
+func Get(name string) (Importer, bool) {
+	for _, imp := range importers {
+		if strings.EqualFold(imp.Name(), name) {
+			return imp, true
+		}
+	}
+	return nil, false
+}
+
+// Detect returns the importer whose layout matches the file's header row.
+// Brokers often put a title line above the header, so the first few lines are tried.
+func Detect(data []byte) (Importer, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for line := 0; line < 5; line++ {
+		header, err := reader.Read()
+		if err != nil {
+			break
+		}
+		for _, imp := range importers {
+			if imp.Detect(header) {
+				return imp, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unrecognized broker file; supported: %s", strings.Join(Names(), ", "))
+}
+
+// Import reads a broker file and groups its fills into trades and events. An
+// empty broker name detects the layout from the header.
+func Import(r io.Reader, broker string) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var imp Importer
+	if broker == "" {
+		if imp, err = Detect(data); err != nil {
+			return nil, err
+		}
+	} else {
+		var ok bool
+		if imp, ok = Get(broker); !ok {
+			return nil, fmt.Errorf("unknown broker %q; supported: %s", broker, strings.Join(Names(), ", "))
+		}
+	}
+
+	fills, skipped, err := imp.Fills(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s file: %w", imp.Name(), err)
+	}
+
+	result := Group(imp.Name(), fills)
+	result.Skipped = append(skipped, result.Skipped...)
+	sort.SliceStable(result.Skipped, func(i, j int) bool {
+		return result.Skipped[i].Row < result.Skipped[j].Row
+	})
+	return result, nil
+}
+
+func init() {
+	Register(tastytrade{})
+	Register(ibkr{})
+	Register(schwab{})
+}
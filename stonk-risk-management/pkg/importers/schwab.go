@@ -0,0 +1,116 @@
+package importers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+)
+
+// schwab reads the transaction history CSV exported by Charles Schwab
+type schwab struct{}
+
+// schwabOption matches Schwab option symbols such as "AAPL 01/17/2025 150.00 C"
+var schwabOption = regexp.MustCompile(`^([A-Z0-9.]+)\s+(\d{2}/\d{2}/\d{4})\s+([\d.]+)\s+([CP])$`)
+
+func (schwab) Name() string { return "schwab" }
+
+func (schwab) Detect(header []string) bool {
+	return hasColumns(header, "Date", "Action", "Symbol", "Description", "Quantity", "Price", "Fees & Comm", "Amount")
+}
+
+func (i schwab) Fills(r io.Reader) ([]Fill, []Skipped, error) {
+	t, err := readTable(r, i.Detect)
+	if err != nil {
+		return nil, nil, err
+	}
+	fills, skipped := rowFills(t, i.fill)
+	return fills, skipped, nil
+}
+
+// fill converts a row into a fill, or returns why the row is not one
+func (schwab) fill(t *table, row []string) (Fill, string, error) {
+	var f Fill
+
+	action := strings.ToLower(t.get(row, "Action"))
+	switch action {
+	case "buy to open":
+		f.Side, f.Effect = models.SideBuy, EffectOpen
+	case "sell to open":
+		f.Side, f.Effect = models.SideSell, EffectOpen
+	case "buy to close":
+		f.Side, f.Effect = models.SideBuy, EffectClose
+	case "sell to close":
+		f.Side, f.Effect = models.SideSell, EffectClose
+	case "expired":
+		f.Effect = EffectExpire
+	case "assigned", "exchange or exercise":
+		f.Effect = EffectAssign
+	case "buy":
+		f.Side, f.Effect = models.SideBuy, EffectOpen
+	case "sell":
+		f.Side, f.Effect = models.SideSell, EffectClose
+	case "":
+		return f, "row is not a transaction", nil
+	default:
+		return f, fmt.Sprintf("%s row is not a fill", t.get(row, "Action")), nil
+	}
+
+	// Dates of corrected rows read "01/10/2025 as of 01/09/2025"
+	date := t.get(row, "Date")
+	if i := strings.Index(date, " as of "); i >= 0 {
+		date = date[:i]
+	}
+	var err error
+	if f.Date, err = parseTime(date, "01/02/2006", "1/2/2006", "2006-01-02"); err != nil {
+		return f, "", err
+	}
+	if f.Quantity, _, err = parseQuantity(t.get(row, "Quantity")); err != nil {
+		return f, "", err
+	}
+
+	symbol := strings.ToUpper(t.get(row, "Symbol"))
+	if m := schwabOption.FindStringSubmatch(symbol); m != nil {
+		f.Underlying = m[1]
+		if f.Expiration, err = time.Parse("01/02/2006", m[2]); err != nil {
+			return f, "", fmt.Errorf("invalid option symbol %q", symbol)
+		}
+		if f.Strike, err = strconv.ParseFloat(m[3], 64); err != nil {
+			return f, "", fmt.Errorf("invalid option symbol %q", symbol)
+		}
+		f.Right = models.RightCall
+		if m[4] == "P" {
+			f.Right = models.RightPut
+		}
+	} else if c, err := occ.Parse(symbol); err == nil {
+		f.Underlying, f.Right, f.Strike, f.Expiration = c.Root, c.Right, c.Strike, c.Expiration
+	} else if strings.ContainsAny(symbol, " /") {
+		return f, "", fmt.Errorf("invalid option symbol %q", symbol)
+	} else {
+		f.Underlying, f.Right = symbol, models.RightStock
+	}
+
+	if f.Right == models.RightStock && (f.Effect == EffectExpire || f.Effect == EffectAssign) {
+		return f, "share delivery from assignment is not tracked", nil
+	}
+
+	price, err := parseAmount(t.get(row, "Price"))
+	if err != nil {
+		return f, "", err
+	}
+	f.Price = math.Abs(price)
+
+	fees, err := parseAmount(t.get(row, "Fees & Comm"))
+	if err != nil {
+		return f, "", err
+	}
+	f.Fees = math.Abs(fees)
+
+	return f, "", nil
+}
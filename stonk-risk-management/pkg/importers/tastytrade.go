@@ -0,0 +1,131 @@
+package importers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+)
+
+// tastytrade reads the transaction history CSV exported by tastytrade
+type tastytrade struct{}
+
+func (tastytrade) Name() string { return "tastytrade" }
+
+func (tastytrade) Detect(header []string) bool {
+	return hasColumns(header, "Date", "Type", "Action", "Symbol", "Instrument Type", "Underlying Symbol", "Order #")
+}
+
+func (i tastytrade) Fills(r io.Reader) ([]Fill, []Skipped, error) {
+	t, err := readTable(r, i.Detect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fills, skipped := rowFills(t, i.fill)
+	return fills, skipped, nil
+}
+
+// fill converts a row into a fill, or returns why the row is not one
+func (tastytrade) fill(t *table, row []string) (Fill, string, error) {
+	var f Fill
+
+	kind, sub := t.get(row, "Type"), t.get(row, "Sub Type")
+	switch instrument := t.get(row, "Instrument Type"); instrument {
+	case "Equity Option":
+	case "Equity":
+		f.Right = models.RightStock
+	default:
+		return f, fmt.Sprintf("%s row is not a stock or option fill", strings.TrimSpace(kind+" "+sub)), nil
+	}
+
+	switch lower := strings.ToLower(sub); {
+	case kind == "Trade":
+	case kind == "Receive Deliver" && lower == "expiration":
+		f.Effect = EffectExpire
+	case kind == "Receive Deliver" && (strings.Contains(lower, "assignment") || strings.Contains(lower, "exercise")):
+		if f.Right == models.RightStock {
+			return f, "share delivery from assignment is not tracked", nil
+		}
+		f.Effect = EffectAssign
+	default:
+		return f, fmt.Sprintf("%s %s row is not a fill", kind, sub), nil
+	}
+
+	action := strings.ToUpper(strings.ReplaceAll(t.get(row, "Action"), " ", "_"))
+	switch {
+	case strings.HasPrefix(action, "BUY"):
+		f.Side = models.SideBuy
+	case strings.HasPrefix(action, "SELL"):
+		f.Side = models.SideSell
+	}
+	if f.Effect == "" {
+		switch {
+		case strings.HasSuffix(action, "TO_OPEN"):
+			f.Effect = EffectOpen
+		case strings.HasSuffix(action, "TO_CLOSE"):
+			f.Effect = EffectClose
+		default:
+			return f, "", fmt.Errorf("unknown action %q", t.get(row, "Action"))
+		}
+		if f.Side == "" {
+			return f, "", fmt.Errorf("unknown action %q", t.get(row, "Action"))
+		}
+	}
+
+	var err error
+	if f.Date, err = parseTime(t.get(row, "Date"), "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05Z07:00", "01/02/2006 15:04", "01/02/2006", "2006-01-02"); err != nil {
+		return f, "", err
+	}
+	if f.Quantity, _, err = parseQuantity(t.get(row, "Quantity")); err != nil {
+		return f, "", err
+	}
+	f.OrderID = t.get(row, "Order #")
+
+	f.Underlying = strings.ToUpper(t.get(row, "Underlying Symbol"))
+	if f.Underlying == "" {
+		f.Underlying = strings.ToUpper(t.get(row, "Root Symbol"))
+	}
+
+	if f.Right != models.RightStock {
+		c, err := occ.Parse(t.get(row, "Symbol"))
+		if err != nil {
+			return f, "", err
+		}
+		f.Right, f.Strike, f.Expiration = c.Right, c.Strike, c.Expiration
+		if f.Underlying == "" {
+			f.Underlying = c.Root
+		}
+	} else if f.Underlying == "" {
+		f.Underlying = strings.ToUpper(t.get(row, "Symbol"))
+	}
+
+	// Value is the signed cash amount of the row; the per-share price follows from it
+	value, err := parseAmount(t.get(row, "Value"))
+	if err != nil {
+		return f, "", err
+	}
+	multiplier, err := parseAmount(t.get(row, "Multiplier"))
+	if err != nil || multiplier == 0 {
+		multiplier = 1
+		if f.Right != models.RightStock {
+			multiplier = models.ContractMultiplier
+		}
+	}
+	f.Price = math.Abs(value) / (float64(f.Quantity) * multiplier)
+
+	commissions, err := parseAmount(t.get(row, "Commissions"))
+	if err != nil {
+		return f, "", err
+	}
+	fees, err := parseAmount(t.get(row, "Fees"))
+	if err != nil {
+		return f, "", err
+	}
+	f.Fees = math.Abs(commissions) + math.Abs(fees)
+
+	return f, "", nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CashFlow is money moved into or out of the account. Cash flows change the
+// equity without being trading gains or losses.
+type CashFlow struct {
+	ID     string    `json:"id"`
+	Date   time.Time `json:"date"`   // Day of the transfer
+	Amount float64   `json:"amount"` // Positive for a deposit, negative for a withdrawal
+	Notes  string    `json:"notes"`  // e.g. "Opening balance"
+}
+
+// Validate checks the cash flow before it is saved
+func (c *CashFlow) Validate() error {
+	if c.Date.IsZero() {
+		return fmt.Errorf("date is required")
+	}
+	if c.Amount == 0 {
+		return fmt.Errorf("amount cannot be zero")
+	}
+	return nil
+}
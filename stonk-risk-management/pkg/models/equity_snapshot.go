@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// EquitySnapshot is the account value at the end of one day
+type EquitySnapshot struct {
+	Date          time.Time `json:"date"`          // Day of the snapshot (midnight, local time)
+	StartingValue float64   `json:"startingValue"` // Net deposits (deposits less withdrawals) through the end of the day
+	RealizedPL    float64   `json:"realizedPL"`    // Cumulative realized P&L through the end of the day
+	Equity        float64   `json:"equity"`        // StartingValue plus RealizedPL
+}
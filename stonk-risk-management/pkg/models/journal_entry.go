@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Journal entry outcomes
+const (
+	OutcomeWin       = "win"
+	OutcomeLoss      = "loss"
+	OutcomeBreakeven = "breakeven"
+)
+
+// JournalEntry represents a trader's reflection on a trade
+type JournalEntry struct {
+	ID              string    `json:"id"`
+	TradeID         string    `json:"tradeId"`         // Trade this entry reflects on (optional)
+	Date            time.Time `json:"date"`            // Date of the entry
+	Title           string    `json:"title"`           // Brief description of the trade
+	Symbol          string    `json:"symbol"`          // Stock ticker symbol
+	Sector          string    `json:"sector"`          // Industry sector
+	Strategy        string    `json:"strategy"`        // Strategy category
+	Type            string    `json:"type"`            // Specific strategy type
+	Outcome         string    `json:"outcome"`         // win, loss or breakeven
+	PnLAmount       float64   `json:"pnlAmount"`       // Profit or loss in dollars
+	EmotionalState  string    `json:"emotionalState"`  // How the trader felt before/during the trade
+	WhatWentWell    string    `json:"whatWentWell"`    // Aspects executed properly
+	WhatWentPoorly  string    `json:"whatWentPoorly"`  // Mistakes or areas for improvement
+	LessonsLearned  string    `json:"lessonsLearned"`  // Key takeaways
+	ImprovementPlan string    `json:"improvementPlan"` // Actions to improve future trades
+	Tags            []string  `json:"tags"`            // Free-form labels
+	OverrideReason  string    `json:"overrideReason"`  // Why the loss limit was overridden, on override entries
+}
+
+// NewJournalEntry creates a new journal entry with the current date
+func NewJournalEntry() *JournalEntry {
+	return &JournalEntry{
+		Date:    time.Now(),
+		Outcome: OutcomeWin,
+	}
+}
+
+// Validate checks the journal entry before it is saved
+func (j *JournalEntry) Validate() error {
+	if j.Date.IsZero() {
+		return fmt.Errorf("date is required")
+	}
+
+	switch j.Outcome {
+	case OutcomeWin, OutcomeLoss, OutcomeBreakeven:
+	default:
+		return fmt.Errorf("unknown outcome %q", j.Outcome)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Loss limit periods
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// LossLimitOverride lifts a loss-limit lock for the rest of one period
+type LossLimitOverride struct {
+	ID             string    `json:"id"`
+	Period         string    `json:"period"`         // daily or weekly
+	PeriodKey      string    `json:"periodKey"`      // Day ("2025-01-10") or ISO week ("2025-W02") overridden
+	Date           time.Time `json:"date"`           // When the override was made
+	Reason         string    `json:"reason"`         // Why the trader chose to keep trading
+	JournalEntryID string    `json:"journalEntryId"` // Journal entry recording the override
+}
@@ -13,4 +13,5 @@ type PositionSettings struct {
 	CorrelationAdjustment float64 `json:"correlationAdjustment"`
 	VolatilityMultiplier  float64 `json:"volatilityMultiplier"`
 	MaxDrawdownTolerance  float64 `json:"maxDrawdownTolerance"`
+	RiskEnforcement       string  `json:"riskEnforcement"` // "off", "warn" or "block" for trades over the risk budget
 }
@@ -1,16 +1,33 @@
 package models
 
 import (
+	"fmt"
+	"math"
 	"time"
 )
 
+// Range of every psychological dimension on a risk assessment
+const (
+	MinAssessmentScore = -3
+	MaxAssessmentScore = 3
+)
+
+// Range of the scores of assessments saved before the -3..+3 scale
+const (
+	MinLegacyScore = 1
+	MaxLegacyScore = 10
+)
+
 // RiskAssessment represents a trader's daily risk assessment
 type RiskAssessment struct {
 	ID             string    `json:"id"`
 	Date           time.Time `json:"date"`
-	EmotionalScore int       `json:"emotionalScore"` // 1-10 scale
-	FOMOScore      int       `json:"fomoScore"`      // 1-10 scale
-	BiasScore      int       `json:"biasScore"`      // 1-10 scale
+	EmotionalScore int       `json:"emotionalScore"` // -3 (distressed) to +3 (euphoric)
+	FOMOScore      int       `json:"fomoScore"`      // -3 (no FOMO) to +3 (extreme FOMO)
+	BiasScore      int       `json:"biasScore"`      // -3 to +3, market bias (does not affect sizing)
+	PhysicalScore  int       `json:"physicalScore"`  // -3 (exhausted/ill) to +3 (excellent)
+	PLImpactScore  int       `json:"plImpactScore"`  // -3 (large recent losses) to +3 (large recent gains)
+	OtherScore     int       `json:"otherScore"`     // -3 (bad vibes) to +3 (in the zone)
 	OverallScore   int       `json:"overallScore"`   // Computed or manually entered
 	Notes          string    `json:"notes"`          // Optional trader notes
 }
@@ -22,8 +39,53 @@ func NewRiskAssessment() *RiskAssessment {
 	}
 }
 
-// CalculateOverall computes the overall risk score based on individual components
+// CalculateOverall computes the overall risk score based on individual components.
+// Market bias is excluded because it does not reflect the trader's state.
 func (r *RiskAssessment) CalculateOverall() {
-	// Simple average calculation, can be replaced with more complex weighting
-	r.OverallScore = (r.EmotionalScore + r.FOMOScore + r.BiasScore) / 3
+	sum := r.EmotionalScore + r.FOMOScore + r.PhysicalScore + r.PLImpactScore + r.OtherScore
+	// Round half up to match the frontend's Math.round
+	r.OverallScore = int(math.Floor(float64(sum)/5 + 0.5))
+}
+
+// Validate checks that every dimension is within the -3..+3 range
+func (r *RiskAssessment) Validate() error {
+	scores := []struct {
+		name  string
+		value int
+	}{
+		{"emotionalScore", r.EmotionalScore},
+		{"fomoScore", r.FOMOScore},
+		{"biasScore", r.BiasScore},
+		{"physicalScore", r.PhysicalScore},
+		{"plImpactScore", r.PLImpactScore},
+		{"otherScore", r.OtherScore},
+	}
+
+	for _, s := range scores {
+		if s.value < MinAssessmentScore || s.value > MaxAssessmentScore {
+			return fmt.Errorf("%s must be between %d and %d, got %d", s.name, MinAssessmentScore, MaxAssessmentScore, s.value)
+		}
+	}
+
+	return nil
+}
+
+// ClampScore limits a score to the -3..+3 assessment range
+func ClampScore(score int) int {
+	if score < MinAssessmentScore {
+		return MinAssessmentScore
+	}
+	if score > MaxAssessmentScore {
+		return MaxAssessmentScore
+	}
+	return score
+}
+
+// RescaleLegacyScore maps a score from the old 1..10 scale proportionally onto
+// -3..+3, so 1 becomes -3, 10 becomes +3 and the midpoint 5.5 becomes 0
+func RescaleLegacyScore(score int) int {
+	span := float64(MaxAssessmentScore - MinAssessmentScore)
+	legacySpan := float64(MaxLegacyScore - MinLegacyScore)
+	scaled := float64(MinAssessmentScore) + float64(score-MinLegacyScore)*span/legacySpan
+	return ClampScore(int(math.Round(scaled)))
 }
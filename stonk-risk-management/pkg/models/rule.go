@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Rule is a user-editable warning raised when its expression is true
+type Rule struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Expression string `json:"expression"` // e.g. "trades.losingToday >= 3"
+	Severity   string `json:"severity"`   // info, warning or critical
+	Message    string `json:"message"`    // Shown when the rule fires; {variable} is replaced by its value
+	Enabled    bool   `json:"enabled"`
+	Builtin    bool   `json:"builtin"` // One of the rules the app ships with
+}
+
+// Validate checks the rule's fields; the expression itself is checked when it is compiled
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(r.Expression) == "" {
+		return fmt.Errorf("expression is required")
+	}
+
+	switch r.Severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("unknown severity %q", r.Severity)
+	}
+
+	return nil
+}
@@ -0,0 +1,482 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// StrategyCategories lists the strategy types offered by the trade calendar, by category
+var StrategyCategories = map[string][]string{
+	"Basic Spreads":                {"Long Call", "Long Put", "Covered Call"},
+	"Vertical Spreads":             {"Bull Call Spread", "Bear Call Spread", "Bull Put Spread", "Bear Put Spread"},
+	"Calendar/Horizontal Spreads":  {"Long Calendar Call Spread", "Long Calendar Put Spread"},
+	"Diagonal Spreads":             {"Diagonal Call Spread Up", "Diagonal Call Spread Down", "Diagonal Put Spread Up", "Diagonal Put Spread Down"},
+	"Butterfly Spreads":            {"Long Call Butterfly", "Long Put Butterfly", "Broken Wing Butterfly Up", "Broken Wing Butterfly Down"},
+	"Iron Condors/Butterflies":     {"Iron Condor", "Iron Butterfly"},
+	"Ratio Spreads":                {"Call Ratio Backspread", "Put Ratio Backspread"},
+	"Danger - naked options ahead": {"Short Call", "Short Put", "Cash-Secured Put", "Call Ratio Spread", "Put Ratio Spread"},
+}
+
+// StrategyCategoryFor returns the category of a strategy type, or "" if the type is unknown
+func StrategyCategoryFor(strategyType string) string {
+	for category, types := range StrategyCategories {
+		for _, t := range types {
+			if t == strategyType {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// strategyAliases maps common abbreviations and broker names to strategy types.
+// Keys are in the normalized form produced by strategyKey.
+var strategyAliases = map[string]string{
+	"call":                "Long Call",
+	"put":                 "Long Put",
+	"cc":                  "Covered Call",
+	"buywrite":            "Covered Call",
+	"bullcall":            "Bull Call Spread",
+	"calldebitspread":     "Bull Call Spread",
+	"bearcall":            "Bear Call Spread",
+	"callcreditspread":    "Bear Call Spread",
+	"bullput":             "Bull Put Spread",
+	"putcreditspread":     "Bull Put Spread",
+	"bearput":             "Bear Put Spread",
+	"putdebitspread":      "Bear Put Spread",
+	"calendarcall":        "Long Calendar Call Spread",
+	"callcalendar":        "Long Calendar Call Spread",
+	"calendarput":         "Long Calendar Put Spread",
+	"putcalendar":         "Long Calendar Put Spread",
+	"callbutterfly":       "Long Call Butterfly",
+	"putbutterfly":        "Long Put Butterfly",
+	"ic":                  "Iron Condor",
+	"ironfly":             "Iron Butterfly",
+	"ib":                  "Iron Butterfly",
+	"nakedcall":           "Short Call",
+	"nakedput":            "Short Put",
+	"csp":                 "Cash-Secured Put",
+	"cashsecuredput":      "Cash-Secured Put",
+	"callbackspread":      "Call Ratio Backspread",
+	"putbackspread":       "Put Ratio Backspread",
+	"bwbup":               "Broken Wing Butterfly Up",
+	"bwbdown":             "Broken Wing Butterfly Down",
+	"brokenwingbutterfly": "Broken Wing Butterfly Up",
+}
+
+// NormalizeStrategyType matches a free-form strategy name, such as "bull-call" or
+// "IRON_CONDOR", to a known strategy type. Case, punctuation and a trailing
+// "spread" are ignored.
+func NormalizeStrategyType(name string) (string, bool) {
+	key := strategyKey(name)
+	if key == "" {
+		return "", false
+	}
+
+	for _, types := range StrategyCategories {
+		for _, t := range types {
+			known := strategyKey(t)
+			if key == known || key+"spread" == known {
+				return t, true
+			}
+		}
+	}
+
+	if t, ok := strategyAliases[key]; ok {
+		return t, true
+	}
+	if t, ok := strategyAliases[strings.TrimSuffix(key, "spread")]; ok {
+		return t, true
+	}
+	return "", false
+}
+
+// NormalizeStrategyCategory matches a free-form category name to a known category
+func NormalizeStrategyCategory(name string) (string, bool) {
+	key := strategyKey(name)
+	for category := range StrategyCategories {
+		if strategyKey(category) == key {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// strategyKey lowercases a strategy name and drops everything but letters and digits
+func strategyKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// inferenceOrder lists the strategy types InferStrategyType tries, stricter shapes
+// first: ratio spreads would otherwise pass as verticals. Cash-Secured Put is left
+// out because its legs cannot be told apart from a Short Put.
+var inferenceOrder = []string{
+	"Covered Call",
+	"Call Ratio Spread", "Put Ratio Spread", "Call Ratio Backspread", "Put Ratio Backspread",
+	"Bull Call Spread", "Bear Call Spread", "Bull Put Spread", "Bear Put Spread",
+	"Long Calendar Call Spread", "Long Calendar Put Spread",
+	"Diagonal Call Spread Up", "Diagonal Call Spread Down", "Diagonal Put Spread Up", "Diagonal Put Spread Down",
+	"Long Call Butterfly", "Long Put Butterfly", "Broken Wing Butterfly Up", "Broken Wing Butterfly Down",
+	"Iron Condor", "Iron Butterfly",
+	"Long Call", "Long Put", "Short Call", "Short Put",
+}
+
+// InferStrategyType returns the first strategy type whose leg rules the legs satisfy
+func InferStrategyType(legs []Leg) (string, bool) {
+	for _, t := range inferenceOrder {
+		if strategyRules[t].validate(legs) == nil {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Market directions of strategy types
+const (
+	DirectionBullish = "bullish"
+	DirectionBearish = "bearish"
+	DirectionNeutral = "neutral"
+)
+
+// strategyDirections gives the directional bias of each strategy type
+var strategyDirections = map[string]string{
+	"Long Call":                  DirectionBullish,
+	"Covered Call":               DirectionBullish,
+	"Bull Call Spread":           DirectionBullish,
+	"Bull Put Spread":            DirectionBullish,
+	"Diagonal Call Spread Up":    DirectionBullish,
+	"Diagonal Put Spread Down":   DirectionBullish,
+	"Broken Wing Butterfly Up":   DirectionBullish,
+	"Call Ratio Backspread":      DirectionBullish,
+	"Short Put":                  DirectionBullish,
+	"Cash-Secured Put":           DirectionBullish,
+	"Put Ratio Spread":           DirectionBullish,
+	"Long Put":                   DirectionBearish,
+	"Bear Call Spread":           DirectionBearish,
+	"Bear Put Spread":            DirectionBearish,
+	"Diagonal Call Spread Down":  DirectionBearish,
+	"Diagonal Put Spread Up":     DirectionBearish,
+	"Broken Wing Butterfly Down": DirectionBearish,
+	"Put Ratio Backspread":       DirectionBearish,
+	"Short Call":                 DirectionBearish,
+	"Call Ratio Spread":          DirectionBearish,
+}
+
+// StrategyDirection returns the directional bias of a strategy type.
+// Calendars, butterflies, condors and unknown types are neutral.
+func StrategyDirection(strategyType string) string {
+	if direction, ok := strategyDirections[strategyType]; ok {
+		return direction
+	}
+	return DirectionNeutral
+}
+
+// Expiration requirements between the legs of a strategy
+const (
+	expirationsAny = iota
+	expirationsSame
+	expirationsShortFirst // Short legs expire before long legs (calendars and diagonals)
+)
+
+// legShape describes one leg of a strategy. An empty right matches calls or puts,
+// but every wildcard leg of the strategy must then use the same right.
+type legShape struct {
+	right string
+	side  string
+}
+
+// strategyRule describes the legs a strategy type is made of
+type strategyRule struct {
+	shape       []legShape
+	expirations int
+	check       func(legs []Leg) error
+}
+
+var (
+	buyCall  = legShape{RightCall, SideBuy}
+	sellCall = legShape{RightCall, SideSell}
+	buyPut   = legShape{RightPut, SideBuy}
+	sellPut  = legShape{RightPut, SideSell}
+	buyAny   = legShape{"", SideBuy}
+	sellAny  = legShape{"", SideSell}
+)
+
+var strategyRules = map[string]strategyRule{
+	"Long Call":    {shape: []legShape{buyCall}},
+	"Long Put":     {shape: []legShape{buyPut}},
+	"Covered Call": {shape: []legShape{{RightStock, SideBuy}, sellCall}, check: checkCoveredCall},
+
+	"Bull Call Spread": {shape: []legShape{buyCall, sellCall}, expirations: expirationsSame, check: checkStrikes(RightCall, "<")},
+	"Bear Call Spread": {shape: []legShape{sellCall, buyCall}, expirations: expirationsSame, check: checkStrikes(RightCall, ">")},
+	"Bull Put Spread":  {shape: []legShape{sellPut, buyPut}, expirations: expirationsSame, check: checkStrikes(RightPut, "<")},
+	"Bear Put Spread":  {shape: []legShape{buyPut, sellPut}, expirations: expirationsSame, check: checkStrikes(RightPut, ">")},
+
+	"Long Calendar Call Spread": {shape: []legShape{sellCall, buyCall}, expirations: expirationsShortFirst, check: checkStrikes(RightCall, "=")},
+	"Long Calendar Put Spread":  {shape: []legShape{sellPut, buyPut}, expirations: expirationsShortFirst, check: checkStrikes(RightPut, "=")},
+
+	"Diagonal Call Spread Up":   {shape: []legShape{buyCall, sellCall}, expirations: expirationsShortFirst, check: checkStrikes(RightCall, "<")},
+	"Diagonal Call Spread Down": {shape: []legShape{buyCall, sellCall}, expirations: expirationsShortFirst, check: checkStrikes(RightCall, ">")},
+	"Diagonal Put Spread Up":    {shape: []legShape{buyPut, sellPut}, expirations: expirationsShortFirst, check: checkStrikes(RightPut, "<")},
+	"Diagonal Put Spread Down":  {shape: []legShape{buyPut, sellPut}, expirations: expirationsShortFirst, check: checkStrikes(RightPut, ">")},
+
+	"Long Call Butterfly":        {shape: []legShape{buyCall, sellCall, buyCall}, expirations: expirationsSame, check: checkButterfly("=")},
+	"Long Put Butterfly":         {shape: []legShape{buyPut, sellPut, buyPut}, expirations: expirationsSame, check: checkButterfly("=")},
+	"Broken Wing Butterfly Up":   {shape: []legShape{buyAny, sellAny, buyAny}, expirations: expirationsSame, check: checkButterfly(">")},
+	"Broken Wing Butterfly Down": {shape: []legShape{buyAny, sellAny, buyAny}, expirations: expirationsSame, check: checkButterfly("<")},
+
+	"Iron Condor":    {shape: []legShape{buyPut, sellPut, sellCall, buyCall}, expirations: expirationsSame, check: checkIron(false)},
+	"Iron Butterfly": {shape: []legShape{buyPut, sellPut, sellCall, buyCall}, expirations: expirationsSame, check: checkIron(true)},
+
+	"Call Ratio Backspread": {shape: []legShape{sellCall, buyCall}, expirations: expirationsSame, check: checkRatio(RightCall, SideBuy)},
+	"Put Ratio Backspread":  {shape: []legShape{sellPut, buyPut}, expirations: expirationsSame, check: checkRatio(RightPut, SideBuy)},
+
+	"Short Call":        {shape: []legShape{sellCall}},
+	"Short Put":         {shape: []legShape{sellPut}},
+	"Cash-Secured Put":  {shape: []legShape{sellPut}},
+	"Call Ratio Spread": {shape: []legShape{buyCall, sellCall}, expirations: expirationsSame, check: checkRatio(RightCall, SideSell)},
+	"Put Ratio Spread":  {shape: []legShape{buyPut, sellPut}, expirations: expirationsSame, check: checkRatio(RightPut, SideSell)},
+}
+
+// validate checks legs against the rule
+func (r strategyRule) validate(legs []Leg) error {
+	if len(legs) != len(r.shape) {
+		return fmt.Errorf("expected %d legs, got %d", len(r.shape), len(legs))
+	}
+
+	// Resolve wildcard legs to the right used by the trade's option legs
+	wildcardRight := ""
+	for _, leg := range legs {
+		if leg.IsOption() {
+			wildcardRight = leg.Right
+			break
+		}
+	}
+
+	want := map[legShape]int{}
+	for _, s := range r.shape {
+		if s.right == "" {
+			s.right = wildcardRight
+		}
+		want[s]++
+	}
+
+	got := map[legShape]int{}
+	for _, leg := range legs {
+		got[legShape{leg.Right, leg.Side}]++
+	}
+
+	for s, n := range want {
+		if got[s] != n {
+			return fmt.Errorf("expected %d %s %s leg(s), got %d", n, s.side, s.right, got[s])
+		}
+	}
+
+	switch r.expirations {
+	case expirationsSame:
+		var first string
+		for _, leg := range legs {
+			if !leg.IsOption() {
+				continue
+			}
+			day := leg.Expiration.Format("2006-01-02")
+			if first == "" {
+				first = day
+			} else if day != first {
+				return fmt.Errorf("all legs must share the same expiration")
+			}
+		}
+	case expirationsShortFirst:
+		for _, short := range legs {
+			if short.Side != SideSell {
+				continue
+			}
+			for _, long := range legs {
+				if long.Side == SideBuy && !short.Expiration.Before(long.Expiration) {
+					return fmt.Errorf("short leg must expire before the long leg")
+				}
+			}
+		}
+	}
+
+	if r.check != nil {
+		return r.check(legs)
+	}
+
+	return nil
+}
+
+// findLeg returns the first leg with the given right and side
+func findLeg(legs []Leg, right, side string) Leg {
+	for _, leg := range legs {
+		if leg.Right == right && leg.Side == side {
+			return leg
+		}
+	}
+	return Leg{}
+}
+
+// checkStrikes compares the long strike to the short strike of a two-leg spread,
+// whose legs must be the same size
+func checkStrikes(right, relation string) func(legs []Leg) error {
+	return func(legs []Leg) error {
+		if err := checkSameQuantity(legs, right); err != nil {
+			return err
+		}
+
+		long := findLeg(legs, right, SideBuy).Strike
+		short := findLeg(legs, right, SideSell).Strike
+
+		ok := false
+		switch relation {
+		case "<":
+			ok = long < short
+		case ">":
+			ok = long > short
+		case "=":
+			ok = long == short
+		}
+
+		if !ok {
+			return fmt.Errorf("long strike %.2f must be %s short strike %.2f", long, describeRelation(relation), short)
+		}
+		return nil
+	}
+}
+
+// checkSameQuantity ensures the long and short legs of a right hold as many contracts
+func checkSameQuantity(legs []Leg, right string) error {
+	long := findLeg(legs, right, SideBuy).Quantity
+	short := findLeg(legs, right, SideSell).Quantity
+	if long != short {
+		return fmt.Errorf("long %s quantity %d must equal short %s quantity %d", right, long, right, short)
+	}
+	return nil
+}
+
+// checkCoveredCall ensures the shares cover the short calls
+func checkCoveredCall(legs []Leg) error {
+	shares := findLeg(legs, RightStock, SideBuy).Quantity
+	calls := findLeg(legs, RightCall, SideSell).Quantity
+	if shares < calls*100 {
+		return fmt.Errorf("%d shares do not cover %d short calls", shares, calls)
+	}
+	return nil
+}
+
+// checkButterfly checks a three-strike butterfly. The body must sit between the
+// wings, and the legs must be sized 1:2:1. The relation compares the upper wing
+// width to the lower wing width.
+func checkButterfly(relation string) func(legs []Leg) error {
+	return func(legs []Leg) error {
+		var wings []Leg
+		var body Leg
+		for _, leg := range legs {
+			if leg.Side == SideSell {
+				body = leg
+			} else {
+				wings = append(wings, leg)
+			}
+		}
+		sort.Slice(wings, func(i, j int) bool { return wings[i].Strike < wings[j].Strike })
+
+		lower, upper := wings[0], wings[1]
+		if !(lower.Strike < body.Strike && body.Strike < upper.Strike) {
+			return fmt.Errorf("short strike must be between the long strikes")
+		}
+		if lower.Quantity != upper.Quantity || body.Quantity != 2*lower.Quantity {
+			return fmt.Errorf("quantities must be 1:2:1, got %d:%d:%d", lower.Quantity, body.Quantity, upper.Quantity)
+		}
+
+		lowerWidth := body.Strike - lower.Strike
+		upperWidth := upper.Strike - body.Strike
+		switch relation {
+		case "=":
+			if lowerWidth != upperWidth {
+				return fmt.Errorf("wings must be the same width")
+			}
+		case ">":
+			if upperWidth <= lowerWidth {
+				return fmt.Errorf("upper wing must be wider than the lower wing")
+			}
+		case "<":
+			if lowerWidth <= upperWidth {
+				return fmt.Errorf("lower wing must be wider than the upper wing")
+			}
+		}
+		return nil
+	}
+}
+
+// checkIron checks the strikes of an iron condor, or an iron butterfly when sameBody
+// is set. The long and short legs of each wing must be the same size.
+func checkIron(sameBody bool) func(legs []Leg) error {
+	return func(legs []Leg) error {
+		for _, right := range []string{RightPut, RightCall} {
+			if err := checkSameQuantity(legs, right); err != nil {
+				return err
+			}
+		}
+
+		longPut := findLeg(legs, RightPut, SideBuy).Strike
+		shortPut := findLeg(legs, RightPut, SideSell).Strike
+		shortCall := findLeg(legs, RightCall, SideSell).Strike
+		longCall := findLeg(legs, RightCall, SideBuy).Strike
+
+		if !(longPut < shortPut && shortCall < longCall) {
+			return fmt.Errorf("long strikes must be outside the short strikes")
+		}
+		if sameBody && shortPut != shortCall {
+			return fmt.Errorf("short put and short call must share a strike")
+		}
+		if !sameBody && shortPut >= shortCall {
+			return fmt.Errorf("short put strike must be below the short call strike")
+		}
+		return nil
+	}
+}
+
+// checkRatio checks a ratio spread where the larger side holds more contracts
+// and sits further out of the money
+func checkRatio(right, largerSide string) func(legs []Leg) error {
+	return func(legs []Leg) error {
+		larger := findLeg(legs, right, largerSide)
+		smallerSide := SideSell
+		if largerSide == SideSell {
+			smallerSide = SideBuy
+		}
+		smaller := findLeg(legs, right, smallerSide)
+
+		if larger.Quantity <= smaller.Quantity {
+			return fmt.Errorf("%s quantity must be greater than %s quantity", largerSide, smallerSide)
+		}
+
+		outOfMoney := larger.Strike > smaller.Strike
+		if right == RightPut {
+			outOfMoney = larger.Strike < smaller.Strike
+		}
+		if !outOfMoney {
+			return fmt.Errorf("%s strike must be further out of the money than %s strike", largerSide, smallerSide)
+		}
+		return nil
+	}
+}
+
+// describeRelation turns a comparison operator into words for error messages
+func describeRelation(relation string) string {
+	switch relation {
+	case "<":
+		return "below"
+	case ">":
+		return "above"
+	default:
+		return "equal to"
+	}
+}
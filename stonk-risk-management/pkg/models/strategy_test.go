@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateLegsQuantities(t *testing.T) {
+	expiration := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	leg := func(right, side string, quantity int, strike float64) Leg {
+		return Leg{Right: right, Side: side, Quantity: quantity, Strike: strike, Expiration: expiration, FillPrice: 1}
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		legs     []Leg
+		want     string // Error substring, empty when the legs are valid
+	}{
+		{"vertical", "Bull Call Spread", []Leg{
+			leg(RightCall, SideBuy, 2, 150), leg(RightCall, SideSell, 2, 160),
+		}, ""},
+		{"vertical sold three to one", "Bull Call Spread", []Leg{
+			leg(RightCall, SideBuy, 1, 150), leg(RightCall, SideSell, 3, 160),
+		}, "long call quantity 1 must equal short call quantity 3"},
+		{"put vertical bought two to one", "Bear Put Spread", []Leg{
+			leg(RightPut, SideBuy, 2, 160), leg(RightPut, SideSell, 1, 150),
+		}, "long put quantity 2 must equal short put quantity 1"},
+		{"iron condor", "Iron Condor", []Leg{
+			leg(RightPut, SideBuy, 1, 90), leg(RightPut, SideSell, 1, 95),
+			leg(RightCall, SideSell, 1, 105), leg(RightCall, SideBuy, 1, 110),
+		}, ""},
+		{"iron condor with an unbalanced call wing", "Iron Condor", []Leg{
+			leg(RightPut, SideBuy, 1, 90), leg(RightPut, SideSell, 1, 95),
+			leg(RightCall, SideSell, 2, 105), leg(RightCall, SideBuy, 1, 110),
+		}, "long call quantity 1 must equal short call quantity 2"},
+		{"iron butterfly with an unbalanced put wing", "Iron Butterfly", []Leg{
+			leg(RightPut, SideBuy, 3, 90), leg(RightPut, SideSell, 1, 100),
+			leg(RightCall, SideSell, 1, 100), leg(RightCall, SideBuy, 1, 110),
+		}, "long put quantity 3 must equal short put quantity 1"},
+		{"butterfly", "Long Call Butterfly", []Leg{
+			leg(RightCall, SideBuy, 2, 90), leg(RightCall, SideSell, 4, 100), leg(RightCall, SideBuy, 2, 110),
+		}, ""},
+		// The body matches both wings combined, but the wings differ
+		{"butterfly sized 1:4:3", "Long Call Butterfly", []Leg{
+			leg(RightCall, SideBuy, 1, 90), leg(RightCall, SideSell, 4, 100), leg(RightCall, SideBuy, 3, 110),
+		}, "quantities must be 1:2:1, got 1:4:3"},
+		{"butterfly with a small body", "Long Put Butterfly", []Leg{
+			leg(RightPut, SideBuy, 1, 90), leg(RightPut, SideSell, 1, 100), leg(RightPut, SideBuy, 1, 110),
+		}, "quantities must be 1:2:1, got 1:1:1"},
+		{"broken wing butterfly", "Broken Wing Butterfly Up", []Leg{
+			leg(RightCall, SideBuy, 1, 90), leg(RightCall, SideSell, 2, 100), leg(RightCall, SideBuy, 1, 115),
+		}, ""},
+		{"calendar sized two to one", "Long Calendar Call Spread", []Leg{
+			{Right: RightCall, Side: SideSell, Quantity: 2, Strike: 100, Expiration: expiration, FillPrice: 1},
+			{Right: RightCall, Side: SideBuy, Quantity: 1, Strike: 100, Expiration: expiration.AddDate(0, 1, 0), FillPrice: 2},
+		}, "long call quantity 1 must equal short call quantity 2"},
+		// Ratio spreads are unequal by design
+		{"call ratio backspread", "Call Ratio Backspread", []Leg{
+			leg(RightCall, SideSell, 1, 100), leg(RightCall, SideBuy, 2, 110),
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade := &Trade{Type: tt.strategy, Legs: tt.legs}
+			err := trade.ValidateLegs()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("ValidateLegs: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Unbalanced legs are not mistaken for a spread they do not match
+func TestInferStrategyTypeQuantities(t *testing.T) {
+	expiration := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	legs := []Leg{
+		{Right: RightPut, Side: SideBuy, Quantity: 1, Strike: 90, Expiration: expiration},
+		{Right: RightPut, Side: SideSell, Quantity: 1, Strike: 95, Expiration: expiration},
+		{Right: RightCall, Side: SideSell, Quantity: 1, Strike: 105, Expiration: expiration},
+		{Right: RightCall, Side: SideBuy, Quantity: 2, Strike: 110, Expiration: expiration},
+	}
+	if got, ok := InferStrategyType(legs); ok {
+		t.Errorf("InferStrategyType = %s, want no match", got)
+	}
+
+	legs[3].Quantity = 1
+	if got, _ := InferStrategyType(legs); got != "Iron Condor" {
+		t.Errorf("InferStrategyType = %s, want Iron Condor", got)
+	}
+}
@@ -1,9 +1,23 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
+// Option rights for a trade leg
+const (
+	RightCall  = "call"
+	RightPut   = "put"
+	RightStock = "stock" // Share leg, e.g. the stock in a covered call
+)
+
+// Sides of a trade leg
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
 // Trade represents an options trade transaction
 type Trade struct {
 	ID             string    `json:"id"`             // Unique identifier for the trade group (if multi-leg)
@@ -23,4 +37,100 @@ type Trade struct {
 	Entry          float64   `json:"entry"`          // Entry price point
 	Stop           float64   `json:"stop"`           // Stop loss price
 	Target         float64   `json:"target"`         // Price target
+	Legs           []Leg     `json:"legs"`           // Individual contracts that make up the trade
+	RolledFromID   string    `json:"rolledFromId"`   // ID of the trade this one was rolled from, if any
+}
+
+// Leg represents a single contract (or share position) within a trade
+type Leg struct {
+	Strike     float64   `json:"strike"`     // Strike price (ignored for stock legs)
+	Right      string    `json:"right"`      // "call", "put" or "stock"; empty for a summary leg
+	Side       string    `json:"side"`       // "buy" or "sell"
+	Quantity   int       `json:"quantity"`   // Number of contracts (shares for stock legs)
+	Expiration time.Time `json:"expiration"` // Expiration date of the contract
+	FillPrice  float64   `json:"fillPrice"`  // Per-contract (per-share) fill price
+	Symbol     string    `json:"symbol"`     // OCC symbol of an option leg, e.g. "AAPL  250117C00150000"
+}
+
+// IsOption reports whether the leg is an option contract
+func (l Leg) IsOption() bool {
+	return l.Right == RightCall || l.Right == RightPut
+}
+
+// Validate checks the fields of a single leg
+func (l Leg) Validate() error {
+	if l.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+
+	switch l.Right {
+	case RightCall, RightPut:
+		if l.Strike <= 0 {
+			return fmt.Errorf("strike must be positive")
+		}
+		if l.Expiration.IsZero() {
+			return fmt.Errorf("expiration is required")
+		}
+	case RightStock:
+	default:
+		return fmt.Errorf("unknown right %q", l.Right)
+	}
+
+	if l.Side != SideBuy && l.Side != SideSell {
+		return fmt.Errorf("unknown side %q", l.Side)
+	}
+
+	if l.FillPrice < 0 {
+		return fmt.Errorf("fill price cannot be negative")
+	}
+
+	return nil
+}
+
+// SummaryLeg builds the single leg used for trades recorded without leg detail.
+// It only carries the trade's primary expiration and entry price.
+func SummaryLeg(t *Trade) Leg {
+	return Leg{
+		Quantity:   1,
+		Expiration: t.ExpirationDate,
+		FillPrice:  t.EntryPrice,
+	}
+}
+
+// HasLegDetail reports whether the legs describe actual contracts rather than a summary leg
+func (t *Trade) HasLegDetail() bool {
+	return !(len(t.Legs) == 1 && t.Legs[0].Right == "")
+}
+
+// ValidateLegs checks every leg and, for known strategy types, that the legs
+// match the shape of the strategy. Trades with a single summary leg are accepted
+// for any strategy until their legs are entered.
+func (t *Trade) ValidateLegs() error {
+	if len(t.Legs) == 0 {
+		return fmt.Errorf("trade must have at least one leg")
+	}
+
+	if !t.HasLegDetail() {
+		if t.Legs[0].Quantity <= 0 {
+			return fmt.Errorf("leg 1: quantity must be positive")
+		}
+		return nil
+	}
+
+	for i, leg := range t.Legs {
+		if err := leg.Validate(); err != nil {
+			return fmt.Errorf("leg %d: %w", i+1, err)
+		}
+	}
+
+	rule, ok := strategyRules[t.Type]
+	if !ok {
+		return nil
+	}
+
+	if err := rule.validate(t.Legs); err != nil {
+		return fmt.Errorf("%s: %w", t.Type, err)
+	}
+
+	return nil
 }
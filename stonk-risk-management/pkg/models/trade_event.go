@@ -0,0 +1,256 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ContractMultiplier is the number of shares controlled by one option contract
+const ContractMultiplier = 100
+
+// Trade event types
+const (
+	EventClose  = "close"  // Position (or part of it) bought/sold back
+	EventExpire = "expire" // Contracts expired worthless
+	EventAssign = "assign" // Contracts were assigned or exercised
+	EventRoll   = "roll"   // Position closed and reopened as a new trade
+	EventAdjust = "adjust" // Cash adjustment that does not change the open quantity
+)
+
+// Derived trade statuses
+const (
+	StatusOpen     = "open"
+	StatusClosed   = "closed"
+	StatusExpired  = "expired"
+	StatusAssigned = "assigned"
+	StatusRolled   = "rolled"
+)
+
+// TradeEvent records something that happened to a trade after entry
+type TradeEvent struct {
+	ID         string    `json:"id"`
+	TradeID    string    `json:"tradeId"`
+	Type       string    `json:"type"`       // close, expire, assign, roll or adjust
+	Date       time.Time `json:"date"`       // Date of the exit or adjustment
+	Price      float64   `json:"price"`      // Per-unit exit price; for adjustments the net credit (+) or debit (-)
+	Quantity   int       `json:"quantity"`   // Units of the trade closed (or adjusted)
+	Fees       float64   `json:"fees"`       // Commissions and fees for the event
+	RolledToID string    `json:"rolledToId"` // ID of the new trade for roll events
+	Notes      string    `json:"notes"`      // Optional notes
+}
+
+// ClosesPosition reports whether the event reduces the open quantity
+func (e *TradeEvent) ClosesPosition() bool {
+	return e.Type != EventAdjust
+}
+
+// TradeHistory is a trade together with its events and the values derived from them
+type TradeHistory struct {
+	Trade        *Trade        `json:"trade"`
+	Events       []*TradeEvent `json:"events"`
+	Status       string        `json:"status"`
+	Quantity     int           `json:"quantity"`     // Units opened
+	OpenQuantity int           `json:"openQuantity"` // Units still open
+	EntryValue   float64       `json:"entryValue"`   // Per-unit entry price, positive for a debit and negative for a credit
+	RealizedPL   float64       `json:"realizedPL"`   // Realized profit or loss in dollars, net of fees
+	Fees         float64       `json:"fees"`         // Total fees across all events
+	ClosedDate   *time.Time    `json:"closedDate"`   // Date the last unit was closed, nil while open
+}
+
+// creditStrategies are the strategy types opened for a net credit
+var creditStrategies = map[string]bool{
+	"Bear Call Spread":  true,
+	"Bull Put Spread":   true,
+	"Iron Condor":       true,
+	"Iron Butterfly":    true,
+	"Short Call":        true,
+	"Short Put":         true,
+	"Cash-Secured Put":  true,
+	"Call Ratio Spread": true,
+	"Put Ratio Spread":  true,
+}
+
+// Quantity returns the number of units of the trade, the greatest common
+// divisor of its leg quantities (a 1x2 ratio spread of 3 units has legs of 3 and 6)
+func (t *Trade) Quantity() int {
+	units := 0
+	for _, leg := range t.Legs {
+		q := leg.Quantity
+		if leg.Right == RightStock {
+			q = leg.Quantity / ContractMultiplier
+		}
+		units = gcd(units, q)
+	}
+	if units == 0 {
+		return 1
+	}
+	return units
+}
+
+// EntryValue returns the per-unit entry price, positive for a debit and negative for a credit.
+// With leg detail it is the net of the leg fills; otherwise EntryPrice is signed by strategy type.
+func (t *Trade) EntryValue() float64 {
+	if !t.HasLegDetail() {
+		price := t.EntryPrice
+		if price < 0 {
+			price = -price
+		}
+		if creditStrategies[t.Type] {
+			return -price
+		}
+		return price
+	}
+
+	net := 0.0
+	for _, leg := range t.Legs {
+		amount := leg.FillPrice * float64(leg.Quantity)
+		if leg.Right == RightStock {
+			amount /= ContractMultiplier
+		}
+		if leg.Side == SideSell {
+			amount = -amount
+		}
+		net += amount
+	}
+
+	return net / float64(t.Quantity())
+}
+
+// NewTradeHistory derives the status and realized P&L of a trade from its events
+func NewTradeHistory(trade *Trade, events []*TradeEvent) *TradeHistory {
+	sorted := make([]*TradeEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	h := &TradeHistory{
+		Trade:      trade,
+		Events:     sorted,
+		Status:     StatusOpen,
+		Quantity:   trade.Quantity(),
+		EntryValue: trade.EntryValue(),
+	}
+	h.OpenQuantity = h.Quantity
+
+	for _, e := range sorted {
+		h.Fees += e.Fees
+		h.RealizedPL += h.EventPL(e)
+
+		if !e.ClosesPosition() {
+			continue
+		}
+		h.OpenQuantity -= e.Quantity
+
+		if h.OpenQuantity <= 0 {
+			h.OpenQuantity = 0
+			closed := e.Date
+			h.ClosedDate = &closed
+			h.Status = statusFor(e.Type)
+		}
+	}
+
+	return h
+}
+
+// EventPL returns the profit or loss realized by a single event, net of its fees
+func (h *TradeHistory) EventPL(e *TradeEvent) float64 {
+	if !e.ClosesPosition() {
+		return e.Price*float64(e.Quantity)*ContractMultiplier - e.Fees
+	}
+
+	price := e.Price
+	if e.Type == EventExpire {
+		price = 0
+	}
+	return (h.direction()*price-h.EntryValue)*float64(e.Quantity)*ContractMultiplier - e.Fees
+}
+
+// RealizedBetween returns the P&L realized by events dated in [start, end)
+func (h *TradeHistory) RealizedBetween(start, end time.Time) float64 {
+	total := 0.0
+	for _, e := range h.Events {
+		if !e.Date.Before(start) && e.Date.Before(end) {
+			total += h.EventPL(e)
+		}
+	}
+	return total
+}
+
+// UnrealizedPL returns the P&L of the open units marked at a per-unit price
+func (h *TradeHistory) UnrealizedPL(mark float64) float64 {
+	return (h.direction()*mark - h.EntryValue) * float64(h.OpenQuantity) * ContractMultiplier
+}
+
+// direction is 1 for debit trades, which profit when the exit price rises,
+// and -1 for credit trades, which profit when it falls
+func (h *TradeHistory) direction() float64 {
+	if h.EntryValue < 0 {
+		return -1
+	}
+	return 1
+}
+
+// ValidateEvent checks that an event can be applied to the current history
+func (h *TradeHistory) ValidateEvent(e *TradeEvent) error {
+	switch e.Type {
+	case EventClose, EventExpire, EventAssign, EventRoll, EventAdjust:
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+
+	if e.Date.IsZero() {
+		return fmt.Errorf("event date is required")
+	}
+	if e.Date.Before(h.Trade.EntryDate.Truncate(24 * time.Hour)) {
+		return fmt.Errorf("event date cannot be before the entry date")
+	}
+	if e.Fees < 0 {
+		return fmt.Errorf("fees cannot be negative")
+	}
+
+	if !e.ClosesPosition() {
+		return nil
+	}
+
+	if h.OpenQuantity == 0 {
+		return fmt.Errorf("trade is already %s", h.Status)
+	}
+	if e.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	if e.Quantity > h.OpenQuantity {
+		return fmt.Errorf("cannot close %d units, only %d open", e.Quantity, h.OpenQuantity)
+	}
+	if e.Type != EventExpire && e.Price < 0 {
+		return fmt.Errorf("exit price cannot be negative")
+	}
+
+	return nil
+}
+
+// statusFor maps the event that closed the last unit to the trade status
+func statusFor(eventType string) string {
+	switch eventType {
+	case EventExpire:
+		return StatusExpired
+	case EventAssign:
+		return StatusAssigned
+	case EventRoll:
+		return StatusRolled
+	default:
+		return StatusClosed
+	}
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,164 @@
+package occ
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// OCC symbols are a root of up to six characters, padded with spaces to six in
+// the standard form, then YYMMDD, C or P, and the strike times 1000 in eight digits
+const (
+	rootWidth        = 6
+	expirationLayout = "060102"
+	strikeScale      = 1000
+	maxStrike        = 99999999
+)
+
+// pattern matches padded ("AAPL  250117C00150000") and compact ("AAPL250117C00150000") symbols
+var pattern = regexp.MustCompile(`^([A-Z0-9]{1,6})\s*(\d{6})([CP])(\d{8})$`)
+
+// Symbol is an option series identified by an OCC (OSI) symbol
+type Symbol struct {
+	Root       string    `json:"root"`       // Option root, usually the underlying ticker
+	Expiration time.Time `json:"expiration"` // Expiration date
+	Right      string    `json:"right"`      // models.RightCall or models.RightPut
+	Strike     float64   `json:"strike"`     // Strike price
+}
+
+// Parse parses a padded or compact OCC symbol
+func Parse(s string) (Symbol, error) {
+	m := pattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return Symbol{}, fmt.Errorf("invalid OCC symbol %q", s)
+	}
+
+	expiration, err := time.Parse(expirationLayout, m[2])
+	if err != nil {
+		return Symbol{}, fmt.Errorf("invalid expiration in OCC symbol %q", s)
+	}
+	strike, err := strconv.Atoi(m[4])
+	if err != nil || strike == 0 {
+		return Symbol{}, fmt.Errorf("invalid strike in OCC symbol %q", s)
+	}
+
+	right := models.RightCall
+	if m[3] == "P" {
+		right = models.RightPut
+	}
+
+	return Symbol{
+		Root:       m[1],
+		Expiration: expiration,
+		Right:      right,
+		Strike:     float64(strike) / strikeScale,
+	}, nil
+}
+
+// New builds the symbol of an option series, checking that it can be encoded.
+// The root is upper-cased and punctuation such as the dot in "BRK.B" is dropped.
+func New(root string, expiration time.Time, right string, strike float64) (Symbol, error) {
+	s := Symbol{Root: Root(root), Expiration: expiration, Right: right, Strike: strike}
+	if err := s.check(); err != nil {
+		return Symbol{}, err
+	}
+	return s, nil
+}
+
+// ForLeg builds the symbol of an option leg of a trade on the given underlying
+func ForLeg(underlying string, leg models.Leg) (Symbol, error) {
+	if !leg.IsOption() {
+		return Symbol{}, fmt.Errorf("%q leg has no OCC symbol", leg.Right)
+	}
+	return New(underlying, leg.Expiration, leg.Right, leg.Strike)
+}
+
+// Root converts a ticker to an OCC root
+func Root(ticker string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(ticker) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// String returns the standard 21-character form with the root padded to six characters
+func (s Symbol) String() string {
+	return fmt.Sprintf("%-*s%s", rootWidth, s.Root, s.series())
+}
+
+// Compact returns the form without root padding
+func (s Symbol) Compact() string {
+	return s.Root + s.series()
+}
+
+// series formats the expiration, right and strike
+func (s Symbol) series() string {
+	right := "C"
+	if s.Right == models.RightPut {
+		right = "P"
+	}
+	return fmt.Sprintf("%s%s%08d", s.Expiration.Format(expirationLayout), right, s.strikeUnits())
+}
+
+// strikeUnits returns the strike in thousandths of a dollar
+func (s Symbol) strikeUnits() int {
+	return int(math.Round(s.Strike * strikeScale))
+}
+
+// check reports why a symbol cannot be encoded
+func (s Symbol) check() error {
+	if s.Root == "" || len(s.Root) > rootWidth {
+		return fmt.Errorf("OCC root %q must be 1 to %d letters or digits", s.Root, rootWidth)
+	}
+	if s.Right != models.RightCall && s.Right != models.RightPut {
+		return fmt.Errorf("unknown right %q", s.Right)
+	}
+	if s.Expiration.IsZero() {
+		return fmt.Errorf("expiration is required")
+	}
+	if units := s.strikeUnits(); units <= 0 || units > maxStrike {
+		return fmt.Errorf("strike %g cannot be encoded", s.Strike)
+	}
+	return nil
+}
+
+// Validate checks that the symbol describes the given option leg of a trade on the underlying
+func (s Symbol) Validate(underlying string, leg models.Leg) error {
+	if !leg.IsOption() {
+		return fmt.Errorf("OCC symbol %s given for a %q leg", s, leg.Right)
+	}
+	if root := Root(underlying); root != s.Root {
+		return fmt.Errorf("OCC symbol %s is for %s, not %s", s, s.Root, root)
+	}
+	if s.Right != leg.Right {
+		return fmt.Errorf("OCC symbol %s is a %s, not a %s", s, s.Right, leg.Right)
+	}
+	if s.strikeUnits() != (Symbol{Strike: leg.Strike}).strikeUnits() {
+		return fmt.Errorf("OCC symbol %s has strike %g, not %g", s, s.Strike, leg.Strike)
+	}
+	if s.Expiration.Format(expirationLayout) != leg.Expiration.Format(expirationLayout) {
+		return fmt.Errorf("OCC symbol %s expires %s, not %s", s, s.Expiration.Format("2006-01-02"), leg.Expiration.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// AssignSymbols sets the canonical OCC symbol of every option leg of a trade.
+// Legs that cannot be encoded, such as legs with a missing strike, are left empty
+// for leg validation to report.
+func AssignSymbols(trade *models.Trade) {
+	for i := range trade.Legs {
+		leg := &trade.Legs[i]
+		leg.Symbol = ""
+		if s, err := ForLeg(trade.Symbol, *leg); err == nil {
+			leg.Symbol = s.String()
+		}
+	}
+}
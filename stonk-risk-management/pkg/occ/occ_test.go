@@ -0,0 +1,203 @@
+package occ
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Symbol
+		padded  string
+		compact string
+	}{
+		{
+			name:    "padded call",
+			input:   "AAPL  250117C00150000",
+			want:    Symbol{Root: "AAPL", Expiration: date("2025-01-17"), Right: models.RightCall, Strike: 150},
+			padded:  "AAPL  250117C00150000",
+			compact: "AAPL250117C00150000",
+		},
+		{
+			name:    "compact put",
+			input:   "SPY251219P00500000",
+			want:    Symbol{Root: "SPY", Expiration: date("2025-12-19"), Right: models.RightPut, Strike: 500},
+			padded:  "SPY   251219P00500000",
+			compact: "SPY251219P00500000",
+		},
+		{
+			name:    "fractional strike",
+			input:   "F     260116C00012500",
+			want:    Symbol{Root: "F", Expiration: date("2026-01-16"), Right: models.RightCall, Strike: 12.5},
+			padded:  "F     260116C00012500",
+			compact: "F260116C00012500",
+		},
+		{
+			// The root of BRK.B drops the dot
+			name:    "class share root",
+			input:   "BRKB  261218P00480000",
+			want:    Symbol{Root: "BRKB", Expiration: date("2026-12-18"), Right: models.RightPut, Strike: 480},
+			padded:  "BRKB  261218P00480000",
+			compact: "BRKB261218P00480000",
+		},
+		{
+			// An adjusted root ends in a digit, which the compact form runs into the date
+			name:    "adjusted root",
+			input:   "AMC1261218C00005000",
+			want:    Symbol{Root: "AMC1", Expiration: date("2026-12-18"), Right: models.RightCall, Strike: 5},
+			padded:  "AMC1  261218C00005000",
+			compact: "AMC1261218C00005000",
+		},
+		{
+			name:    "six-character root",
+			input:   "GOOGL1270115C01000500",
+			want:    Symbol{Root: "GOOGL1", Expiration: date("2027-01-15"), Right: models.RightCall, Strike: 1000.5},
+			padded:  "GOOGL1270115C01000500",
+			compact: "GOOGL1270115C01000500",
+		},
+		{
+			name:    "lower case with spaces around",
+			input:   "  aapl  250117c00150000 ",
+			want:    Symbol{Root: "AAPL", Expiration: date("2025-01-17"), Right: models.RightCall, Strike: 150},
+			padded:  "AAPL  250117C00150000",
+			compact: "AAPL250117C00150000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if s != tt.want {
+				t.Errorf("Parse = %+v, want %+v", s, tt.want)
+			}
+			if got := s.String(); got != tt.padded {
+				t.Errorf("String = %q, want %q", got, tt.padded)
+			}
+			if got := s.Compact(); got != tt.compact {
+				t.Errorf("Compact = %q, want %q", got, tt.compact)
+			}
+
+			// Both forms parse back to the same series
+			for _, form := range []string{s.String(), s.Compact()} {
+				again, err := Parse(form)
+				if err != nil || again != s {
+					t.Errorf("Parse(%q) = %+v, %v, want %+v", form, again, err, s)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"AAPL",
+		"BRK.B 261218P00480000",  // Dots are not part of a root
+		"TOOLONG250117C00150000", // Seven-character root
+		"AAPL  250117X00150000",  // Neither call nor put
+		"AAPL  251317C00150000",  // Month 13
+		"AAPL  250117C0015000",   // Seven strike digits
+		"AAPL  250117C00000000",  // Zero strike
+	} {
+		if s, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", input, s)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	expiration := date("2026-12-18")
+
+	s, err := New("brk.b", expiration, models.RightPut, 480)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String(); got != "BRKB  261218P00480000" {
+		t.Errorf("String = %q, want BRKB  261218P00480000", got)
+	}
+
+	tests := []struct {
+		name       string
+		root       string
+		expiration time.Time
+		right      string
+		strike     float64
+		want       string
+	}{
+		{"largest strike", "SPX", expiration, models.RightCall, 99999.999, ""},
+		{"strike overflow", "SPX", expiration, models.RightCall, 100000, "cannot be encoded"},
+		{"strike rounding to zero", "SPX", expiration, models.RightCall, 0.0004, "cannot be encoded"},
+		{"negative strike", "SPX", expiration, models.RightCall, -5, "cannot be encoded"},
+		{"empty root", ".", expiration, models.RightCall, 10, "must be 1 to 6"},
+		{"long root", "ABCDEFG", expiration, models.RightCall, 10, "must be 1 to 6"},
+		{"stock right", "AAPL", expiration, models.RightStock, 10, "unknown right"},
+		{"no expiration", "AAPL", time.Time{}, models.RightCall, 10, "expiration is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.root, tt.expiration, tt.right, tt.strike)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("New: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := Parse("BRKB  261218C00012500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leg := models.Leg{Right: models.RightCall, Side: models.SideBuy, Quantity: 1, Strike: 12.5, Expiration: date("2026-12-18")}
+	if err := s.Validate("BRK.B", leg); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		underlying string
+		edit       func(l *models.Leg)
+		want       string
+	}{
+		{"other underlying", "BRK.A", nil, "is for BRKB, not BRKA"},
+		{"other right", "BRK.B", func(l *models.Leg) { l.Right = models.RightPut }, "is a call, not a put"},
+		{"other strike", "BRK.B", func(l *models.Leg) { l.Strike = 12.75 }, "has strike 12.5, not 12.75"},
+		{"other expiration", "BRK.B", func(l *models.Leg) { l.Expiration = date("2026-12-19") }, "expires 2026-12-18, not 2026-12-19"},
+		{"stock leg", "BRK.B", func(l *models.Leg) { l.Right = models.RightStock }, "given for a \"stock\" leg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := leg
+			if tt.edit != nil {
+				tt.edit(&l)
+			}
+			err := s.Validate(tt.underlying, l)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
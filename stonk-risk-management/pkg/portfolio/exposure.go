@@ -0,0 +1,220 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+)
+
+// Bucket is the exposure of open trades sharing one attribute
+type Bucket struct {
+	Key      string  `json:"key"`
+	Exposure float64 `json:"exposure"` // Dollars at risk
+	Percent  float64 `json:"percent"`  // Percent of account value
+	Trades   int     `json:"trades"`
+}
+
+// Position is the exposure of a single open trade
+type Position struct {
+	TradeID          string  `json:"tradeId"`
+	Symbol           string  `json:"symbol"`
+	Sector           string  `json:"sector"`
+	Strategy         string  `json:"strategy"`
+	Type             string  `json:"type"`
+	Direction        string  `json:"direction"`
+	ExpirationWeek   string  `json:"expirationWeek"`
+	OpenQuantity     int     `json:"openQuantity"`
+	Exposure         float64 `json:"exposure"`
+	MaxLossUnlimited bool    `json:"maxLossUnlimited"` // Exposure is the short notional instead of a defined max loss
+}
+
+// Exposure aggregates the open trades of the portfolio
+type Exposure struct {
+	AsOf                 time.Time  `json:"asOf"`
+	AccountValue         float64    `json:"accountValue"`
+	TotalExposure        float64    `json:"totalExposure"`
+	TotalPercent         float64    `json:"totalPercent"`
+	MaxPortfolioExposure float64    `json:"maxPortfolioExposure"` // Limit as a percent of account value
+	OverLimit            bool       `json:"overLimit"`
+	BySector             []Bucket   `json:"bySector"`
+	BySymbol             []Bucket   `json:"bySymbol"`
+	ByStrategy           []Bucket   `json:"byStrategy"`
+	ByExpirationWeek     []Bucket   `json:"byExpirationWeek"`
+	ByDirection          []Bucket   `json:"byDirection"`
+	Positions            []Position `json:"positions"`
+}
+
+// Aggregate computes the exposure of the trades open on asOf's calendar day. Events
+// after that day are ignored, so past dates show the portfolio as it was then.
+func Aggregate(trades []*models.Trade, events map[string][]*models.TradeEvent, settings *models.PositionSettings, asOf time.Time) *Exposure {
+	exposure := &Exposure{
+		AsOf:      asOf,
+		Positions: []Position{},
+	}
+	if settings != nil {
+		exposure.AccountValue = settings.AccountValue
+		exposure.MaxPortfolioExposure = settings.MaxPortfolioExposure
+	}
+
+	for _, trade := range trades {
+		position, open := openPosition(trade, events[trade.ID], asOf)
+		if open {
+			exposure.Positions = append(exposure.Positions, position)
+			exposure.TotalExposure += position.Exposure
+		}
+	}
+
+	sort.Slice(exposure.Positions, func(i, j int) bool {
+		return exposure.Positions[i].Exposure > exposure.Positions[j].Exposure
+	})
+
+	exposure.TotalPercent = exposure.percentOf(exposure.TotalExposure)
+	exposure.OverLimit = exposure.MaxPortfolioExposure > 0 && exposure.TotalPercent > exposure.MaxPortfolioExposure
+
+	exposure.BySector = exposure.bucket(func(p Position) string { return p.Sector })
+	exposure.BySymbol = exposure.bucket(func(p Position) string { return p.Symbol })
+	exposure.ByStrategy = exposure.bucket(func(p Position) string { return p.Strategy })
+	exposure.ByDirection = exposure.bucket(func(p Position) string { return p.Direction })
+	exposure.ByExpirationWeek = exposure.bucket(func(p Position) string { return p.ExpirationWeek })
+
+	// Expiration weeks read best in calendar order
+	sort.Slice(exposure.ByExpirationWeek, func(i, j int) bool {
+		return exposure.ByExpirationWeek[i].Key < exposure.ByExpirationWeek[j].Key
+	})
+
+	return exposure
+}
+
+// ExpirationWeek returns the ISO week of a date, e.g. "2025-W03", or "Unknown"
+// for a trade without an expiration
+func ExpirationWeek(date time.Time) string {
+	if date.IsZero() {
+		return "Unknown"
+	}
+	year, week := date.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// openPosition returns the exposure of a trade if it is open on asOf's calendar day.
+// Dates are stored at midnight UTC, so they are compared with the UTC bounds of the day;
+// a trade is still open on its expiration day.
+func openPosition(trade *models.Trade, events []*models.TradeEvent, asOf time.Time) (Position, bool) {
+	_, dayStart, dayEnd := risk.DayPeriod(asOf)
+	if !trade.EntryDate.Before(dayEnd) {
+		return Position{}, false
+	}
+
+	expiration := lastExpiration(trade)
+	if !expiration.IsZero() && expiration.Before(dayStart) {
+		return Position{}, false
+	}
+
+	var past []*models.TradeEvent
+	for _, e := range events {
+		if e.Date.Before(dayEnd) {
+			past = append(past, e)
+		}
+	}
+	history := models.NewTradeHistory(trade, past)
+	if history.OpenQuantity == 0 {
+		return Position{}, false
+	}
+
+	maxLoss, unlimited, _ := risk.MaxLoss(trade)
+	if unlimited {
+		maxLoss = shortNotional(trade)
+	}
+
+	category := trade.Strategy
+	if category == "" {
+		category = models.StrategyCategoryFor(trade.Type)
+	}
+
+	return Position{
+		TradeID:          trade.ID,
+		Symbol:           trade.Symbol,
+		Sector:           trade.Sector,
+		Strategy:         category,
+		Type:             trade.Type,
+		Direction:        models.StrategyDirection(trade.Type),
+		ExpirationWeek:   ExpirationWeek(firstExpiration(trade)),
+		OpenQuantity:     history.OpenQuantity,
+		Exposure:         maxLoss * float64(history.OpenQuantity) / float64(history.Quantity),
+		MaxLossUnlimited: unlimited,
+	}, true
+}
+
+// bucket groups positions by key
+func (e *Exposure) bucket(key func(Position) string) []Bucket {
+	byKey := map[string]*Bucket{}
+	var buckets []*Bucket
+	for _, p := range e.Positions {
+		k := key(p)
+		if k == "" {
+			k = "Unknown"
+		}
+		b, ok := byKey[k]
+		if !ok {
+			b = &Bucket{Key: k}
+			byKey[k] = b
+			buckets = append(buckets, b)
+		}
+		b.Exposure += p.Exposure
+		b.Trades++
+	}
+
+	result := make([]Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		b.Percent = e.percentOf(b.Exposure)
+		result = append(result, *b)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Exposure > result[j].Exposure
+	})
+	return result
+}
+
+// percentOf converts dollars to a percent of account value
+func (e *Exposure) percentOf(amount float64) float64 {
+	if e.AccountValue <= 0 {
+		return 0
+	}
+	return amount / e.AccountValue * 100
+}
+
+// shortNotional is the strike value of the short options, used as the exposure of undefined-risk trades
+func shortNotional(trade *models.Trade) float64 {
+	total := 0.0
+	for _, leg := range trade.Legs {
+		if leg.IsOption() && leg.Side == models.SideSell {
+			total += leg.Strike * float64(leg.Quantity*models.ContractMultiplier)
+		}
+	}
+	return total
+}
+
+// firstExpiration returns the earliest option expiration of a trade
+func firstExpiration(trade *models.Trade) time.Time {
+	first := trade.ExpirationDate
+	for _, leg := range trade.Legs {
+		if leg.IsOption() && !leg.Expiration.IsZero() && (first.IsZero() || leg.Expiration.Before(first)) {
+			first = leg.Expiration
+		}
+	}
+	return first
+}
+
+// lastExpiration returns the latest option expiration of a trade
+func lastExpiration(trade *models.Trade) time.Time {
+	last := trade.ExpirationDate
+	for _, leg := range trade.Legs {
+		if leg.IsOption() && leg.Expiration.After(last) {
+			last = leg.Expiration
+		}
+	}
+	return last
+}
@@ -0,0 +1,145 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// longCall is a call bought at 2 that expires on a date, risking $200
+func longCall(id string, entry, expiration time.Time) *models.Trade {
+	trade := &models.Trade{
+		ID:             id,
+		Symbol:         "AAPL",
+		Sector:         "Technology",
+		Strategy:       "Directional",
+		Type:           "Long Call",
+		EntryDate:      entry,
+		ExpirationDate: expiration,
+		EntryPrice:     2,
+	}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return trade
+}
+
+// Dates are stored at midnight UTC of their calendar day. A trade stays open through
+// its expiration day, and a trade entered today is open today, whatever the local time zone.
+func TestAggregateCalendarDays(t *testing.T) {
+	trades := []*models.Trade{
+		longCall("expiring", day("2026-10-05"), day("2026-10-16")),
+		longCall("entered", day("2026-10-16"), day("2026-11-20")),
+	}
+	settings := &models.PositionSettings{AccountValue: 10000}
+
+	zones := []*time.Location{
+		time.UTC,
+		time.FixedZone("Los Angeles", -7*3600),
+		time.FixedZone("Tokyo", 9*3600),
+	}
+
+	tests := []struct {
+		name string
+		date string
+		hour int
+		open []string
+	}{
+		{"day before expiration", "2026-10-15", 12, []string{"expiring"}},
+		{"expiration day morning", "2026-10-16", 8, []string{"entered", "expiring"}},
+		{"expiration day evening", "2026-10-16", 20, []string{"entered", "expiring"}},
+		{"day after expiration", "2026-10-17", 8, []string{"entered"}},
+	}
+
+	for _, tt := range tests {
+		for _, loc := range zones {
+			t.Run(tt.name+"/"+loc.String(), func(t *testing.T) {
+				d := day(tt.date)
+				asOf := time.Date(d.Year(), d.Month(), d.Day(), tt.hour, 0, 0, 0, loc)
+				exposure := Aggregate(trades, nil, settings, asOf)
+
+				open := map[string]bool{}
+				for _, p := range exposure.Positions {
+					open[p.TradeID] = true
+				}
+				if len(open) != len(tt.open) {
+					t.Fatalf("open = %v, want %v", open, tt.open)
+				}
+				for _, id := range tt.open {
+					if !open[id] {
+						t.Errorf("open = %v, want %v", open, tt.open)
+					}
+				}
+				if want := 200 * float64(len(tt.open)); math.Abs(exposure.TotalExposure-want) > 1e-9 {
+					t.Errorf("total exposure = %v, want %v", exposure.TotalExposure, want)
+				}
+			})
+		}
+	}
+}
+
+// A close dated today ends the position today, whatever the local time zone
+func TestAggregateClosedToday(t *testing.T) {
+	trade := longCall("closed", day("2026-10-05"), day("2026-11-20"))
+	events := map[string][]*models.TradeEvent{
+		"closed": {{TradeID: "closed", Type: models.EventClose, Date: day("2026-10-16"), Price: 3, Quantity: 1}},
+	}
+
+	for _, loc := range []*time.Location{time.FixedZone("Los Angeles", -7*3600), time.FixedZone("Tokyo", 9*3600)} {
+		asOf := time.Date(2026, 10, 16, 8, 0, 0, 0, loc)
+		if exposure := Aggregate([]*models.Trade{trade}, events, nil, asOf); len(exposure.Positions) != 0 {
+			t.Errorf("%s: %d positions open, want none", loc, len(exposure.Positions))
+		}
+		asOf = time.Date(2026, 10, 15, 20, 0, 0, 0, loc)
+		if exposure := Aggregate([]*models.Trade{trade}, events, nil, asOf); len(exposure.Positions) != 1 {
+			t.Errorf("%s: %d positions open the day before the close, want 1", loc, len(exposure.Positions))
+		}
+	}
+}
+
+func TestExpirationWeek(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{day("2026-10-16"), "2026-W42"},
+		{day("2026-01-01"), "2026-W01"},
+		{day("2027-01-01"), "2026-W53"}, // A Friday in the last ISO week of 2026
+		{time.Time{}, "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := ExpirationWeek(tt.date); got != tt.want {
+			t.Errorf("ExpirationWeek(%v) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
+
+// Trades without an expiration are grouped under Unknown, after the dated weeks
+func TestAggregateWithoutExpiration(t *testing.T) {
+	trades := []*models.Trade{
+		longCall("dated", day("2026-10-05"), day("2026-11-20")),
+		longCall("undated", day("2026-10-05"), time.Time{}),
+	}
+	exposure := Aggregate(trades, nil, &models.PositionSettings{AccountValue: 10000}, day("2026-10-16"))
+
+	var weeks []string
+	for _, b := range exposure.ByExpirationWeek {
+		weeks = append(weeks, b.Key)
+	}
+	if len(weeks) != 2 || weeks[0] != "2026-W47" || weeks[1] != "Unknown" {
+		t.Errorf("expiration weeks = %v, want [2026-W47 Unknown]", weeks)
+	}
+	for _, p := range exposure.Positions {
+		if p.TradeID == "undated" && p.ExpirationWeek != "Unknown" {
+			t.Errorf("undated expiration week = %s, want Unknown", p.ExpirationWeek)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Defaults used when PayoffOptions leaves a field empty
+const (
+	DefaultPayoffPoints     = 101
+	DefaultPayoffVolatility = 0.30
+	DefaultPayoffRate       = 0.04
+
+	MaxPayoffPoints = 1001 // Larger requests are sampled at this many points
+)
+
+// ErrNoLegDetail is returned for trades that only have a summary leg
+var ErrNoLegDetail = errors.New("trade has no leg detail to build a payoff from")
+
+// PayoffOptions controls how a payoff is sampled
+type PayoffOptions struct {
+	Low        float64     `json:"low"`        // Lowest underlying price, defaults to 70% of the lowest strike
+	High       float64     `json:"high"`       // Highest underlying price, defaults to 130% of the highest strike
+	Points     int         `json:"points"`     // Number of samples, defaults to 101 and at most 1001 (strikes are always included)
+	Dates      []time.Time `json:"dates"`      // Dates before expiration to draw curves for
+	Volatility float64     `json:"volatility"` // Implied volatility for legs that are still open, defaults to 30%
+	Rate       *float64    `json:"rate"`       // Risk-free rate, defaults to 4% when nil so that 0 can be asked for
+	Dividend   float64     `json:"dividend"`   // Dividend yield
+}
+
+// PayoffPoint is the profit or loss of the trade at one underlying price
+type PayoffPoint struct {
+	Price float64 `json:"price"`
+	PL    float64 `json:"pl"`
+}
+
+// PayoffCurve is a sampled profit or loss curve on a given date
+type PayoffCurve struct {
+	Date   time.Time     `json:"date"`
+	Points []PayoffPoint `json:"points"`
+}
+
+// Payoff describes the shape of a trade
+type Payoff struct {
+	TradeID            string        `json:"tradeId"`
+	Expiration         PayoffCurve   `json:"expiration"` // At the first expiration among the legs
+	Curves             []PayoffCurve `json:"curves"`     // At each requested date before expiration
+	MaxProfit          float64       `json:"maxProfit"`
+	MaxProfitUnlimited bool          `json:"maxProfitUnlimited"`
+	MaxLoss            float64       `json:"maxLoss"` // Largest loss as a positive dollar amount
+	MaxLossUnlimited   bool          `json:"maxLossUnlimited"`
+	Breakevens         []float64     `json:"breakevens"` // Underlying prices where the expiration P&L crosses zero
+}
+
+// BuildPayoff samples the profit or loss of a trade's legs against the underlying price.
+// Legs that expire after the first expiration (calendars and diagonals) are valued with
+// Black-Scholes-Merton at that date, so their part of the curve depends on the volatility.
+func BuildPayoff(trade *models.Trade, opts PayoffOptions) (*Payoff, error) {
+	if !trade.HasLegDetail() || len(trade.Legs) == 0 {
+		return nil, ErrNoLegDetail
+	}
+
+	opts = payoffDefaults(trade, opts)
+	prices := samplePrices(trade, opts)
+
+	firstExpiration := time.Time{}
+	for _, leg := range trade.Legs {
+		if leg.IsOption() && (firstExpiration.IsZero() || leg.Expiration.Before(firstExpiration)) {
+			firstExpiration = leg.Expiration
+		}
+	}
+
+	payoff := &Payoff{
+		TradeID:    trade.ID,
+		Expiration: sampleCurve(trade, prices, firstExpiration, opts),
+		Curves:     []PayoffCurve{},
+		Breakevens: []float64{},
+	}
+
+	for _, date := range opts.Dates {
+		if !firstExpiration.IsZero() && !date.Before(firstExpiration) {
+			continue
+		}
+		payoff.Curves = append(payoff.Curves, sampleCurve(trade, prices, date, opts))
+	}
+
+	// The P&L at zero bounds the downside of the price range exactly
+	atZero := positionPL(trade, 0, firstExpiration, opts)
+	maxPL, minPL := atZero, atZero
+	for _, p := range payoff.Expiration.Points {
+		maxPL = math.Max(maxPL, p.PL)
+		minPL = math.Min(minPL, p.PL)
+	}
+
+	// Beyond the highest strike the P&L grows linearly with the net long calls and shares
+	slope := upsideSlope(trade)
+	payoff.MaxProfitUnlimited = slope > 0
+	payoff.MaxLossUnlimited = slope < 0
+	if !payoff.MaxProfitUnlimited {
+		payoff.MaxProfit = roundCents(maxPL)
+	}
+	if !payoff.MaxLossUnlimited && minPL < 0 {
+		payoff.MaxLoss = roundCents(-minPL)
+	}
+
+	points := payoff.Expiration.Points
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if a.PL == 0 {
+			payoff.Breakevens = appendBreakeven(payoff.Breakevens, a.Price)
+		} else if (a.PL < 0) != (b.PL < 0) && b.PL != 0 {
+			payoff.Breakevens = appendBreakeven(payoff.Breakevens, a.Price+(b.Price-a.Price)*(-a.PL)/(b.PL-a.PL))
+		}
+	}
+	if last := points[len(points)-1]; last.PL == 0 {
+		payoff.Breakevens = appendBreakeven(payoff.Breakevens, last.Price)
+	}
+
+	return payoff, nil
+}
+
+// positionPL returns the trade's profit or loss at an underlying price on a date.
+// Legs expiring on or before the date are worth their intrinsic value.
+func positionPL(trade *models.Trade, price float64, date time.Time, opts PayoffOptions) float64 {
+	total := 0.0
+	for _, leg := range trade.Legs {
+		sign := 1.0
+		if leg.Side == models.SideSell {
+			sign = -1.0
+		}
+
+		var value, multiplier float64
+		switch leg.Right {
+		case models.RightStock:
+			value, multiplier = price, 1
+		case models.RightCall, models.RightPut:
+			multiplier = models.ContractMultiplier
+			if !leg.Expiration.After(date) {
+				value = intrinsic(leg.Right, price, leg.Strike)
+			} else {
+				value = Price(Option{
+					Right:      leg.Right,
+					Spot:       price,
+					Strike:     leg.Strike,
+					Years:      YearsUntil(leg.Expiration, date),
+					Rate:       *opts.Rate,
+					Dividend:   opts.Dividend,
+					Volatility: opts.Volatility,
+				})
+			}
+		default:
+			continue
+		}
+
+		total += sign * (value - leg.FillPrice) * float64(leg.Quantity) * multiplier
+	}
+	return total
+}
+
+// sampleCurve evaluates the trade at every sample price on a date
+func sampleCurve(trade *models.Trade, prices []float64, date time.Time, opts PayoffOptions) PayoffCurve {
+	curve := PayoffCurve{Date: date, Points: make([]PayoffPoint, 0, len(prices))}
+	for _, price := range prices {
+		curve.Points = append(curve.Points, PayoffPoint{
+			Price: price,
+			PL:    positionPL(trade, price, date, opts),
+		})
+	}
+	return curve
+}
+
+// upsideSlope is the dollar change in P&L per dollar of underlying far above every strike
+func upsideSlope(trade *models.Trade) float64 {
+	slope := 0.0
+	for _, leg := range trade.Legs {
+		sign := 1.0
+		if leg.Side == models.SideSell {
+			sign = -1.0
+		}
+		switch leg.Right {
+		case models.RightCall:
+			slope += sign * float64(leg.Quantity*models.ContractMultiplier)
+		case models.RightStock:
+			slope += sign * float64(leg.Quantity)
+		}
+	}
+	return slope
+}
+
+// samplePrices returns evenly spaced prices between Low and High plus every strike
+func samplePrices(trade *models.Trade, opts PayoffOptions) []float64 {
+	prices := make([]float64, 0, opts.Points+len(trade.Legs))
+	step := (opts.High - opts.Low) / float64(opts.Points-1)
+	for i := 0; i < opts.Points; i++ {
+		prices = append(prices, opts.Low+step*float64(i))
+	}
+	for _, leg := range trade.Legs {
+		if leg.IsOption() && leg.Strike >= opts.Low && leg.Strike <= opts.High {
+			prices = append(prices, leg.Strike)
+		}
+	}
+
+	sort.Float64s(prices)
+	unique := prices[:0]
+	for i, p := range prices {
+		if i == 0 || p-unique[len(unique)-1] > 1e-9 {
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// payoffDefaults fills in unset options from the trade's strikes
+func payoffDefaults(trade *models.Trade, opts PayoffOptions) PayoffOptions {
+	lowStrike, highStrike := math.Inf(1), math.Inf(-1)
+	for _, leg := range trade.Legs {
+		strike := leg.Strike
+		if leg.Right == models.RightStock {
+			strike = leg.FillPrice
+		}
+		if strike > 0 {
+			lowStrike = math.Min(lowStrike, strike)
+			highStrike = math.Max(highStrike, strike)
+		}
+	}
+	if math.IsInf(lowStrike, 0) {
+		lowStrike, highStrike = 1, 1
+	}
+
+	if opts.Low <= 0 {
+		opts.Low = lowStrike * 0.7
+	}
+	if opts.High <= opts.Low {
+		opts.High = math.Max(highStrike*1.3, opts.Low+1)
+	}
+	if opts.Points < 2 {
+		opts.Points = DefaultPayoffPoints
+	}
+	opts.Points = min(opts.Points, MaxPayoffPoints)
+	if opts.Volatility <= 0 {
+		opts.Volatility = DefaultPayoffVolatility
+	}
+	if opts.Rate == nil {
+		rate := DefaultPayoffRate
+		opts.Rate = &rate
+	}
+	return opts
+}
+
+// intrinsic returns the exercise value of an option
+func intrinsic(right string, price, strike float64) float64 {
+	if right == models.RightCall {
+		return math.Max(price-strike, 0)
+	}
+	return math.Max(strike-price, 0)
+}
+
+// appendBreakeven adds a breakeven price unless it repeats the previous one
+func appendBreakeven(breakevens []float64, price float64) []float64 {
+	price = roundCents(price)
+	if n := len(breakevens); n > 0 && breakevens[n-1] == price {
+		return breakevens
+	}
+	return append(breakevens, price)
+}
+
+// roundCents rounds a dollar amount to the nearest cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,83 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// longCall is one 100 call bought for 5.00 that expires a year after the curve date
+func longCall() *models.Trade {
+	return &models.Trade{
+		ID: "call",
+		Legs: []models.Leg{{
+			Right: models.RightCall, Side: models.SideBuy, Quantity: 1, Strike: 100, FillPrice: 5,
+			Expiration: time.Date(2027, 10, 15, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+}
+
+func TestBuildPayoffRate(t *testing.T) {
+	date := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	zero := 0.0
+
+	tests := []struct {
+		name string
+		rate *float64
+		want float64
+	}{
+		{"default rate", nil, DefaultPayoffRate},
+		{"zero rate", &zero, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade := longCall()
+			payoff, err := BuildPayoff(trade, PayoffOptions{
+				Low: 50, High: 150, Dates: []time.Time{date}, Volatility: 0.2, Rate: tt.rate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(payoff.Curves) != 1 {
+				t.Fatalf("got %d curves, want 1", len(payoff.Curves))
+			}
+
+			value := Price(Option{
+				Right: models.RightCall, Spot: 100, Strike: 100,
+				Years: YearsUntil(trade.Legs[0].Expiration, date), Rate: tt.want, Volatility: 0.2,
+			})
+			for _, p := range payoff.Curves[0].Points {
+				if p.Price == 100 {
+					near(t, "PL at 100", p.PL, (value-5)*models.ContractMultiplier, 1e-6)
+					return
+				}
+			}
+			t.Error("curve has no point at 100")
+		})
+	}
+}
+
+func TestBuildPayoffPoints(t *testing.T) {
+	tests := []struct {
+		points int
+		want   int
+	}{
+		{0, DefaultPayoffPoints},
+		{11, 11},
+		{MaxPayoffPoints, MaxPayoffPoints},
+		{10_000_000, MaxPayoffPoints},
+	}
+
+	for _, tt := range tests {
+		// The strike is one of the evenly spaced prices, so it adds no point
+		payoff, err := BuildPayoff(longCall(), PayoffOptions{Low: 50, High: 150, Points: tt.points})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(payoff.Expiration.Points); got != tt.want {
+			t.Errorf("Points %d: sampled %d prices, want %d", tt.points, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Market holds the user-supplied inputs used to value a position
+type Market struct {
+	Spot       float64   `json:"spot"`       // Underlying price
+	Volatility float64   `json:"volatility"` // Implied volatility (0.25 = 25%)
+	Rate       float64   `json:"rate"`       // Risk-free rate (0.05 = 5%)
+	Dividend   float64   `json:"dividend"`   // Dividend yield (0.01 = 1%)
+	AsOf       time.Time `json:"asOf"`       // Valuation date, defaults to now
+}
+
+// LegGreeks is the value and Greeks of one leg, in dollars for the whole leg
+type LegGreeks struct {
+	Leg    models.Leg `json:"leg"`
+	Years  float64    `json:"years"`  // Time to expiration used for the leg
+	Greeks Greeks     `json:"greeks"` // Per-share Greeks of a single contract
+	Total  Greeks     `json:"total"`  // Position Greeks: signed by side and scaled by quantity and multiplier
+}
+
+// TradeGreeks is the value and Greeks of a whole trade
+type TradeGreeks struct {
+	TradeID string      `json:"tradeId"`
+	Market  Market      `json:"market"`
+	Legs    []LegGreeks `json:"legs"`
+	Total   Greeks      `json:"total"`
+}
+
+// YearsUntil returns the time from asOf to the expiration date in years
+func YearsUntil(expiration, asOf time.Time) float64 {
+	years := expiration.Sub(asOf).Hours() / 24 / 365
+	if years < 0 {
+		return 0
+	}
+	return years
+}
+
+// ForLeg values a single leg. Summary legs without a right have no Greeks.
+func ForLeg(leg models.Leg, m Market) LegGreeks {
+	m = withDefaults(m)
+	result := LegGreeks{Leg: leg}
+
+	sign := 1.0
+	if leg.Side == models.SideSell {
+		sign = -1.0
+	}
+
+	switch leg.Right {
+	case models.RightStock:
+		result.Greeks = Greeks{Price: m.Spot, Delta: 1}
+		result.Total = scale(result.Greeks, sign*float64(leg.Quantity))
+	case models.RightCall, models.RightPut:
+		result.Years = YearsUntil(leg.Expiration, m.AsOf)
+		result.Greeks = Calculate(Option{
+			Right:      leg.Right,
+			Spot:       m.Spot,
+			Strike:     leg.Strike,
+			Years:      result.Years,
+			Rate:       m.Rate,
+			Dividend:   m.Dividend,
+			Volatility: m.Volatility,
+		})
+		result.Total = scale(result.Greeks, sign*float64(leg.Quantity*models.ContractMultiplier))
+	}
+
+	return result
+}
+
+// ForTrade values every leg of a trade and sums them
+func ForTrade(trade *models.Trade, m Market) TradeGreeks {
+	m = withDefaults(m)
+	result := TradeGreeks{
+		TradeID: trade.ID,
+		Market:  m,
+		Legs:    make([]LegGreeks, 0, len(trade.Legs)),
+	}
+
+	for _, leg := range trade.Legs {
+		lg := ForLeg(leg, m)
+		result.Legs = append(result.Legs, lg)
+		result.Total = add(result.Total, lg.Total)
+	}
+
+	return result
+}
+
+// withDefaults fills in the valuation date
+func withDefaults(m Market) Market {
+	if m.AsOf.IsZero() {
+		m.AsOf = time.Now()
+	}
+	return m
+}
+
+// scale multiplies every Greek by factor
+func scale(g Greeks, factor float64) Greeks {
+	return Greeks{
+		Price: g.Price * factor,
+		Delta: g.Delta * factor,
+		Gamma: g.Gamma * factor,
+		Theta: g.Theta * factor,
+		Vega:  g.Vega * factor,
+		Rho:   g.Rho * factor,
+	}
+}
+
+// add sums two sets of Greeks
+func add(a, b Greeks) Greeks {
+	return Greeks{
+		Price: a.Price + b.Price,
+		Delta: a.Delta + b.Delta,
+		Gamma: a.Gamma + b.Gamma,
+		Theta: a.Theta + b.Theta,
+		Vega:  a.Vega + b.Vega,
+		Rho:   a.Rho + b.Rho,
+	}
+}
@@ -0,0 +1,154 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Option describes a European option for Black-Scholes-Merton pricing
+type Option struct {
+	Right      string  `json:"right"`      // models.RightCall or models.RightPut
+	Spot       float64 `json:"spot"`       // Underlying price
+	Strike     float64 `json:"strike"`     // Strike price
+	Years      float64 `json:"years"`      // Time to expiration in years
+	Rate       float64 `json:"rate"`       // Continuously compounded risk-free rate (0.05 = 5%)
+	Dividend   float64 `json:"dividend"`   // Continuous dividend yield (0.01 = 1%)
+	Volatility float64 `json:"volatility"` // Annualized volatility (0.25 = 25%)
+}
+
+// Greeks holds an option's value and sensitivities.
+// Theta is per calendar day, vega per volatility point and rho per rate point.
+type Greeks struct {
+	Price float64 `json:"price"`
+	Delta float64 `json:"delta"`
+	Gamma float64 `json:"gamma"`
+	Theta float64 `json:"theta"`
+	Vega  float64 `json:"vega"`
+	Rho   float64 `json:"rho"`
+}
+
+// ErrNoConvergence is returned when the implied volatility solver cannot find a solution
+var ErrNoConvergence = errors.New("implied volatility did not converge")
+
+// ErrPriceOutOfBounds is returned when a price is outside the no-arbitrage bounds
+var ErrPriceOutOfBounds = errors.New("price is outside no-arbitrage bounds")
+
+// Price returns the Black-Scholes-Merton value of an option
+func Price(o Option) float64 {
+	return Calculate(o).Price
+}
+
+// Calculate returns the Black-Scholes-Merton value and Greeks of an option.
+// At or after expiration (or with zero volatility) the option is worth its
+// discounted intrinsic value and only delta is non-zero.
+func Calculate(o Option) Greeks {
+	isCall := o.Right == models.RightCall
+	dfRate := math.Exp(-o.Rate * o.Years)
+	dfDiv := math.Exp(-o.Dividend * o.Years)
+
+	if o.Years <= 0 || o.Volatility <= 0 {
+		forward := o.Spot * dfDiv
+		strike := o.Strike * dfRate
+		g := Greeks{}
+		if isCall && forward > strike {
+			g.Price = forward - strike
+			g.Delta = dfDiv
+		} else if !isCall && strike > forward {
+			g.Price = strike - forward
+			g.Delta = -dfDiv
+		}
+		return g
+	}
+
+	sqrtT := math.Sqrt(o.Years)
+	d1 := (math.Log(o.Spot/o.Strike) + (o.Rate-o.Dividend+0.5*o.Volatility*o.Volatility)*o.Years) / (o.Volatility * sqrtT)
+	d2 := d1 - o.Volatility*sqrtT
+
+	pdf := normPDF(d1)
+	g := Greeks{
+		Gamma: dfDiv * pdf / (o.Spot * o.Volatility * sqrtT),
+		Vega:  o.Spot * dfDiv * pdf * sqrtT / 100,
+	}
+
+	decay := -o.Spot * dfDiv * pdf * o.Volatility / (2 * sqrtT)
+	if isCall {
+		g.Price = o.Spot*dfDiv*normCDF(d1) - o.Strike*dfRate*normCDF(d2)
+		g.Delta = dfDiv * normCDF(d1)
+		g.Theta = (decay - o.Rate*o.Strike*dfRate*normCDF(d2) + o.Dividend*o.Spot*dfDiv*normCDF(d1)) / 365
+		g.Rho = o.Strike * o.Years * dfRate * normCDF(d2) / 100
+	} else {
+		g.Price = o.Strike*dfRate*normCDF(-d2) - o.Spot*dfDiv*normCDF(-d1)
+		g.Delta = -dfDiv * normCDF(-d1)
+		g.Theta = (decay + o.Rate*o.Strike*dfRate*normCDF(-d2) - o.Dividend*o.Spot*dfDiv*normCDF(-d1)) / 365
+		g.Rho = -o.Strike * o.Years * dfRate * normCDF(-d2) / 100
+	}
+
+	return g
+}
+
+// ImpliedVolatility finds the volatility at which the option is worth price.
+// The Volatility field of o is ignored. Newton-Raphson is used while vega is
+// meaningful, falling back to bisection.
+func ImpliedVolatility(price float64, o Option) (float64, error) {
+	if o.Years <= 0 {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	dfRate := math.Exp(-o.Rate * o.Years)
+	dfDiv := math.Exp(-o.Dividend * o.Years)
+	var lower, upper float64
+	if o.Right == models.RightCall {
+		lower = math.Max(0, o.Spot*dfDiv-o.Strike*dfRate)
+		upper = o.Spot * dfDiv
+	} else {
+		lower = math.Max(0, o.Strike*dfRate-o.Spot*dfDiv)
+		upper = o.Strike * dfRate
+	}
+	if price < lower || price >= upper {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	const (
+		tolerance     = 1e-8
+		maxIterations = 100
+	)
+
+	lo, hi := 1e-6, 5.0
+	vol := 0.3
+	for i := 0; i < maxIterations; i++ {
+		o.Volatility = vol
+		g := Calculate(o)
+		diff := g.Price - price
+		if math.Abs(diff) < tolerance {
+			return vol, nil
+		}
+
+		// Keep a bracket around the root for the bisection fallback
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+
+		vega := g.Vega * 100
+		next := vol - diff/vega
+		if vega < 1e-10 || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		vol = next
+	}
+
+	return 0, ErrNoConvergence
+}
+
+// normCDF is the standard normal cumulative distribution function
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normPDF is the standard normal probability density function
+func normPDF(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+}
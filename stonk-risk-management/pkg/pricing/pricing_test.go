@@ -0,0 +1,147 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func near(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.6f, want %.6f", name, got, want)
+	}
+}
+
+// atTheMoney is the standard one-year at-the-money example: S = K = 100, r = 5%, σ = 20%
+func atTheMoney(right string) Option {
+	return Option{Right: right, Spot: 100, Strike: 100, Years: 1, Rate: 0.05, Volatility: 0.2}
+}
+
+func TestPriceReferenceValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		option Option
+		want   float64
+	}{
+		{"at-the-money call", atTheMoney(models.RightCall), 10.4506},
+		{"at-the-money put", atTheMoney(models.RightPut), 5.5735},
+		// Hull, Options, Futures and Other Derivatives: S = 42, K = 40, r = 10%, σ = 20%, six months
+		{"Hull call", Option{Right: models.RightCall, Spot: 42, Strike: 40, Years: 0.5, Rate: 0.1, Volatility: 0.2}, 4.7594},
+		{"Hull put", Option{Right: models.RightPut, Spot: 42, Strike: 40, Years: 0.5, Rate: 0.1, Volatility: 0.2}, 0.8086},
+		{"call with dividend yield", Option{Right: models.RightCall, Spot: 100, Strike: 95, Years: 0.5, Rate: 0.03, Dividend: 0.02, Volatility: 0.3}, 11.1273},
+		{"put with dividend yield", Option{Right: models.RightPut, Spot: 100, Strike: 95, Years: 0.5, Rate: 0.03, Dividend: 0.02, Volatility: 0.3}, 5.7080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			near(t, "Price", Price(tt.option), tt.want, 1e-4)
+		})
+	}
+}
+
+func TestGreeksReferenceValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		option Option
+		want   Greeks
+	}{
+		{"call", atTheMoney(models.RightCall), Greeks{
+			Price: 10.450584, Delta: 0.636831, Gamma: 0.018762, Theta: -0.017573, Vega: 0.375240, Rho: 0.532325,
+		}},
+		{"put", atTheMoney(models.RightPut), Greeks{
+			Price: 5.573526, Delta: -0.363169, Gamma: 0.018762, Theta: -0.004542, Vega: 0.375240, Rho: -0.418905,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Calculate(tt.option)
+			near(t, "Price", g.Price, tt.want.Price, 1e-6)
+			near(t, "Delta", g.Delta, tt.want.Delta, 1e-6)
+			near(t, "Gamma", g.Gamma, tt.want.Gamma, 1e-6)
+			near(t, "Theta", g.Theta, tt.want.Theta, 1e-6)
+			near(t, "Vega", g.Vega, tt.want.Vega, 1e-6)
+			near(t, "Rho", g.Rho, tt.want.Rho, 1e-6)
+		})
+	}
+}
+
+func TestPutCallParity(t *testing.T) {
+	options := []Option{
+		atTheMoney(""),
+		{Spot: 42, Strike: 40, Years: 0.5, Rate: 0.1, Volatility: 0.2},
+		{Spot: 100, Strike: 95, Years: 0.5, Rate: 0.03, Dividend: 0.02, Volatility: 0.3},
+		{Spot: 50, Strike: 80, Years: 2, Rate: 0.01, Dividend: 0.04, Volatility: 0.6},
+	}
+
+	for _, o := range options {
+		call, put := o, o
+		call.Right, put.Right = models.RightCall, models.RightPut
+
+		// C - P = S·e^(-qT) - K·e^(-rT)
+		want := o.Spot*math.Exp(-o.Dividend*o.Years) - o.Strike*math.Exp(-o.Rate*o.Years)
+		near(t, "C - P", Price(call)-Price(put), want, 1e-9)
+
+		// Deltas differ by e^(-qT); gamma and vega match
+		cg, pg := Calculate(call), Calculate(put)
+		near(t, "call delta - put delta", cg.Delta-pg.Delta, math.Exp(-o.Dividend*o.Years), 1e-9)
+		near(t, "gamma", cg.Gamma, pg.Gamma, 1e-12)
+		near(t, "vega", cg.Vega, pg.Vega, 1e-12)
+	}
+}
+
+func TestExpiredOptionIsIntrinsic(t *testing.T) {
+	call := Option{Right: models.RightCall, Spot: 110, Strike: 100, Volatility: 0.2}
+	put := Option{Right: models.RightPut, Spot: 110, Strike: 100, Volatility: 0.2}
+
+	if g := Calculate(call); g.Price != 10 || g.Delta != 1 || g.Gamma != 0 {
+		t.Errorf("expired call = %+v, want price 10 and delta 1", g)
+	}
+	if g := Calculate(put); g.Price != 0 || g.Delta != 0 {
+		t.Errorf("expired put = %+v, want worthless", g)
+	}
+}
+
+func TestImpliedVolatilityRoundTrip(t *testing.T) {
+	for _, right := range []string{models.RightCall, models.RightPut} {
+		for _, vol := range []float64{0.05, 0.2, 0.45, 1.5} {
+			o := atTheMoney(right)
+			o.Volatility = vol
+			price := Price(o)
+
+			got, err := ImpliedVolatility(price, o)
+			if err != nil {
+				t.Fatalf("%s at %.2f: %v", right, vol, err)
+			}
+			near(t, right+" implied volatility", got, vol, 1e-6)
+		}
+	}
+}
+
+func TestImpliedVolatilityErrors(t *testing.T) {
+	call := atTheMoney(models.RightCall)
+
+	tests := []struct {
+		name   string
+		price  float64
+		option Option
+		want   error
+	}{
+		{"below intrinsic value", 1, Option{Right: models.RightCall, Spot: 120, Strike: 100, Years: 1, Rate: 0.05}, ErrPriceOutOfBounds},
+		{"at the underlying price", 100, call, ErrPriceOutOfBounds},
+		{"expired", 10, Option{Right: models.RightCall, Spot: 100, Strike: 100}, ErrPriceOutOfBounds},
+		// Within the bounds, but only a volatility far above the solver's 500% ceiling fits
+		{"needs an extreme volatility", 99.9, call, ErrNoConvergence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ImpliedVolatility(tt.price, tt.option); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
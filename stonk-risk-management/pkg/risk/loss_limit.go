@@ -0,0 +1,139 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// ErrLossLimitLocked is wrapped by the error of a locked LossLimitStatus
+var ErrLossLimitLocked = errors.New("trading locked by loss limits")
+
+// PeriodLoss is the P&L of one loss-limit period compared to its limit
+type PeriodLoss struct {
+	Period       string                    `json:"period"`       // daily or weekly
+	Key          string                    `json:"key"`          // Day ("2025-01-10") or ISO week ("2025-W02")
+	Start        time.Time                 `json:"start"`        // Start of the period (inclusive)
+	End          time.Time                 `json:"end"`          // Start of the next period, when the lock lifts
+	RealizedPL   float64                   `json:"realizedPL"`   // P&L realized by events in the period
+	MarkedPL     float64                   `json:"markedPL"`     // Unrealized P&L of marked open trades
+	Loss         float64                   `json:"loss"`         // Net loss in dollars, zero when the period is profitable
+	LossPercent  float64                   `json:"lossPercent"`  // Loss as a percent of account value
+	LimitPercent float64                   `json:"limitPercent"` // Limit as a percent of account value
+	Limit        float64                   `json:"limit"`        // Limit in dollars
+	Breached     bool                      `json:"breached"`
+	Override     *models.LossLimitOverride `json:"override"` // Override lifting the lock, if any
+}
+
+// Locked reports whether the period is breached without an override
+func (p *PeriodLoss) Locked() bool {
+	return p.Breached && p.Override == nil
+}
+
+// LossLimitStatus is the state of the daily and weekly loss-limit circuit breaker
+type LossLimitStatus struct {
+	AsOf   time.Time  `json:"asOf"`
+	Daily  PeriodLoss `json:"daily"`
+	Weekly PeriodLoss `json:"weekly"`
+	Locked bool       `json:"locked"` // New opening trades are refused while locked
+}
+
+// DayPeriod returns the key and bounds of the trading day containing t.
+//
+// Trade and event dates are stored as calendar dates at midnight UTC (the UI and orm
+// write "2025-01-10T00:00:00Z" for the 10th), so the bounds are the calendar day of t
+// in t's own location, expressed in UTC. Comparing against bounds in t's location would
+// put today's events before the start of the day for anyone west of UTC.
+func DayPeriod(t time.Time) (key string, start, end time.Time) {
+	year, month, day := t.Date()
+	start = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01-02"), start, start.AddDate(0, 0, 1)
+}
+
+// WeekPeriod returns the key and bounds of the ISO week (Monday to Sunday) containing t,
+// as calendar dates in UTC like DayPeriod
+func WeekPeriod(t time.Time) (key string, start, end time.Time) {
+	_, day, _ := DayPeriod(t)
+	offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	start = day.AddDate(0, 0, -offset)
+	year, week := day.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week), start, start.AddDate(0, 0, 7)
+}
+
+// EvaluateLossLimits sums the P&L realized in the current day and ISO week and
+// compares the losses to DailyLossLimit and WeeklyLossLimit (percent of account
+// value). Marks are optional per-unit prices of open trades by trade ID; marked
+// trades add their unrealized P&L to both periods. Overrides are looked up by
+// period and key.
+func EvaluateLossLimits(histories []*models.TradeHistory, marks map[string]float64, settings *models.PositionSettings,
+	overrides func(period, key string) *models.LossLimitOverride, asOf time.Time) LossLimitStatus {
+	status := LossLimitStatus{AsOf: asOf}
+
+	dayKey, dayStart, dayEnd := DayPeriod(asOf)
+	weekKey, weekStart, weekEnd := WeekPeriod(asOf)
+	status.Daily = PeriodLoss{Period: models.PeriodDaily, Key: dayKey, Start: dayStart, End: dayEnd}
+	status.Weekly = PeriodLoss{Period: models.PeriodWeekly, Key: weekKey, Start: weekStart, End: weekEnd}
+
+	marked := 0.0
+	for _, h := range histories {
+		status.Daily.RealizedPL += h.RealizedBetween(dayStart, dayEnd)
+		status.Weekly.RealizedPL += h.RealizedBetween(weekStart, weekEnd)
+		if mark, ok := marks[h.Trade.ID]; ok && h.OpenQuantity > 0 {
+			marked += h.UnrealizedPL(mark)
+		}
+	}
+	status.Daily.MarkedPL = marked
+	status.Weekly.MarkedPL = marked
+
+	accountValue := 0.0
+	if settings != nil {
+		accountValue = settings.AccountValue
+		status.Daily.LimitPercent = settings.DailyLossLimit
+		status.Weekly.LimitPercent = settings.WeeklyLossLimit
+	}
+
+	for _, p := range []*PeriodLoss{&status.Daily, &status.Weekly} {
+		p.apply(accountValue)
+		if p.Breached && overrides != nil {
+			p.Override = overrides(p.Period, p.Key)
+		}
+		if p.Locked() {
+			status.Locked = true
+		}
+	}
+
+	return status
+}
+
+// Err returns an error describing the breached limits, or nil if trading is allowed
+func (s *LossLimitStatus) Err() error {
+	if !s.Locked {
+		return nil
+	}
+
+	var messages []string
+	for _, p := range []PeriodLoss{s.Daily, s.Weekly} {
+		if p.Locked() {
+			messages = append(messages, fmt.Sprintf("%s loss $%.2f (%.1f%%) exceeds the %.1f%% limit until %s",
+				p.Period, p.Loss, p.LossPercent, p.LimitPercent, p.End.Format("2006-01-02")))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrLossLimitLocked, strings.Join(messages, "; "))
+}
+
+// apply computes the loss and compares it to the limit
+func (p *PeriodLoss) apply(accountValue float64) {
+	if net := p.RealizedPL + p.MarkedPL; net < 0 {
+		p.Loss = -net
+	}
+	if accountValue <= 0 {
+		return
+	}
+
+	p.LossPercent = p.Loss / accountValue * 100
+	p.Limit = accountValue * p.LimitPercent / 100
+	p.Breached = p.LimitPercent > 0 && p.Loss >= p.Limit
+}
@@ -0,0 +1,88 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// losingTrade is a long call bought at 5 and sold at 1 on the given date, a $400 loss
+func losingTrade(id string, exit time.Time) *models.TradeHistory {
+	trade := &models.Trade{ID: id, Type: "Long Call", EntryPrice: 5, EntryDate: exit}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return models.NewTradeHistory(trade, []*models.TradeEvent{
+		{ID: id + "-close", TradeID: id, Type: models.EventClose, Date: exit, Price: 1, Quantity: 1},
+	})
+}
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Event dates are stored at midnight UTC of their calendar day, as the UI and orm
+// write them. The loss limits must see today's loss whatever the local time zone.
+func TestEvaluateLossLimitsTimeZones(t *testing.T) {
+	settings := &models.PositionSettings{AccountValue: 10000, DailyLossLimit: 3, WeeklyLossLimit: 6}
+	histories := []*models.TradeHistory{
+		losingTrade("monday", day("2026-10-12")),
+		losingTrade("sunday", day("2026-10-11")), // The previous ISO week
+	}
+
+	zones := []*time.Location{
+		time.UTC,
+		time.FixedZone("Los Angeles", -7*3600),
+		time.FixedZone("New York", -4*3600),
+		time.FixedZone("Tokyo", 9*3600),
+		time.FixedZone("Auckland", 13*3600),
+	}
+	for _, zone := range zones {
+		for _, hour := range []int{0, 9, 23} {
+			asOf := time.Date(2026, 10, 12, hour, 30, 0, 0, zone)
+			t.Run(asOf.Format("15:04 MST"), func(t *testing.T) {
+				status := EvaluateLossLimits(histories, nil, settings, nil, asOf)
+
+				if status.Daily.Key != "2026-10-12" || status.Weekly.Key != "2026-W42" {
+					t.Fatalf("keys = %s, %s, want 2026-10-12, 2026-W42", status.Daily.Key, status.Weekly.Key)
+				}
+				if status.Daily.RealizedPL != -400 {
+					t.Errorf("daily realized = %v, want -400", status.Daily.RealizedPL)
+				}
+				if status.Weekly.RealizedPL != -400 {
+					t.Errorf("weekly realized = %v, want -400 (Sunday belongs to the previous week)", status.Weekly.RealizedPL)
+				}
+				if !status.Daily.Breached || status.Weekly.Breached || !status.Locked {
+					t.Errorf("daily breached = %v, weekly breached = %v, locked = %v, want true, false, true",
+						status.Daily.Breached, status.Weekly.Breached, status.Locked)
+				}
+				if !status.Daily.End.Equal(day("2026-10-13")) {
+					t.Errorf("daily end = %v, want 2026-10-13", status.Daily.End)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluateLossLimitsOverride(t *testing.T) {
+	settings := &models.PositionSettings{AccountValue: 10000, DailyLossLimit: 3, WeeklyLossLimit: 6}
+	histories := []*models.TradeHistory{losingTrade("t1", day("2026-10-14"))}
+
+	overrides := func(period, key string) *models.LossLimitOverride {
+		if period == models.PeriodDaily && key == "2026-10-14" {
+			return &models.LossLimitOverride{Period: period, PeriodKey: key, Reason: "planned hedge"}
+		}
+		return nil
+	}
+
+	status := EvaluateLossLimits(histories, nil, settings, overrides, time.Date(2026, 10, 14, 15, 0, 0, 0, time.Local))
+	if !status.Daily.Breached || status.Daily.Override == nil || status.Locked {
+		t.Fatalf("breached = %v, override = %v, locked = %v, want an overridden breach", status.Daily.Breached, status.Daily.Override, status.Locked)
+	}
+	if err := status.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
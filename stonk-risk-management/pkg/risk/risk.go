@@ -0,0 +1,160 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/pricing"
+)
+
+// Enforcement modes for PositionSettings.RiskEnforcement
+const (
+	EnforcementOff   = "off"   // Violations are not reported
+	EnforcementWarn  = "warn"  // Violations are reported but the trade is saved
+	EnforcementBlock = "block" // Trades over budget are refused
+)
+
+// Violation severities
+const (
+	SeverityWarn  = "warn"
+	SeverityBlock = "block"
+)
+
+// Violation codes
+const (
+	CodeOverBudget    = "over_budget"    // Max loss exceeds the per-trade risk budget
+	CodeUnlimitedRisk = "unlimited_risk" // The legs have no defined max loss
+	CodeUndefinedRisk = "undefined_risk" // Not enough information to compute a max loss
+)
+
+// Sources of a trade's max loss
+const (
+	SourceLegs    = "legs"    // From the payoff of the trade's legs
+	SourceStop    = "stop"    // From the distance between Entry and Stop
+	SourcePremium = "premium" // From the debit paid on a trade without leg detail
+	SourceUnknown = "unknown"
+)
+
+// Violation is a single breach of the risk rules
+type Violation struct {
+	Code     string  `json:"code"`
+	Severity string  `json:"severity"`
+	Message  string  `json:"message"`
+	Limit    float64 `json:"limit"`
+	Actual   float64 `json:"actual"`
+}
+
+// Check is the result of checking a trade against the position settings
+type Check struct {
+	MaxLoss            float64     `json:"maxLoss"`
+	MaxLossUnlimited   bool        `json:"maxLossUnlimited"`
+	MaxLossSource      string      `json:"maxLossSource"`
+	BaseBudget         float64     `json:"baseBudget"`         // Account value times risk per trade
+	RecommendedPercent int         `json:"recommendedPercent"` // Recommended position size used to scale the budget
+	Budget             float64     `json:"budget"`             // BaseBudget scaled by RecommendedPercent
+	Violations         []Violation `json:"violations"`
+	Blocked            bool        `json:"blocked"`
+}
+
+// MaxLoss computes the defined max loss of a trade in dollars.
+// Legs take precedence, then the Entry/Stop distance, then the debit paid.
+func MaxLoss(trade *models.Trade) (amount float64, unlimited bool, source string) {
+	if trade.HasLegDetail() && len(trade.Legs) > 0 {
+		if payoff, err := pricing.BuildPayoff(trade, pricing.PayoffOptions{}); err == nil {
+			return payoff.MaxLoss, payoff.MaxLossUnlimited, SourceLegs
+		}
+	}
+
+	units := float64(trade.Quantity())
+	if trade.Entry > 0 && trade.Stop > 0 {
+		return math.Abs(trade.Entry-trade.Stop) * units * models.ContractMultiplier, false, SourceStop
+	}
+
+	if entry := trade.EntryValue(); entry > 0 {
+		return entry * units * models.ContractMultiplier, false, SourcePremium
+	}
+
+	return 0, false, SourceUnknown
+}
+
+// Evaluate checks a trade's max loss against the per-trade risk budget, scaled
+// by the currently recommended position size (percent of max)
+func Evaluate(trade *models.Trade, settings *models.PositionSettings, recommendedPercent int) Check {
+	check := Check{
+		RecommendedPercent: recommendedPercent,
+		Violations:         []Violation{},
+	}
+	check.MaxLoss, check.MaxLossUnlimited, check.MaxLossSource = MaxLoss(trade)
+
+	if settings == nil {
+		return check
+	}
+	check.BaseBudget = settings.AccountValue * (settings.AccountRiskPerTrade / 100)
+	check.Budget = check.BaseBudget * float64(recommendedPercent) / 100
+
+	enforcement := settings.RiskEnforcement
+	if enforcement == "" {
+		enforcement = EnforcementWarn
+	}
+	if enforcement == EnforcementOff {
+		return check
+	}
+
+	severity := SeverityWarn
+	if enforcement == EnforcementBlock {
+		severity = SeverityBlock
+	}
+
+	switch {
+	case check.MaxLossUnlimited:
+		check.add(Violation{
+			Code:     CodeUnlimitedRisk,
+			Severity: severity,
+			Message:  fmt.Sprintf("%s has unlimited risk; the per-trade budget is $%.2f", trade.Type, check.Budget),
+			Limit:    check.Budget,
+		})
+	case check.MaxLossSource == SourceUnknown:
+		check.add(Violation{
+			Code:     CodeUndefinedRisk,
+			Severity: SeverityWarn,
+			Message:  "Max loss could not be determined; enter legs or an entry and stop",
+			Limit:    check.Budget,
+		})
+	case check.MaxLoss > check.Budget:
+		check.add(Violation{
+			Code:     CodeOverBudget,
+			Severity: severity,
+			Message: fmt.Sprintf("Max loss $%.2f exceeds the $%.2f budget (%d%% of $%.2f per trade)",
+				check.MaxLoss, check.Budget, recommendedPercent, check.BaseBudget),
+			Limit:  check.Budget,
+			Actual: check.MaxLoss,
+		})
+	}
+
+	return check
+}
+
+// Err returns an error describing the blocking violations, or nil if the trade may be saved
+func (c *Check) Err() error {
+	if !c.Blocked {
+		return nil
+	}
+
+	var messages []string
+	for _, v := range c.Violations {
+		if v.Severity == SeverityBlock {
+			messages = append(messages, v.Message)
+		}
+	}
+	return fmt.Errorf("trade blocked by risk limits: %s", strings.Join(messages, "; "))
+}
+
+// add records a violation and marks the check blocked if needed
+func (c *Check) add(v Violation) {
+	c.Violations = append(c.Violations, v)
+	if v.Severity == SeverityBlock {
+		c.Blocked = true
+	}
+}
@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"sort"
+	"time"
+
+	"stonk-risk-management/pkg/equity"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/portfolio"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/sizing"
+)
+
+// Variable types
+const (
+	TypeNumber = "number"
+	TypeBool   = "boolean"
+)
+
+// Variable is a value rule expressions can refer to
+type Variable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // number or boolean
+	Description string `json:"description"`
+}
+
+func (v Variable) kind() kind {
+	if v.Type == TypeBool {
+		return kindBool
+	}
+	return kindNumber
+}
+
+// variables lists every variable of the environment
+var variables = []Variable{
+	{"assessment.recorded", TypeBool, "A risk assessment was recorded today"},
+	{"assessment.emotional", TypeNumber, "Emotional state of today's assessment, -3 to +3"},
+	{"assessment.fomo", TypeNumber, "Fear of missing out of today's assessment, -3 to +3"},
+	{"assessment.bias", TypeNumber, "Market bias of today's assessment, -3 to +3"},
+	{"assessment.physical", TypeNumber, "Physical condition of today's assessment, -3 to +3"},
+	{"assessment.plImpact", TypeNumber, "Impact of recent P&L in today's assessment, -3 to +3"},
+	{"assessment.other", TypeNumber, "Other factors of today's assessment, -3 to +3"},
+	{"assessment.overall", TypeNumber, "Overall score of today's assessment, -3 to +3"},
+	{"sizing.percent", TypeNumber, "Recommended position size in percent, 100 without an assessment"},
+	{"sizing.extremeCount", TypeNumber, "Assessment dimensions at an extreme score"},
+	{"settings.accountValue", TypeNumber, "Account value in dollars"},
+	{"settings.riskPerTrade", TypeNumber, "Account risk per trade in percent"},
+	{"settings.dailyLossLimit", TypeNumber, "Daily loss limit in percent of account value"},
+	{"settings.weeklyLossLimit", TypeNumber, "Weekly loss limit in percent of account value"},
+	{"pnl.today", TypeNumber, "P&L realized today in dollars"},
+	{"pnl.week", TypeNumber, "P&L realized this week in dollars"},
+	{"pnl.todayPercent", TypeNumber, "P&L realized today in percent of account value"},
+	{"pnl.weekPercent", TypeNumber, "P&L realized this week in percent of account value"},
+	{"trades.open", TypeNumber, "Trades currently open"},
+	{"trades.openedToday", TypeNumber, "Trades entered today"},
+	{"trades.closedToday", TypeNumber, "Trades with an exit today"},
+	{"trades.winningToday", TypeNumber, "Trades whose exits today made money"},
+	{"trades.losingToday", TypeNumber, "Trades whose exits today lost money"},
+	{"trades.losingStreak", TypeNumber, "Most recently closed trades that lost money in a row"},
+	{"lastLoss.recorded", TypeBool, "A trade exit has lost money"},
+	{"lastEntry.afterLoss", TypeBool, "A trade was entered today on the day another trade's exit lost money"},
+	{"exposure.percent", TypeNumber, "Open max loss in percent of account value"},
+	{"exposure.overLimit", TypeBool, "Open exposure exceeds the portfolio limit"},
+	{"drawdown.percent", TypeNumber, "Current drawdown from the equity peak in percent"},
+	{"drawdown.breached", TypeBool, "Drawdown exceeds the tolerance"},
+	{"lossLimit.locked", TypeBool, "Trading is locked by the daily or weekly loss limit"},
+	{"lossLimit.dailyPercent", TypeNumber, "Today's loss in percent of account value"},
+	{"lossLimit.weeklyPercent", TypeNumber, "This week's loss in percent of account value"},
+}
+
+// Variables returns the variables rule expressions can refer to
+func Variables() []Variable {
+	result := make([]Variable, len(variables))
+	copy(result, variables)
+	return result
+}
+
+// lookup finds a variable by name
+func lookup(name string) (Variable, bool) {
+	for _, v := range variables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
+
+// Env holds the value of every variable
+type Env map[string]interface{}
+
+// Input is the state the environment is computed from. Everything but Now is optional.
+type Input struct {
+	Now            time.Time
+	Assessment     *models.RiskAssessment // Latest assessment recorded today
+	Recommendation *sizing.Recommendation // Recommended size for Assessment
+	Settings       *models.PositionSettings
+	Histories      []*models.TradeHistory
+	Exposure       *portfolio.Exposure
+	Drawdown       *equity.Drawdown
+	LossLimit      *risk.LossLimitStatus
+}
+
+// exit is a position-closing event of a trade and the P&L it realized
+type exit struct {
+	trade *models.TradeHistory
+	date  time.Time
+	pl    float64
+}
+
+// NewEnv computes the variables from the current state
+func NewEnv(in Input) Env {
+	env := Env{}
+	for _, v := range variables {
+		env[v.Name] = zero(v.kind())
+	}
+	_, dayStart, dayEnd := risk.DayPeriod(in.Now)
+	_, weekStart, weekEnd := risk.WeekPeriod(in.Now)
+
+	if a := in.Assessment; a != nil {
+		env["assessment.recorded"] = true
+		env["assessment.emotional"] = float64(a.EmotionalScore)
+		env["assessment.fomo"] = float64(a.FOMOScore)
+		env["assessment.bias"] = float64(a.BiasScore)
+		env["assessment.physical"] = float64(a.PhysicalScore)
+		env["assessment.plImpact"] = float64(a.PLImpactScore)
+		env["assessment.other"] = float64(a.OtherScore)
+		env["assessment.overall"] = float64(a.OverallScore)
+	}
+	env["sizing.percent"] = sizing.MaximumPercent
+	if r := in.Recommendation; r != nil {
+		env["sizing.percent"] = float64(r.Percent)
+		env["sizing.extremeCount"] = float64(r.ExtremeCount)
+	}
+
+	accountValue := 0.0
+	if s := in.Settings; s != nil {
+		accountValue = s.AccountValue
+		env["settings.accountValue"] = s.AccountValue
+		env["settings.riskPerTrade"] = s.AccountRiskPerTrade
+		env["settings.dailyLossLimit"] = s.DailyLossLimit
+		env["settings.weeklyLossLimit"] = s.WeeklyLossLimit
+	}
+
+	var exits []exit
+	var today, week float64
+	var open, opened, closed, winning, losing float64
+	var lastEntry *models.Trade
+	for _, h := range in.Histories {
+		today += h.RealizedBetween(dayStart, dayEnd)
+		week += h.RealizedBetween(weekStart, weekEnd)
+		if h.OpenQuantity > 0 {
+			open++
+		}
+
+		entry := h.Trade.EntryDate
+		if !entry.Before(dayStart) && entry.Before(dayEnd) {
+			opened++
+			if lastEntry == nil || entry.After(lastEntry.EntryDate) {
+				lastEntry = h.Trade
+			}
+		}
+
+		exitedToday := false
+		for _, e := range h.Events {
+			if !e.ClosesPosition() || !e.Date.Before(dayEnd) {
+				continue
+			}
+			exits = append(exits, exit{trade: h, date: e.Date, pl: h.EventPL(e)})
+			exitedToday = exitedToday || (!e.Date.Before(dayStart) && e.Date.Before(dayEnd))
+		}
+		if exitedToday {
+			closed++
+			switch pl := h.RealizedBetween(dayStart, dayEnd); {
+			case pl > 0:
+				winning++
+			case pl < 0:
+				losing++
+			}
+		}
+	}
+	env["pnl.today"] = today
+	env["pnl.week"] = week
+	env["pnl.todayPercent"] = percentOf(today, accountValue)
+	env["pnl.weekPercent"] = percentOf(week, accountValue)
+	env["trades.open"] = open
+	env["trades.openedToday"] = opened
+	env["trades.closedToday"] = closed
+	env["trades.winningToday"] = winning
+	env["trades.losingToday"] = losing
+	env["trades.losingStreak"] = float64(losingStreak(in.Histories))
+
+	// Dates carry no time of day, so a loss and an entry on the same day cannot be ordered
+	for _, e := range exits {
+		if e.pl >= 0 {
+			continue
+		}
+		env["lastLoss.recorded"] = true
+		if lastEntry != nil && e.trade.Trade != lastEntry && !e.date.Before(dayStart) {
+			env["lastEntry.afterLoss"] = true
+		}
+	}
+
+	if x := in.Exposure; x != nil {
+		env["exposure.percent"] = x.TotalPercent
+		env["exposure.overLimit"] = x.OverLimit
+	}
+	if d := in.Drawdown; d != nil {
+		env["drawdown.percent"] = d.CurrentPercent
+		env["drawdown.breached"] = d.Breached
+	}
+	if l := in.LossLimit; l != nil {
+		env["lossLimit.locked"] = l.Locked
+		env["lossLimit.dailyPercent"] = l.Daily.LossPercent
+		env["lossLimit.weeklyPercent"] = l.Weekly.LossPercent
+	}
+
+	return env
+}
+
+// losingStreak counts the most recently closed trades that lost money in a row
+func losingStreak(histories []*models.TradeHistory) int {
+	var closed []*models.TradeHistory
+	for _, h := range histories {
+		if h.ClosedDate != nil {
+			closed = append(closed, h)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return closed[i].ClosedDate.After(*closed[j].ClosedDate) })
+
+	streak := 0
+	for _, h := range closed {
+		if h.RealizedPL >= 0 {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+// percentOf returns part as a percent of whole
+func percentOf(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return part / whole * 100
+}
@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// closedTrade is a long call bought at 5 and closed at a price, -$400 at 1 and +$300 at 8
+func closedTrade(id string, entry, exit time.Time, price float64) *models.TradeHistory {
+	trade := &models.Trade{ID: id, Type: "Long Call", EntryPrice: 5, EntryDate: entry}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return models.NewTradeHistory(trade, []*models.TradeEvent{
+		{ID: id + "-close", TradeID: id, Type: models.EventClose, Date: exit, Price: price, Quantity: 1},
+	})
+}
+
+// openTrade is a long call bought at 5 that is still open
+func openTrade(id string, entry time.Time) *models.TradeHistory {
+	trade := &models.Trade{ID: id, Type: "Long Call", EntryPrice: 5, EntryDate: entry}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return models.NewTradeHistory(trade, nil)
+}
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Dates are stored at midnight UTC of their calendar day, so the trade variables
+// must count Wednesday's trades on Wednesday whatever the local time zone
+func TestNewEnvTrades(t *testing.T) {
+	histories := []*models.TradeHistory{
+		closedTrade("win", day("2026-10-09"), day("2026-10-12"), 8),
+		closedTrade("loss-tuesday", day("2026-10-10"), day("2026-10-13"), 1),
+		closedTrade("loss-wednesday", day("2026-10-11"), day("2026-10-14"), 1),
+		openTrade("entry", day("2026-10-14")),
+	}
+
+	zones := []*time.Location{
+		time.UTC,
+		time.FixedZone("Los Angeles", -7*3600),
+		time.FixedZone("Tokyo", 9*3600),
+	}
+
+	tests := []struct {
+		name      string
+		now       func(*time.Location) time.Time
+		histories []*models.TradeHistory
+		want      Env
+	}{
+		{
+			name:      "entry on the day of a loss",
+			now:       func(loc *time.Location) time.Time { return time.Date(2026, 10, 14, 8, 0, 0, 0, loc) },
+			histories: histories,
+			want: Env{
+				"pnl.today":           -400.0,
+				"pnl.week":            -500.0, // Monday's win and two losses
+				"trades.open":         1.0,
+				"trades.openedToday":  1.0,
+				"trades.closedToday":  1.0,
+				"trades.losingToday":  1.0,
+				"trades.losingStreak": 2.0,
+				"lastLoss.recorded":   true,
+				"lastEntry.afterLoss": true,
+			},
+		},
+		{
+			name:      "the day after",
+			now:       func(loc *time.Location) time.Time { return time.Date(2026, 10, 15, 20, 0, 0, 0, loc) },
+			histories: histories,
+			want: Env{
+				"pnl.today":           0.0,
+				"pnl.week":            -500.0,
+				"trades.openedToday":  0.0,
+				"trades.closedToday":  0.0,
+				"trades.losingToday":  0.0,
+				"trades.losingStreak": 2.0,
+				"lastLoss.recorded":   true,
+				"lastEntry.afterLoss": false,
+			},
+		},
+		{
+			// A trade's own loss is not a loss it was entered after
+			name: "entered and lost the same day",
+			now:  func(loc *time.Location) time.Time { return time.Date(2026, 10, 14, 8, 0, 0, 0, loc) },
+			histories: []*models.TradeHistory{
+				closedTrade("win", day("2026-10-09"), day("2026-10-12"), 8),
+				closedTrade("day-trade", day("2026-10-14"), day("2026-10-14"), 1),
+			},
+			want: Env{
+				"pnl.today":           -400.0,
+				"trades.openedToday":  1.0,
+				"trades.losingStreak": 1.0,
+				"lastLoss.recorded":   true,
+				"lastEntry.afterLoss": false,
+			},
+		},
+		{
+			// A win last ends the streak
+			name: "win after losses",
+			now:  func(loc *time.Location) time.Time { return time.Date(2026, 10, 16, 8, 0, 0, 0, loc) },
+			histories: []*models.TradeHistory{
+				closedTrade("loss-monday", day("2026-10-09"), day("2026-10-12"), 1),
+				closedTrade("loss-tuesday", day("2026-10-10"), day("2026-10-13"), 1),
+				closedTrade("win-friday", day("2026-10-14"), day("2026-10-16"), 8),
+			},
+			want: Env{
+				"pnl.today":           300.0,
+				"trades.winningToday": 1.0,
+				"trades.losingStreak": 0.0,
+				"lastLoss.recorded":   true,
+				"lastEntry.afterLoss": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, loc := range zones {
+			t.Run(tt.name+"/"+loc.String(), func(t *testing.T) {
+				env := NewEnv(Input{Now: tt.now(loc), Histories: tt.histories})
+				for name, want := range tt.want {
+					if _, ok := lookup(name); !ok {
+						t.Fatalf("%s is not a variable", name)
+					}
+					if got := env[name]; got != want {
+						t.Errorf("%s = %v, want %v", name, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+// Without any state every variable has its zero value, except the full recommended size
+func TestNewEnvDefaults(t *testing.T) {
+	env := NewEnv(Input{Now: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)})
+	for _, v := range Variables() {
+		got, ok := env[v.Name]
+		if !ok {
+			t.Errorf("%s is missing", v.Name)
+			continue
+		}
+		want := zero(v.kind())
+		if v.Name == "sizing.percent" {
+			want = 100.0
+		}
+		if got != want {
+			t.Errorf("%s = %v, want %v", v.Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a compiled rule expression
+type Expression struct {
+	Source string
+	Names  []string // Variables the expression reads, in order of first use
+	root   node
+}
+
+// Compile parses a rule expression and checks that it refers to known variables,
+// combines values of matching types and evaluates to true or false.
+//
+// Expressions compare numbers (==, !=, <, <=, >, >=, +, -, *, /), booleans and
+// quoted strings, and combine conditions with and, or, not (or &&, ||, !) and
+// parentheses. Division by zero yields 0.
+func Compile(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, seen: map[string]bool{}}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+	if root.kind() != kindBool {
+		return nil, fmt.Errorf("expression must be a condition, not a %s", root.kind())
+	}
+
+	return &Expression{Source: source, Names: p.names, root: root}, nil
+}
+
+// Eval evaluates the expression against the variables of an environment
+func (e *Expression) Eval(env Env) bool {
+	return e.root.eval(env).(bool)
+}
+
+// kind is the type of a value
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindBool
+	kindString
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// kindOf returns the kind of a variable value
+func kindOf(v interface{}) kind {
+	switch v.(type) {
+	case bool:
+		return kindBool
+	case string:
+		return kindString
+	default:
+		return kindNumber
+	}
+}
+
+// node is a typed expression node
+type node interface {
+	kind() kind
+	eval(env Env) interface{}
+}
+
+type literal struct{ value interface{} }
+
+func (n literal) kind() kind           { return kindOf(n.value) }
+func (n literal) eval(Env) interface{} { return n.value }
+
+type variable struct {
+	name string
+	k    kind
+}
+
+func (n variable) kind() kind { return n.k }
+
+func (n variable) eval(env Env) interface{} {
+	if v, ok := env[n.name]; ok {
+		return v
+	}
+	return zero(n.k)
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n unary) kind() kind { return n.x.kind() }
+
+func (n unary) eval(env Env) interface{} {
+	if n.op == "-" {
+		return -n.x.eval(env).(float64)
+	}
+	return !n.x.eval(env).(bool)
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (n binary) kind() kind {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return kindNumber
+	default:
+		return kindBool
+	}
+}
+
+func (n binary) eval(env Env) interface{} {
+	switch n.op {
+	case "and":
+		return n.l.eval(env).(bool) && n.r.eval(env).(bool)
+	case "or":
+		return n.l.eval(env).(bool) || n.r.eval(env).(bool)
+	}
+
+	l, r := n.l.eval(env), n.r.eval(env)
+	switch n.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+
+	if n.l.kind() == kindString {
+		a, b := l.(string), r.(string)
+		switch n.op {
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		default:
+			return a >= b
+		}
+	}
+
+	a, b := l.(float64), r.(float64)
+	switch n.op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return 0.0
+		}
+		return a / b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+// zero returns the zero value of a kind
+func zero(k kind) interface{} {
+	switch k {
+	case kindBool:
+		return false
+	case kindString:
+		return ""
+	default:
+		return 0.0
+	}
+}
+
+// Tokens
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are the symbolic operators, longest first
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+// lex splits an expression into tokens
+func lex(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != c {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			tokens = append(tokens, token{tokenString, string(runes[start+1 : i]), start})
+			i++
+
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[start:i]), start})
+
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(string(runes[i:]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{tokenEOF, "end of expression", len(runes)}), nil
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	next   int
+	names  []string
+	seen   map[string]bool
+}
+
+func (p *parser) peek() token { return p.tokens[p.next] }
+
+// accept consumes the next token if it is one of the given operators or keywords
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOp && t.kind != tokenIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.next++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) or() (node, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("or", "||"); !ok {
+			return l, nil
+		}
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = logical("or", l, r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) and() (node, error) {
+	l, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("and", "&&"); !ok {
+			return l, nil
+		}
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = logical("and", l, r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) not() (node, error) {
+	if _, ok := p.accept("not", "!"); ok {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != kindBool {
+			return nil, fmt.Errorf("not needs a condition, got a %s", x.kind())
+		}
+		return unary{op: "not", x: x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	l, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return l, nil
+	}
+	r, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.kind() != r.kind() {
+		return nil, fmt.Errorf("cannot compare a %s with a %s", l.kind(), r.kind())
+	}
+	if l.kind() == kindBool && op != "==" && op != "!=" {
+		return nil, fmt.Errorf("operator %s does not apply to conditions", op)
+	}
+	return binary{op: op, l: l, r: r}, nil
+}
+
+func (p *parser) sum() (node, error) {
+	l, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = arithmetic(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) product() (node, error) {
+	l, err := p.negation()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.negation()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = arithmetic(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) negation() (node, error) {
+	if _, ok := p.accept("-"); ok {
+		x, err := p.negation()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != kindNumber {
+			return nil, fmt.Errorf("cannot negate a %s", x.kind())
+		}
+		return unary{op: "-", x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	p.next++
+
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos+1)
+		}
+		return literal{v}, nil
+
+	case tokenString:
+		return literal{t.text}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "and", "or", "not":
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+		}
+		v, ok := lookup(t.text)
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %q at position %d", t.text, t.pos+1)
+		}
+		if !p.seen[v.Name] {
+			p.seen[v.Name] = true
+			p.names = append(p.names, v.Name)
+		}
+		return variable{name: v.Name, k: v.kind()}, nil
+
+	case tokenOp:
+		if t.text == "(" {
+			x, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("missing ) at position %d", p.peek().pos+1)
+			}
+			return x, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %s at position %d", describe(t), t.pos+1)
+}
+
+// logical combines two conditions
+func logical(op string, l, r node) (node, error) {
+	if l.kind() != kindBool || r.kind() != kindBool {
+		return nil, fmt.Errorf("%s needs conditions on both sides", op)
+	}
+	return binary{op: op, l: l, r: r}, nil
+}
+
+// arithmetic combines two numbers
+func arithmetic(op string, l, r node) (node, error) {
+	if l.kind() != kindNumber || r.kind() != kindNumber {
+		return nil, fmt.Errorf("operator %s needs numbers on both sides", op)
+	}
+	return binary{op: op, l: l, r: r}, nil
+}
+
+// describe names a token in an error message
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return t.text
+	}
+	return strconv.Quote(t.text)
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileEvaluates(t *testing.T) {
+	env := Env{
+		"lossLimit.locked":   false,
+		"drawdown.breached":  false,
+		"lastLoss.recorded":  true,
+		"trades.open":        4.0,
+		"trades.losingToday": 0.0,
+	}
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		// not binds tighter than and: (not locked) and breached, not not (locked and breached)
+		{"not lossLimit.locked and drawdown.breached", false},
+		{"not (lossLimit.locked and drawdown.breached)", true},
+		{"! lossLimit.locked && ! drawdown.breached", true},
+		// and binds tighter than or: recorded or (locked and breached)
+		{"lastLoss.recorded or lossLimit.locked and drawdown.breached", true},
+		{"(lastLoss.recorded or lossLimit.locked) and drawdown.breached", false},
+		{"lastLoss.recorded || lossLimit.locked && drawdown.breached", true},
+		// * and / bind tighter than + and -, and - negates
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true},
+		{"-2 * 3 == -6", true},
+		{"- -2 == 2", true},
+		{"trades.open / 2 >= 2", true},
+		// Division by zero yields 0
+		{"trades.open / trades.losingToday == 0", true},
+		{"trades.open / 0 > 1", false},
+		{"'abc' < 'abd'", true},
+		{`"fomo" == 'fomo'`, true},
+		{"lastLoss.recorded == true", true},
+		{"lossLimit.locked != false", false},
+		{".5 + .5 == 1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if got := expr.Eval(env); got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"trades.open", "expression must be a condition, not a number"},
+		{"trades.open == true", "cannot compare a number with a boolean"},
+		{"'x' == 1", "cannot compare a string with a number"},
+		{"lossLimit.locked < true", "operator < does not apply to conditions"},
+		{"trades.open + lossLimit.locked > 1", "operator + needs numbers on both sides"},
+		{"trades.open and lossLimit.locked", "and needs conditions on both sides"},
+		{"not trades.open", "not needs a condition, got a number"},
+		{"-lossLimit.locked", "cannot negate a boolean"},
+		{"assessment.recorded == 'yes", "unterminated string at position 24"},
+		{"trades.open >", "unexpected end of expression at position 14"},
+		{"trades.open > 1 and", "unexpected end of expression at position 20"},
+		{"lossLimit.locked and (", "unexpected end of expression at position 23"},
+		{"(lossLimit.locked", "missing ) at position 18"},
+		{"trades.open > 1 2", `unexpected "2" at position 17`},
+		{"trades.open > 1 )", `unexpected ")" at position 17`},
+		{"trades.open # 1", `unexpected character '#' at position 13`},
+		{"1.2.3 > 1", `invalid number "1.2.3" at position 1`},
+		{"and lossLimit.locked", `unexpected "and" at position 1`},
+		{"foo.bar > 1", `unknown variable "foo.bar" at position 1`},
+		{"lastLoss.minutesAgo < 30", `unknown variable "lastLoss.minutesAgo" at position 1`},
+		{"", "unexpected end of expression at position 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := Compile(tt.source)
+			if err == nil {
+				t.Fatalf("Compile succeeded, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileNames(t *testing.T) {
+	expr, err := Compile("trades.open > 1 and trades.open < 5 or lossLimit.locked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"trades.open", "lossLimit.locked"}; !reflect.DeepEqual(expr.Names, want) {
+		t.Errorf("Names = %v, want %v", expr.Names, want)
+	}
+}
+
+// Every default rule compiles and its message refers to known variables
+func TestDefaultsCheck(t *testing.T) {
+	for _, rule := range Defaults() {
+		if err := Check(rule); err != nil {
+			t.Errorf("rule %s: %v", rule.ID, err)
+		}
+	}
+}
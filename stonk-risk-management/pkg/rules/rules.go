@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stonk-risk-management/pkg/models"
+)
+
+// Fired is a rule whose expression was true
+type Fired struct {
+	RuleID      string                 `json:"ruleId"`
+	Name        string                 `json:"name"`
+	Severity    string                 `json:"severity"`
+	Message     string                 `json:"message"` // The rule's message with variables filled in
+	Expression  string                 `json:"expression"`
+	Explanation string                 `json:"explanation"` // The values that made the expression true
+	Values      map[string]interface{} `json:"values"`      // Variables the expression read
+}
+
+// RuleError is a stored rule that could not be evaluated
+type RuleError struct {
+	RuleID string `json:"ruleId"`
+	Name   string `json:"name"`
+	Error  string `json:"error"`
+}
+
+// Evaluation is the outcome of evaluating the enabled rules
+type Evaluation struct {
+	Evaluated int                    `json:"evaluated"` // Enabled rules evaluated
+	Fired     []*Fired               `json:"fired"`     // Most severe first
+	Errors    []RuleError            `json:"errors"`
+	Values    map[string]interface{} `json:"values"` // Every variable, for display alongside the rules
+}
+
+// severityRank orders severities from most to least severe
+var severityRank = map[string]int{
+	models.SeverityCritical: 0,
+	models.SeverityWarning:  1,
+	models.SeverityInfo:     2,
+}
+
+// placeholder matches {variable} in rule messages
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// Check validates a rule and compiles its expression
+func Check(rule *models.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if _, err := Compile(rule.Expression); err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+	for _, m := range placeholder.FindAllStringSubmatch(rule.Message, -1) {
+		if _, ok := lookup(m[1]); !ok {
+			return fmt.Errorf("message refers to unknown variable %q", m[1])
+		}
+	}
+	return nil
+}
+
+// Evaluate runs the enabled rules against an environment
+func Evaluate(rules []*models.Rule, env Env) *Evaluation {
+	evaluation := &Evaluation{Fired: []*Fired{}, Errors: []RuleError{}, Values: map[string]interface{}(env)}
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		evaluation.Evaluated++
+
+		expr, err := Compile(rule.Expression)
+		if err != nil {
+			evaluation.Errors = append(evaluation.Errors, RuleError{RuleID: rule.ID, Name: rule.Name, Error: err.Error()})
+			continue
+		}
+		if !expr.Eval(env) {
+			continue
+		}
+
+		values := make(map[string]interface{}, len(expr.Names))
+		parts := make([]string, 0, len(expr.Names))
+		for _, name := range expr.Names {
+			values[name] = env[name]
+			parts = append(parts, fmt.Sprintf("%s = %s", name, format(env[name])))
+		}
+		explanation := fmt.Sprintf("%s is true", expr.Source)
+		if len(parts) > 0 {
+			explanation += " because " + strings.Join(parts, ", ")
+		}
+
+		evaluation.Fired = append(evaluation.Fired, &Fired{
+			RuleID:      rule.ID,
+			Name:        rule.Name,
+			Severity:    rule.Severity,
+			Message:     fill(rule.Message, env),
+			Expression:  expr.Source,
+			Explanation: explanation,
+			Values:      values,
+		})
+	}
+
+	sort.SliceStable(evaluation.Fired, func(i, j int) bool {
+		a, b := evaluation.Fired[i], evaluation.Fired[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		return a.Name < b.Name
+	})
+	return evaluation
+}
+
+// Defaults returns the rules the app ships with, which replace the hard-coded
+// euphoria, chasing, FOMO and stay-out flags
+func Defaults() []*models.Rule {
+	rules := []*models.Rule{
+		{
+			ID:         "euphoria",
+			Name:       "Potential euphoria",
+			Expression: "assessment.emotional >= 3 or assessment.plImpact >= 3",
+			Severity:   models.SeverityWarning,
+			Message:    "Extreme positive emotions or recent gains can lead to overconfidence. Stick to your plan and normal size.",
+		},
+		{
+			ID:         "chasing",
+			Name:       "Potential chasing",
+			Expression: "assessment.plImpact <= -3",
+			Severity:   models.SeverityWarning,
+			Message:    "Recent significant losses may lead to revenge trading. Consider a break or minimal size.",
+		},
+		{
+			ID:         "fomo",
+			Name:       "Extreme FOMO",
+			Expression: "assessment.fomo >= 3",
+			Severity:   models.SeverityWarning,
+			Message:    "Fear of missing out is at its highest. Wait for setups that meet your criteria.",
+		},
+		{
+			ID:         "stay-out",
+			Name:       "Stay out of the market",
+			Expression: "assessment.recorded and sizing.percent < 30",
+			Severity:   models.SeverityCritical,
+			Message:    "The recommended size is {sizing.percent}%. Consider not trading today.",
+		},
+		{
+			ID:         "losing-day",
+			Name:       "Three losing trades today",
+			Expression: "trades.losingToday >= 3",
+			Severity:   models.SeverityCritical,
+			Message:    "{trades.losingToday} trades lost money today. Step away before the losses compound.",
+		},
+		{
+			// Trade dates are recorded to the day, so this fires for any entry on the day of a loss
+			ID:         "revenge",
+			Name:       "Revenge trading",
+			Expression: "lastEntry.afterLoss",
+			Severity:   models.SeverityWarning,
+			Message:    "A new trade was entered on the same day as a losing exit. Make sure it is part of your plan, not a way to win the loss back.",
+		},
+		{
+			ID:         "loss-limit",
+			Name:       "Loss limit reached",
+			Expression: "lossLimit.locked",
+			Severity:   models.SeverityCritical,
+			Message:    "Trading is locked by the loss limits.",
+		},
+		{
+			ID:         "over-exposed",
+			Name:       "Portfolio over-exposed",
+			Expression: "exposure.overLimit",
+			Severity:   models.SeverityWarning,
+			Message:    "Open max loss is {exposure.percent}% of the account, above the portfolio limit.",
+		},
+		{
+			ID:         "no-assessment",
+			Name:       "Trading without an assessment",
+			Expression: "not assessment.recorded and trades.openedToday > 0",
+			Severity:   models.SeverityInfo,
+			Message:    "Trades were entered today without a risk assessment.",
+		},
+	}
+	for _, r := range rules {
+		r.Enabled = true
+		r.Builtin = true
+	}
+	return rules
+}
+
+// fill replaces {variable} placeholders with their values
+func fill(message string, env Env) string {
+	return placeholder.ReplaceAllStringFunc(message, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v, ok := env[name]; ok {
+			return format(v)
+		}
+		return m
+	})
+}
+
+// format renders a variable value for messages and explanations
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
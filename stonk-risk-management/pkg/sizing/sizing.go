@@ -0,0 +1,361 @@
+package sizing
+
+import (
+	"math"
+
+	"stonk-risk-management/pkg/models"
+)
+
+const (
+	// BaseSize is the starting position size (percent of max) before any factor is applied
+	BaseSize = 80.0
+	// FloorPercent is the minimum size outside of extreme scenarios
+	FloorPercent = 30.0
+	// MinimumPercent is the absolute minimum size, even in extreme scenarios
+	MinimumPercent = 3.0
+	// MaximumPercent is the largest size that can be recommended
+	MaximumPercent = 100.0
+	// DrawdownMultiplier scales the size while the account is past its drawdown tolerance
+	DrawdownMultiplier = 0.5
+)
+
+// Advice tiers, from most to least favorable
+const (
+	TierOptimal   = "optimal"
+	TierFavorable = "favorable"
+	TierStandard  = "standard"
+	TierCaution   = "caution"
+	TierHighRisk  = "high_risk"
+)
+
+// Factor is the contribution of a single assessment dimension to the position size
+type Factor struct {
+	Name         string  `json:"name"`
+	Score        int     `json:"score"`
+	Contribution float64 `json:"contribution"` // Percentage points added to (or removed from) the base size
+}
+
+// Flags are the warnings raised by an assessment
+type Flags struct {
+	Euphoria bool `json:"euphoria"` // Emotional state or recent P&L at +3
+	Chasing  bool `json:"chasing"`  // Recent P&L at -3, risk of revenge trading
+	FOMO     bool `json:"fomo"`     // FOMO at +3
+	StayOut  bool `json:"stayOut"`  // Final size below the 30% floor
+}
+
+// Advice is the guidance shown alongside the recommended size
+type Advice struct {
+	Tier  string   `json:"tier"`
+	Title string   `json:"title"`
+	Tips  []string `json:"tips"`
+}
+
+// Recommendation is the result of running an assessment through the sizing engine
+type Recommendation struct {
+	Percent               int      `json:"percent"`    // Final recommended size, percent of max position size
+	RawPercent            float64  `json:"rawPercent"` // Base size plus all factors, before floors and caps
+	Factors               []Factor `json:"factors"`
+	ExtremeCount          int      `json:"extremeCount"`
+	ExtremeMultiplier     float64  `json:"extremeMultiplier"`
+	ModerateCount         int      `json:"moderateCount"`
+	ModerateMultiplier    float64  `json:"moderateMultiplier"`
+	ExtremeScenario       bool     `json:"extremeScenario"` // When true the 30% floor does not apply
+	Flags                 Flags    `json:"flags"`
+	Advice                Advice   `json:"advice"`
+	MaxDollarRisk         float64  `json:"maxDollarRisk"`         // Account value times risk per trade
+	RecommendedDollarRisk float64  `json:"recommendedDollarRisk"` // MaxDollarRisk scaled by Percent
+	DrawdownBreached      bool     `json:"drawdownBreached"`      // Size was reduced for a drawdown past MaxDrawdownTolerance
+}
+
+// scores holds the dimensions the engine works with
+type scores struct {
+	emotional int
+	fomo      int
+	physical  int
+	plImpact  int
+	other     int
+}
+
+// Recommend computes the recommended position size for an assessment.
+// Settings are optional and only used to convert the percentage into dollars.
+func Recommend(assessment *models.RiskAssessment, settings *models.PositionSettings) Recommendation {
+	s := scoresFor(assessment)
+	rec := Recommendation{}
+
+	rec.Flags.Euphoria = s.emotional >= 3 || s.plImpact >= 3
+	rec.Flags.Chasing = s.plImpact <= -3
+	rec.Flags.FOMO = s.fomo >= 3
+
+	// Count extreme ratings to apply an additional multiplier if multiple extremes exist
+	if s.emotional <= -3 || s.emotional >= 3 {
+		rec.ExtremeCount++
+	}
+	if s.fomo >= 3 {
+		rec.ExtremeCount++
+	}
+	if s.physical <= -3 {
+		rec.ExtremeCount++
+	}
+	if s.plImpact <= -3 || s.plImpact >= 3 {
+		rec.ExtremeCount++
+	}
+	if s.other <= -3 {
+		rec.ExtremeCount++
+	}
+	rec.ExtremeMultiplier = extremeMultiplier(rec.ExtremeCount)
+
+	// Count moderate-high ratings (-2 or +2 levels)
+	if s.emotional == -2 || s.emotional == 2 {
+		rec.ModerateCount++
+	}
+	if s.fomo == 2 {
+		rec.ModerateCount++
+	}
+	if s.physical == -2 {
+		rec.ModerateCount++
+	}
+	if s.plImpact == -2 || s.plImpact == 2 {
+		rec.ModerateCount++
+	}
+	if s.other == -2 {
+		rec.ModerateCount++
+	}
+	rec.ModerateMultiplier = moderateMultiplier(rec.ModerateCount)
+
+	em, mm := rec.ExtremeMultiplier, rec.ModerateMultiplier
+	rec.Factors = []Factor{
+		{Name: "emotional", Score: s.emotional, Contribution: emotionalFactor(s.emotional, em, mm)},
+		{Name: "fomo", Score: s.fomo, Contribution: fomoFactor(s.fomo, em, mm)},
+		{Name: "physical", Score: s.physical, Contribution: physicalFactor(s.physical, em, mm)},
+		{Name: "plImpact", Score: s.plImpact, Contribution: plImpactFactor(s.plImpact, em, mm)},
+		{Name: "other", Score: s.other, Contribution: otherFactor(s.other, em, mm)},
+	}
+
+	size := BaseSize
+	for _, f := range rec.Factors {
+		size += f.Contribution
+	}
+	rec.RawPercent = size
+
+	// Allow position size to drop below the floor only in extreme scenarios
+	rec.ExtremeScenario = ((s.emotional <= -2 || s.physical <= -2) && s.fomo >= 2) ||
+		(s.plImpact <= -3 && s.fomo >= 2) ||
+		rec.ExtremeCount >= 2 ||
+		s.emotional <= -3 ||
+		s.physical <= -3
+
+	if !rec.ExtremeScenario && size < FloorPercent {
+		size = FloorPercent
+	}
+	if size < MinimumPercent {
+		size = MinimumPercent
+	}
+	if size > MaximumPercent {
+		size = MaximumPercent
+	}
+	rec.Percent = int(math.Round(size))
+
+	rec.Flags.StayOut = rec.Percent < int(FloorPercent)
+	rec.Advice = AdviceFor(rec.Percent)
+
+	if settings != nil {
+		rec.MaxDollarRisk = settings.AccountValue * (settings.AccountRiskPerTrade / 100)
+		rec.RecommendedDollarRisk = math.Round(float64(rec.Percent) / 100 * rec.MaxDollarRisk)
+	}
+
+	return rec
+}
+
+// ApplyDrawdown reduces the recommended size by DrawdownMultiplier when the
+// account drawdown has breached its tolerance
+func (r *Recommendation) ApplyDrawdown(breached bool) {
+	if !breached || r.DrawdownBreached {
+		return
+	}
+	r.DrawdownBreached = true
+
+	size := math.Max(float64(r.Percent)*DrawdownMultiplier, MinimumPercent)
+	r.Percent = int(math.Round(size))
+
+	r.Flags.StayOut = r.Percent < int(FloorPercent)
+	r.Advice = AdviceFor(r.Percent)
+	r.Advice.Tips = append(r.Advice.Tips, "Account drawdown exceeds your tolerance; size reduced until equity recovers")
+	r.RecommendedDollarRisk = math.Round(float64(r.Percent) / 100 * r.MaxDollarRisk)
+}
+
+// AdviceFor returns the advice tier for a final position size percentage
+func AdviceFor(percent int) Advice {
+	switch {
+	case percent >= 80:
+		return Advice{
+			Tier:  TierOptimal,
+			Title: "Optimal Trading Conditions",
+			Tips: []string{
+				"Trading conditions are excellent",
+				"Standard to slightly larger position sizing appropriate",
+				"Remain disciplined despite favorable conditions",
+			},
+		}
+	case percent >= 60:
+		return Advice{
+			Tier:  TierFavorable,
+			Title: "Favorable Trading Conditions",
+			Tips: []string{
+				"Good psychological and physical state for trading",
+				"Standard position sizing appropriate",
+				"Continue monitoring for any condition changes",
+			},
+		}
+	case percent >= 45:
+		return Advice{
+			Tier:  TierStandard,
+			Title: "Standard Trading Conditions",
+			Tips: []string{
+				"Moderate caution advised",
+				"Consider standard position sizing",
+				"Focus on higher-probability setups",
+			},
+		}
+	case percent >= 35:
+		return Advice{
+			Tier:  TierCaution,
+			Title: "Caution: Reduce Position Sizing",
+			Tips: []string{
+				"Current conditions suggest increased risk",
+				"Consider reducing position size by 30-50%",
+				"Focus only on highest-conviction setups",
+			},
+		}
+	default:
+		return Advice{
+			Tier:  TierHighRisk,
+			Title: "High Risk: Minimal Trading Recommended",
+			Tips: []string{
+				"Consider taking a trading break today",
+				"If trading, reduce position size by 70%",
+				"Only take extremely high-probability setups",
+			},
+		}
+	}
+}
+
+// extremeMultiplier grows with the number of extreme (+/-3) ratings
+func extremeMultiplier(count int) float64 {
+	switch {
+	case count >= 2:
+		return 3
+	case count >= 1:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// moderateMultiplier grows with the number of moderate-high (+/-2) ratings
+func moderateMultiplier(count int) float64 {
+	switch {
+	case count >= 2:
+		return 1.7
+	case count >= 1:
+		return 1.4
+	default:
+		return 1
+	}
+}
+
+// emotionalFactor: -3 is most cautious, peaks at +1/+2, +3 is cautious again due to euphoria
+func emotionalFactor(score int, extreme, moderate float64) float64 {
+	switch {
+	case score <= -3:
+		return -45 * extreme
+	case score == -2:
+		return -15 * moderate
+	case score <= 0:
+		return float64(score) * 5
+	case score == 1:
+		return 15
+	case score == 2:
+		return 20
+	default:
+		return -30 * extreme
+	}
+}
+
+// fomoFactor: -3 is peak performance, +3 is most cautious
+func fomoFactor(score int, extreme, moderate float64) float64 {
+	switch {
+	case score <= -3:
+		return 30
+	case score <= 0:
+		return 30 - math.Abs(float64(score+3))*10
+	case score == 1:
+		return -5
+	case score == 2:
+		return -15 * moderate
+	default:
+		return -30 * extreme
+	}
+}
+
+// physicalFactor: -3 is worst, +3 is best
+func physicalFactor(score int, extreme, moderate float64) float64 {
+	switch {
+	case score <= -3:
+		return -45 * extreme
+	case score == -2:
+		return -15 * moderate
+	case score < 0:
+		return float64(score) * 5
+	default:
+		return float64(score) * 10
+	}
+}
+
+// plImpactFactor peaks at +1; both -3 and +3 warrant more caution
+func plImpactFactor(score int, extreme, moderate float64) float64 {
+	switch {
+	case score <= -3:
+		return -45 * extreme
+	case score == -2:
+		return -20 * moderate
+	case score == -1:
+		return -12
+	case score == 0:
+		return -3
+	case score == 1:
+		return 22.5
+	case score == 2:
+		return 10 * moderate
+	default:
+		return -22.5 * extreme
+	}
+}
+
+// otherFactor is linear from -3 (bad vibes) to +3 (in the zone), with extra weight on the negative extremes
+func otherFactor(score int, extreme, moderate float64) float64 {
+	switch {
+	case score <= -3:
+		return -30 * extreme
+	case score == -2:
+		return -15 * moderate
+	case score < 0:
+		return float64(score) * 5
+	default:
+		return float64(score) * 10
+	}
+}
+
+// scoresFor extracts the scores used by the engine from an assessment
+func scoresFor(assessment *models.RiskAssessment) scores {
+	if assessment == nil {
+		return scores{}
+	}
+
+	return scores{
+		emotional: assessment.EmotionalScore,
+		fomo:      assessment.FOMOScore,
+		physical:  assessment.PhysicalScore,
+		plImpact:  assessment.PLImpactScore,
+		other:     assessment.OtherScore,
+	}
+}
@@ -0,0 +1,168 @@
+package sizing
+
+import (
+	"testing"
+
+	"stonk-risk-management/pkg/models"
+)
+
+func assessment(emotional, fomo, physical, plImpact, other int) *models.RiskAssessment {
+	return &models.RiskAssessment{
+		EmotionalScore: emotional,
+		FOMOScore:      fomo,
+		PhysicalScore:  physical,
+		PLImpactScore:  plImpact,
+		OtherScore:     other,
+	}
+}
+
+// Expected percentages were computed with the calculateRecommendedSize formula
+// the dashboard used before the engine moved to Go
+func TestRecommendPercent(t *testing.T) {
+	tests := []struct {
+		name       string
+		assessment *models.RiskAssessment
+		percent    int
+		extreme    bool
+		stayOut    bool
+	}{
+		{"no assessment", nil, 77, false, false},
+		{"neutral", assessment(0, 0, 0, 0, 0), 77, false, false},
+		{"all mildly negative", assessment(-1, -1, -1, -1, -1), 63, false, false},
+		{"best case capped at maximum", assessment(1, -3, 3, 1, 3), 100, false, false},
+		{"moderate highs capped at maximum", assessment(2, -2, 2, 2, 2), 100, false, false},
+		{"moderate euphoria", assessment(2, 2, 0, 2, 0), 92, false, false},
+		{"all extreme highs", assessment(3, 3, 3, 3, 3), 3, true, true},
+		{"all extreme lows", assessment(-3, -3, -3, -3, -3), 3, true, true},
+		{"distressed alone is extreme", assessment(-3, 0, 0, 0, 0), 3, true, true},
+		{"exhausted alone is extreme", assessment(0, 0, -3, 0, 0), 3, true, true},
+		{"extreme FOMO held at floor", assessment(0, 3, 0, 0, 0), 30, false, false},
+		{"euphoria held at floor", assessment(3, 0, 0, 0, 0), 30, false, false},
+		{"large losses held at floor", assessment(0, 0, 0, -3, 0), 30, false, false},
+		{"bad vibes held at floor", assessment(0, 0, 0, 0, -3), 30, false, false},
+		{"large gains", assessment(0, 0, 0, 3, 0), 35, false, false},
+		{"moderate lows held at floor", assessment(-2, 0, -2, -2, -2), 30, false, false},
+		{"negative emotions with FOMO", assessment(-2, 2, 0, 0, 0), 26, true, true},
+		{"chasing with FOMO", assessment(0, 2, 0, -3, 0), 3, true, true},
+		{"two extremes", assessment(0, 3, 0, -3, 0), 3, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := Recommend(tt.assessment, nil)
+			if rec.Percent != tt.percent {
+				t.Errorf("Percent = %d, want %d (raw %.2f)", rec.Percent, tt.percent, rec.RawPercent)
+			}
+			if rec.ExtremeScenario != tt.extreme {
+				t.Errorf("ExtremeScenario = %v, want %v", rec.ExtremeScenario, tt.extreme)
+			}
+			if rec.Flags.StayOut != tt.stayOut {
+				t.Errorf("Flags.StayOut = %v, want %v", rec.Flags.StayOut, tt.stayOut)
+			}
+		})
+	}
+}
+
+func TestRecommendFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		assessment *models.RiskAssessment
+		want       Flags
+	}{
+		{"neutral", assessment(0, 0, 0, 0, 0), Flags{}},
+		{"euphoric emotions", assessment(3, 0, 0, 0, 0), Flags{Euphoria: true}},
+		{"large gains", assessment(0, 0, 0, 3, 0), Flags{Euphoria: true}},
+		{"large losses", assessment(0, 0, 0, -3, 0), Flags{Chasing: true}},
+		{"extreme FOMO", assessment(0, 3, 0, 0, 0), Flags{FOMO: true}},
+		{"everything at once", assessment(3, 3, 3, -3, 3), Flags{Euphoria: true, Chasing: true, FOMO: true, StayOut: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recommend(tt.assessment, nil).Flags; got != tt.want {
+				t.Errorf("Flags = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendDollarRisk(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    *models.PositionSettings
+		maxRisk     float64
+		recommended float64
+	}{
+		{"no settings", nil, 0, 0},
+		{"zero account value", &models.PositionSettings{AccountValue: 0, AccountRiskPerTrade: 2}, 0, 0},
+		{"zero risk per trade", &models.PositionSettings{AccountValue: 50000, AccountRiskPerTrade: 0}, 0, 0},
+		{"funded account", &models.PositionSettings{AccountValue: 50000, AccountRiskPerTrade: 2}, 1000, 770},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := Recommend(assessment(0, 0, 0, 0, 0), tt.settings)
+			if rec.Percent != 77 {
+				t.Fatalf("Percent = %d, want 77", rec.Percent)
+			}
+			if rec.MaxDollarRisk != tt.maxRisk {
+				t.Errorf("MaxDollarRisk = %v, want %v", rec.MaxDollarRisk, tt.maxRisk)
+			}
+			if rec.RecommendedDollarRisk != tt.recommended {
+				t.Errorf("RecommendedDollarRisk = %v, want %v", rec.RecommendedDollarRisk, tt.recommended)
+			}
+		})
+	}
+}
+
+func TestApplyDrawdown(t *testing.T) {
+	settings := &models.PositionSettings{AccountValue: 50000, AccountRiskPerTrade: 2}
+
+	rec := Recommend(assessment(0, 0, 0, 0, 0), settings)
+	rec.ApplyDrawdown(false)
+	if rec.Percent != 77 || rec.DrawdownBreached {
+		t.Fatalf("without a breach: Percent = %d, DrawdownBreached = %v", rec.Percent, rec.DrawdownBreached)
+	}
+
+	rec.ApplyDrawdown(true)
+	if rec.Percent != 39 || !rec.DrawdownBreached || rec.RecommendedDollarRisk != 390 {
+		t.Fatalf("after a breach: Percent = %d, RecommendedDollarRisk = %v", rec.Percent, rec.RecommendedDollarRisk)
+	}
+
+	// Applying twice must not halve again
+	rec.ApplyDrawdown(true)
+	if rec.Percent != 39 {
+		t.Errorf("applied twice: Percent = %d, want 39", rec.Percent)
+	}
+
+	// Never below the absolute minimum
+	low := Recommend(assessment(-3, 0, 0, 0, 0), nil)
+	low.ApplyDrawdown(true)
+	if low.Percent != int(MinimumPercent) {
+		t.Errorf("minimum: Percent = %d, want %v", low.Percent, MinimumPercent)
+	}
+}
+
+func TestAdviceFor(t *testing.T) {
+	tests := []struct {
+		percent int
+		tier    string
+	}{
+		{100, TierOptimal},
+		{80, TierOptimal},
+		{79, TierFavorable},
+		{60, TierFavorable},
+		{59, TierStandard},
+		{45, TierStandard},
+		{44, TierCaution},
+		{35, TierCaution},
+		{34, TierHighRisk},
+		{3, TierHighRisk},
+	}
+
+	for _, tt := range tests {
+		if got := AdviceFor(tt.percent).Tier; got != tt.tier {
+			t.Errorf("AdviceFor(%d).Tier = %q, want %q", tt.percent, got, tt.tier)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tradecsv
+
+// Trade fields that CSV columns can map to. They are also the headers written by Export.
+const (
+	FieldTradeID        = "trade_id"
+	FieldSymbol         = "symbol"
+	FieldSector         = "sector"
+	FieldStrategy       = "strategy"
+	FieldType           = "type"
+	FieldEntryDate      = "entry_date"
+	FieldExpirationDate = "expiration_date"
+	FieldEntryPrice     = "entry_price"
+	FieldEntry          = "entry"
+	FieldStop           = "stop"
+	FieldTarget         = "target"
+	FieldTimeframe      = "timeframe"
+	FieldNotes          = "notes"
+	FieldLeg            = "leg"
+	FieldRight          = "right"
+	FieldSide           = "side"
+	FieldQuantity       = "quantity"
+	FieldStrike         = "strike"
+	FieldLegExpiration  = "leg_expiration"
+	FieldFillPrice      = "fill_price"
+	FieldOCCSymbol      = "occ_symbol"
+)
+
+// Fields lists every field in export column order
+var Fields = []string{
+	FieldTradeID, FieldSymbol, FieldSector, FieldStrategy, FieldType,
+	FieldEntryDate, FieldExpirationDate, FieldEntryPrice,
+	FieldEntry, FieldStop, FieldTarget, FieldTimeframe, FieldNotes,
+	FieldLeg, FieldRight, FieldSide, FieldQuantity, FieldStrike, FieldLegExpiration, FieldFillPrice, FieldOCCSymbol,
+}
+
+// headerAliases are other header names recognized for each field when no mapping is given
+var headerAliases = map[string][]string{
+	FieldTradeID:        {"id", "trade id", "group", "group id"},
+	FieldSymbol:         {"ticker", "underlying", "root"},
+	FieldSector:         {"industry"},
+	FieldStrategy:       {"category", "strategy category"},
+	FieldType:           {"strategy type", "trade type"},
+	FieldEntryDate:      {"date", "open date", "opened", "trade date"},
+	FieldExpirationDate: {"expiration", "expiry", "exp", "exp date"},
+	FieldEntryPrice:     {"price", "net price", "premium", "cost"},
+	FieldRight:          {"call/put", "put/call", "option type", "cp"},
+	FieldSide:           {"action", "buy/sell", "direction"},
+	FieldQuantity:       {"qty", "contracts", "size"},
+	FieldFillPrice:      {"fill", "leg price"},
+	FieldLegExpiration:  {"leg expiration", "leg expiry"},
+	FieldNotes:          {"note", "comments", "description"},
+	FieldOCCSymbol:      {"occ", "osi", "option symbol", "contract"},
+}
@@ -0,0 +1,70 @@
+package tradecsv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the date formats tried by DetectDateLayout, in order.
+// Month-first layouts come before day-first ones, so ambiguous files such as
+// 03/04/2025 are read the US way unless a day above 12 rules it out.
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"1/2/2006",
+	"2/1/2006",
+	"1/2/06",
+	"2/1/06",
+	"1-2-2006",
+	"2-1-2006",
+	"2-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"January 2, 2006",
+	"20060102",
+}
+
+// DetectDateLayout returns the known layout that parses the most values, preferring
+// earlier layouts on a tie. Empty values are ignored; values the layout cannot
+// parse are reported against their rows during import.
+func DetectDateLayout(values []string) (string, error) {
+	best, bestCount := "", 0
+	for _, layout := range dateLayouts {
+		count := 0
+		for _, v := range values {
+			if _, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = layout, count
+		}
+	}
+
+	if bestCount == 0 {
+		for _, v := range values {
+			if strings.TrimSpace(v) != "" {
+				return "", fmt.Errorf("unrecognized date format %q", v)
+			}
+		}
+	}
+	return best, nil
+}
+
+// parseDate parses a date with the given layout, returning the zero time for an empty value
+func parseDate(layout, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,103 @@
+package tradecsv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+)
+
+// exportDateLayout is the date format written by Export
+const exportDateLayout = "2006-01-02"
+
+// Export writes trades as CSV with one row per leg. Trades without leg detail
+// are written as a single row with the leg columns left empty.
+func Export(w io.Writer, trades []*models.Trade) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(Fields); err != nil {
+		return err
+	}
+
+	for _, trade := range trades {
+		for _, row := range tradeRows(trade) {
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// tradeRows returns the CSV rows of a trade
+func tradeRows(trade *models.Trade) [][]string {
+	base := map[string]string{
+		FieldTradeID:        trade.ID,
+		FieldSymbol:         trade.Symbol,
+		FieldSector:         trade.Sector,
+		FieldStrategy:       trade.Strategy,
+		FieldType:           trade.Type,
+		FieldEntryDate:      formatDate(trade.EntryDate),
+		FieldExpirationDate: formatDate(trade.ExpirationDate),
+		FieldEntryPrice:     formatFloat(trade.EntryPrice),
+		FieldEntry:          formatFloat(trade.Entry),
+		FieldStop:           formatFloat(trade.Stop),
+		FieldTarget:         formatFloat(trade.Target),
+		FieldTimeframe:      trade.Timeframe,
+		FieldNotes:          trade.Notes,
+	}
+
+	if !trade.HasLegDetail() {
+		return [][]string{row(base)}
+	}
+
+	rows := make([][]string, 0, len(trade.Legs))
+	for i, leg := range trade.Legs {
+		values := make(map[string]string, len(Fields))
+		for k, v := range base {
+			values[k] = v
+		}
+		values[FieldLeg] = strconv.Itoa(i + 1)
+		values[FieldRight] = leg.Right
+		values[FieldSide] = leg.Side
+		values[FieldQuantity] = strconv.Itoa(leg.Quantity)
+		values[FieldStrike] = formatFloat(leg.Strike)
+		values[FieldLegExpiration] = formatDate(leg.Expiration)
+		values[FieldFillPrice] = formatFloat(leg.FillPrice)
+		values[FieldOCCSymbol] = leg.Symbol
+		if s, err := occ.ForLeg(trade.Symbol, leg); err == nil {
+			values[FieldOCCSymbol] = s.String()
+		}
+		rows = append(rows, row(values))
+	}
+	return rows
+}
+
+// row orders field values by the export columns
+func row(values map[string]string) []string {
+	r := make([]string, len(Fields))
+	for i, f := range Fields {
+		r[i] = values[f]
+	}
+	return r
+}
+
+// formatDate formats a date, leaving zero dates empty
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(exportDateLayout)
+}
+
+// formatFloat formats a number without trailing zeros, leaving zero empty
+func formatFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
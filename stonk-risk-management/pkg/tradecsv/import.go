@@ -0,0 +1,573 @@
+package tradecsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/occ"
+
+	"github.com/google/uuid"
+)
+
+// Row statuses in an import report
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Import modes
+const (
+	ModeAdd    = "add"    // Every trade is added under a new ID
+	ModeUpdate = "update" // A trade_id naming a stored trade updates that trade
+)
+
+// Mapping configures how CSV columns map to trade fields
+type Mapping struct {
+	Columns    map[string]string `json:"columns"`    // Trade field -> CSV header ("" ignores the field); unmapped fields are matched by header name
+	DateFormat string            `json:"dateFormat"` // Go time layout; detected from the data when empty
+}
+
+// RowResult is the outcome of importing one CSV row
+type RowResult struct {
+	Row     int      `json:"row"`     // Line number in the file, the header being line 1
+	TradeID string   `json:"tradeId"` // trade_id of the row in the file, which groups the legs of a trade
+	ID      string   `json:"id"`      // ID the trade is saved under
+	Symbol  string   `json:"symbol"`
+	Status  string   `json:"status"` // ok or error
+	Errors  []string `json:"errors"`
+}
+
+// Report summarizes an import
+type Report struct {
+	DateFormat string      `json:"dateFormat"` // Date layout used to read the file
+	Rows       []RowResult `json:"rows"`
+	Imported   int         `json:"imported"` // Trades that passed validation
+	Updated    int         `json:"updated"`  // Imported trades that replace a stored trade
+	Rejected   int         `json:"rejected"` // Trades with at least one invalid row
+	Saved      bool        `json:"saved"`    // Whether the valid trades were written (false for a dry run)
+}
+
+// tradeGroup collects the rows that make up one trade
+type tradeGroup struct {
+	id      string
+	rows    []int // Indexes into the report rows
+	records [][]string
+}
+
+// Import reads trades from CSV. Rows sharing a trade_id are combined into one
+// multi-leg trade; without a trade_id column every row is its own trade.
+// Invalid rows are reported rather than failing the file, and only trades whose
+// rows are all valid are returned. An error is returned only when the file
+// cannot be read or required columns are missing.
+//
+// The trade_id only groups rows within the file: each trade gets a new ID, so a
+// file from elsewhere cannot overwrite stored trades. When update is not nil, a
+// trade_id that is a UUID for which update returns true keeps that ID and
+// replaces the stored trade.
+func Import(r io.Reader, mapping Mapping, update func(id string) bool) ([]*models.Trade, *Report, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("file is empty")
+		}
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns, err := resolveColumns(header, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if blank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+
+	layout := mapping.DateFormat
+	if layout == "" {
+		var dates []string
+		for _, record := range records {
+			for _, f := range []string{FieldEntryDate, FieldExpirationDate, FieldLegExpiration} {
+				dates = append(dates, columns.value(record, f))
+			}
+		}
+		if layout, err = DetectDateLayout(dates); err != nil {
+			return nil, nil, err
+		}
+		if layout == "" {
+			layout = exportDateLayout
+		}
+	}
+
+	report := &Report{DateFormat: layout, Rows: make([]RowResult, len(records))}
+
+	// Group rows by trade ID, keeping the order in which trades first appear
+	var groups []*tradeGroup
+	byID := map[string]*tradeGroup{}
+	for i, record := range records {
+		id := columns.value(record, FieldTradeID)
+		report.Rows[i] = RowResult{
+			Row:     lines[i],
+			TradeID: id,
+			Symbol:  strings.ToUpper(columns.value(record, FieldSymbol)),
+		}
+
+		g := byID[id]
+		if g == nil || id == "" {
+			g = &tradeGroup{id: id}
+			groups = append(groups, g)
+			if id != "" {
+				byID[id] = g
+			}
+		}
+		g.rows = append(g.rows, i)
+		g.records = append(g.records, record)
+	}
+
+	var trades []*models.Trade
+	for _, g := range groups {
+		trade, rowErrs, tradeErr := buildTrade(g, columns, layout)
+
+		valid := tradeErr == nil
+		for i, row := range g.rows {
+			result := &report.Rows[row]
+			result.Errors = rowErrs[i]
+			if tradeErr != nil {
+				result.Errors = append(result.Errors, tradeErr.Error())
+			}
+			if len(result.Errors) > 0 {
+				valid = false
+			}
+		}
+
+		for _, row := range g.rows {
+			report.Rows[row].Status = StatusOK
+			if !valid {
+				report.Rows[row].Status = StatusError
+			}
+		}
+
+		if valid {
+			trade.ID = uuid.New().String()
+			if _, err := uuid.Parse(g.id); err == nil && update != nil && update(g.id) {
+				trade.ID = g.id
+				report.Updated++
+			}
+			for _, row := range g.rows {
+				report.Rows[row].ID = trade.ID
+			}
+			trades = append(trades, trade)
+			report.Imported++
+		} else {
+			report.Rejected++
+		}
+	}
+
+	return trades, report, nil
+}
+
+// buildTrade converts the rows of a group into a trade. It returns the errors
+// of each row and an error that applies to the trade as a whole.
+func buildTrade(g *tradeGroup, columns columnIndex, layout string) (*models.Trade, [][]string, error) {
+	rowErrs := make([][]string, len(g.records))
+	first := g.records[0]
+
+	trade := &models.Trade{
+		Symbol:    strings.ToUpper(columns.value(first, FieldSymbol)),
+		Sector:    columns.value(first, FieldSector),
+		Notes:     columns.value(first, FieldNotes),
+		Timeframe: columns.value(first, FieldTimeframe),
+		LegNumber: 1,
+	}
+
+	addErr := func(row int, format string, args ...interface{}) {
+		rowErrs[row] = append(rowErrs[row], fmt.Sprintf(format, args...))
+	}
+
+	if trade.Symbol == "" {
+		addErr(0, "symbol is required")
+	}
+	if trade.Sector == "" {
+		addErr(0, "sector is required")
+	}
+
+	strategyType, category, err := resolveStrategy(columns.value(first, FieldType), columns.value(first, FieldStrategy))
+	if err != nil {
+		addErr(0, "%v", err)
+	}
+	trade.Type, trade.Strategy = strategyType, category
+
+	if trade.EntryDate, err = parseDate(layout, columns.value(first, FieldEntryDate)); err != nil {
+		addErr(0, "entry date: %v", err)
+	} else if trade.EntryDate.IsZero() {
+		addErr(0, "entry date is required")
+	}
+	if trade.ExpirationDate, err = parseDate(layout, columns.value(first, FieldExpirationDate)); err != nil {
+		addErr(0, "expiration date: %v", err)
+	}
+
+	for _, f := range []struct {
+		field string
+		dest  *float64
+	}{
+		{FieldEntryPrice, &trade.EntryPrice},
+		{FieldEntry, &trade.Entry},
+		{FieldStop, &trade.Stop},
+		{FieldTarget, &trade.Target},
+	} {
+		v, err := parseNumber(columns.value(first, f.field))
+		if err != nil {
+			addErr(0, "%s: %v", f.field, err)
+		}
+		*f.dest = math.Abs(v)
+	}
+
+	// Legs come from every row that names a right or contract; rows without one describe
+	// a trade recorded without leg detail
+	detailed := 0
+	for i, record := range g.records {
+		if columns.value(record, FieldRight) == "" && columns.value(record, FieldOCCSymbol) == "" {
+			continue
+		}
+		detailed++
+
+		leg, errs := parseLeg(record, columns, layout, trade.Symbol, trade.ExpirationDate)
+		for _, e := range errs {
+			addErr(i, "%s", e)
+		}
+		trade.Legs = append(trade.Legs, leg)
+	}
+
+	if detailed > 0 && detailed < len(g.records) {
+		return nil, rowErrs, fmt.Errorf("trade mixes rows with and without leg detail")
+	}
+	if detailed == 0 && len(g.records) > 1 {
+		return nil, rowErrs, fmt.Errorf("trade has %d rows but no leg detail", len(g.records))
+	}
+
+	for _, errs := range rowErrs {
+		if len(errs) > 0 {
+			return nil, rowErrs, nil
+		}
+	}
+
+	if detailed == 0 {
+		if trade.ExpirationDate.IsZero() {
+			rowErrs[0] = append(rowErrs[0], "expiration date is required")
+			return nil, rowErrs, nil
+		}
+		trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	} else {
+		// Fill the trade-level values a leg-per-row file may leave out
+		for _, leg := range trade.Legs {
+			if leg.Expiration.After(trade.ExpirationDate) {
+				trade.ExpirationDate = leg.Expiration
+			}
+		}
+		if trade.EntryPrice == 0 {
+			trade.EntryPrice = math.Abs(trade.EntryValue())
+		}
+		trade.IsMultiLeg = len(trade.Legs) > 1
+		occ.AssignSymbols(trade)
+	}
+
+	if err := trade.ValidateLegs(); err != nil {
+		return nil, rowErrs, err
+	}
+
+	return trade, rowErrs, nil
+}
+
+// parseLeg reads the leg columns of a row. An OCC symbol supplies the right,
+// strike and expiration when their columns are empty, and must agree with them otherwise.
+func parseLeg(record []string, columns columnIndex, layout string, underlying string, tradeExpiration time.Time) (models.Leg, []string) {
+	var errs []string
+	leg := models.Leg{Expiration: tradeExpiration}
+
+	var symbol *occ.Symbol
+	if value := columns.value(record, FieldOCCSymbol); value != "" {
+		s, err := occ.Parse(value)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			symbol = &s
+		}
+	}
+
+	value := columns.value(record, FieldRight)
+	right, ok := normalizeRight(value)
+	switch {
+	case value == "":
+		// Only rows with an OCC symbol get here; a bad symbol is already reported
+		if symbol != nil {
+			right = symbol.Right
+		}
+	case !ok:
+		errs = append(errs, fmt.Sprintf("unknown right %q", value))
+	}
+	leg.Right = right
+
+	quantity, err := parseNumber(columns.value(record, FieldQuantity))
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Sprintf("quantity: %v", err))
+	case quantity != math.Trunc(quantity):
+		errs = append(errs, fmt.Sprintf("quantity must be a whole number, got %v", quantity))
+	}
+
+	side := columns.value(record, FieldSide)
+	if side == "" {
+		// A signed quantity stands in for the side: negative for short legs
+		switch {
+		case quantity < 0:
+			leg.Side = models.SideSell
+		case quantity > 0:
+			leg.Side = models.SideBuy
+		default:
+			errs = append(errs, "side is required")
+		}
+	} else if leg.Side, ok = normalizeSide(side); !ok {
+		errs = append(errs, fmt.Sprintf("unknown side %q", side))
+	}
+	leg.Quantity = int(math.Abs(quantity))
+
+	if leg.Strike, err = parseNumber(columns.value(record, FieldStrike)); err != nil {
+		errs = append(errs, fmt.Sprintf("strike: %v", err))
+	} else if leg.Strike == 0 && symbol != nil {
+		leg.Strike = symbol.Strike
+	}
+
+	expiration, err := parseDate(layout, columns.value(record, FieldLegExpiration))
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Sprintf("leg expiration: %v", err))
+	case !expiration.IsZero():
+		leg.Expiration = expiration
+	case symbol != nil:
+		leg.Expiration = symbol.Expiration
+	}
+
+	fill, err := parseNumber(columns.value(record, FieldFillPrice))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("fill price: %v", err))
+	}
+	leg.FillPrice = math.Abs(fill)
+
+	if right == models.RightStock {
+		leg.Strike = 0
+	}
+	if len(errs) == 0 {
+		if err := leg.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) == 0 && symbol != nil {
+		if err := symbol.Validate(underlying, leg); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	return leg, errs
+}
+
+// resolveStrategy normalizes the strategy type and category of a row. The type
+// column may be missing when the category column names the type instead, as many
+// brokers export a single "strategy" column.
+func resolveStrategy(typeName, categoryName string) (string, string, error) {
+	if typeName == "" && categoryName == "" {
+		return "", "", fmt.Errorf("strategy type is required")
+	}
+
+	name := typeName
+	if name == "" {
+		name = categoryName
+	}
+	strategyType, ok := models.NormalizeStrategyType(name)
+	if !ok {
+		return "", "", fmt.Errorf("unknown strategy %q", name)
+	}
+
+	category := models.StrategyCategoryFor(strategyType)
+	if typeName != "" && categoryName != "" {
+		if given, ok := models.NormalizeStrategyCategory(categoryName); ok && given != category {
+			return "", "", fmt.Errorf("strategy %q is not in category %q", strategyType, given)
+		}
+	}
+
+	return strategyType, category, nil
+}
+
+// normalizeRight maps broker spellings of an option right
+func normalizeRight(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "c", "call", "calls":
+		return models.RightCall, true
+	case "p", "put", "puts":
+		return models.RightPut, true
+	case "s", "stock", "stk", "shares", "share", "equity":
+		return models.RightStock, true
+	}
+	return "", false
+}
+
+// normalizeSide maps broker spellings of a leg side
+func normalizeSide(value string) (string, bool) {
+	switch headerKey(value) {
+	case "b", "buy", "bought", "long", "bto", "btc", "buytoopen", "buytoclose":
+		return models.SideBuy, true
+	case "s", "sell", "sold", "short", "sto", "stc", "selltoopen", "selltoclose":
+		return models.SideSell, true
+	}
+	return "", false
+}
+
+// parseNumber parses a number that may carry a currency sign, thousands
+// separators or accounting-style parentheses for negatives. Empty values are zero.
+func parseNumber(value string) (float64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	if negative {
+		f = -f
+	}
+	return f, nil
+}
+
+// columnIndex maps trade fields to the index of their CSV column
+type columnIndex map[string]int
+
+// value returns the trimmed value of a field in a record, or "" when the
+// field is not mapped or the record is short
+func (c columnIndex) value(record []string, field string) string {
+	i, ok := c[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// has reports whether a field is mapped to a column
+func (c columnIndex) has(field string) bool {
+	_, ok := c[field]
+	return ok
+}
+
+// resolveColumns maps fields to header positions. Explicit mappings must name
+// an existing header; other fields are matched by name or a common alias,
+// ignoring case and punctuation.
+func resolveColumns(header []string, mapping Mapping) (columnIndex, error) {
+	positions := make(map[string]int, len(header))
+	for i, h := range header {
+		key := headerKey(h)
+		if _, dup := positions[key]; !dup {
+			positions[key] = i
+		}
+	}
+
+	known := make(map[string]bool, len(Fields))
+	for _, f := range Fields {
+		known[f] = true
+	}
+
+	columns := columnIndex{}
+	for field, name := range mapping.Columns {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown trade field %q in column mapping", field)
+		}
+		if name == "" {
+			continue
+		}
+		i, ok := positions[headerKey(name)]
+		if !ok {
+			return nil, fmt.Errorf("column %q mapped to %s not found in header", name, field)
+		}
+		columns[field] = i
+	}
+
+	for _, field := range Fields {
+		if _, mapped := mapping.Columns[field]; mapped {
+			continue
+		}
+		for _, name := range append([]string{field}, headerAliases[field]...) {
+			if i, ok := positions[headerKey(name)]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{FieldSymbol, FieldEntryDate} {
+		if !columns.has(field) {
+			missing = append(missing, field)
+		}
+	}
+	if !columns.has(FieldType) && !columns.has(FieldStrategy) {
+		missing = append(missing, FieldType)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// headerKey lowercases a header and drops everything but letters and digits,
+// so "Entry Date", "entry_date" and "entryDate" match
+func headerKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// blank reports whether every value of a record is empty
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package tradecsv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const storedID = "3f2b8c1e-6d4a-4f0e-9b7a-2c5d8e1f0a93"
+
+// A bull call spread under trade_id "1", a long call under a stored trade's ID,
+// and a long put under an unknown UUID
+const idsFile = `trade_id,symbol,sector,type,entry_date,expiration_date,right,side,quantity,strike,fill_price
+1,AAPL,Technology,Bull Call Spread,2026-10-01,2026-11-20,call,buy,1,150,5
+1,AAPL,Technology,Bull Call Spread,2026-10-01,2026-11-20,call,sell,1,160,2
+` + storedID + `,MSFT,Technology,Long Call,2026-10-02,2026-11-20,call,buy,1,400,8
+0d9e6b2a-1c3f-4a5b-8e7d-6f9a0b1c2d3e,SPY,Index,Long Put,2026-10-03,2026-11-20,put,buy,1,500,6
+`
+
+func TestImportAssignsNewIDs(t *testing.T) {
+	trades, report, err := Import(strings.NewReader(idsFile), Mapping{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || report.Imported != 3 || report.Updated != 0 {
+		t.Fatalf("imported %d trades (report %d, updated %d), want 3 new", len(trades), report.Imported, report.Updated)
+	}
+	if len(trades[0].Legs) != 2 {
+		t.Errorf("rows with trade_id 1 gave %d legs, want 2", len(trades[0].Legs))
+	}
+
+	seen := map[string]bool{}
+	for _, trade := range trades {
+		if _, err := uuid.Parse(trade.ID); err != nil || trade.ID == storedID || seen[trade.ID] {
+			t.Errorf("trade %s ID = %q, want a new UUID", trade.Symbol, trade.ID)
+		}
+		seen[trade.ID] = true
+	}
+	if report.Rows[0].TradeID != "1" || report.Rows[0].ID != trades[0].ID || report.Rows[1].ID != trades[0].ID {
+		t.Errorf("rows = %+v, want the file's trade_id and the new ID", report.Rows[:2])
+	}
+}
+
+func TestImportUpdatesStoredTrades(t *testing.T) {
+	stored := func(id string) bool { return id == storedID }
+
+	trades, report, err := Import(strings.NewReader(idsFile), Mapping{}, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Updated != 1 {
+		t.Errorf("updated = %d, want 1", report.Updated)
+	}
+	for _, trade := range trades {
+		if (trade.Symbol == "MSFT") != (trade.ID == storedID) {
+			t.Errorf("trade %s ID = %q; only the stored trade keeps its ID", trade.Symbol, trade.ID)
+		}
+	}
+}
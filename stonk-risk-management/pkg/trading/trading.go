@@ -0,0 +1,363 @@
+// Package trading saves trades and their lifecycle events for both the app and orm,
+// so they refuse the same trades and keep the equity curve up to date the same way
+package trading
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/equity"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/sizing"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// Store checks and saves trades and trade events
+type Store struct {
+	db         *database.DB
+	risk       *database.RiskRepository
+	trades     *database.TradeRepository
+	events     *database.TradeEventRepository
+	positions  *database.PositionRepository
+	lossLimits *database.LossLimitRepository
+	equity     *database.EquityRepository
+	cashFlows  *database.CashFlowRepository
+}
+
+// NewStore creates a store on a database
+func NewStore(db *database.DB) *Store {
+	return &Store{
+		db:         db,
+		risk:       database.NewRiskRepository(db),
+		trades:     database.NewTradeRepository(db),
+		events:     database.NewTradeEventRepository(db),
+		positions:  database.NewPositionRepository(db),
+		lossLimits: database.NewLossLimitRepository(db),
+		equity:     database.NewEquityRepository(db),
+		cashFlows:  database.NewCashFlowRepository(db),
+	}
+}
+
+// SaveTrade saves a trade after checking its max loss against the risk budget.
+// New trades, and edits that raise a trade's max loss, are refused while a loss
+// limit is locked. The returned check lists any violations; blocking violations
+// prevent the save.
+func (s *Store) SaveTrade(trade *models.Trade) (*risk.Check, error) {
+	addsRisk, err := s.addsRisk(trade)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := s.CheckTrade(trade, addsRisk)
+	if err != nil {
+		return check, err
+	}
+
+	return check, s.trades.Save(trade)
+}
+
+// addsRisk reports whether saving a trade adds risk: no trade with its ID is stored
+// yet, or the edit raises the stored trade's max loss
+func (s *Store) addsRisk(trade *models.Trade) (bool, error) {
+	if trade.ID == "" {
+		return true, nil
+	}
+	stored, err := s.trades.Get(trade.ID)
+	if err == badger.ErrKeyNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch trade %s: %w", trade.ID, err)
+	}
+
+	before, beforeUnlimited, _ := risk.MaxLoss(stored)
+	after, afterUnlimited, _ := risk.MaxLoss(trade)
+	switch {
+	case beforeUnlimited:
+		return false, nil
+	case afterUnlimited:
+		return true, nil
+	default:
+		return after > before+0.005, nil
+	}
+}
+
+// CheckTrade validates a trade before it is saved and runs the risk check.
+// The trade is refused while a loss limit is breached when enforceLock is set,
+// which callers do for trades that add risk; rolls are allowed.
+func (s *Store) CheckTrade(trade *models.Trade, enforceLock bool) (*risk.Check, error) {
+	// Basic validation before saving
+	if trade.Symbol == "" || trade.Sector == "" || trade.Strategy == "" || trade.Type == "" {
+		return nil, fmt.Errorf("invalid trade data: missing required fields")
+	}
+
+	// For backward compatibility, always set legNumber to 1
+	trade.LegNumber = 1
+
+	if enforceLock {
+		status, err := s.LossLimitStatus(nil, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if err := status.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	check, err := s.CheckRisk(trade)
+	if err != nil {
+		return nil, err
+	}
+	if err := check.Err(); err != nil {
+		return check, err
+	}
+	return check, nil
+}
+
+// CheckRisk computes a trade's max loss and compares it to the per-trade risk
+// budget scaled by the current recommended position size
+func (s *Store) CheckRisk(trade *models.Trade) (*risk.Check, error) {
+	settings, err := s.positions.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	recommendation, err := s.Recommendation(nil, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	check := risk.Evaluate(trade, settings, recommendation.Percent)
+	return &check, nil
+}
+
+// Recommendation sizes positions for an assessment, or for the latest one when nil,
+// reduced while the equity drawdown is past the tolerance. Without any assessment
+// the full size is recommended.
+func (s *Store) Recommendation(assessment *models.RiskAssessment, settings *models.PositionSettings) (*sizing.Recommendation, error) {
+	if assessment == nil {
+		latest, err := database.LogBadKeys(s.risk.GetLatest())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch latest risk assessment: %w", err)
+		}
+		assessment = latest
+	}
+
+	curve, err := s.EquityCurve(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation := sizing.Recommendation{Percent: int(sizing.MaximumPercent)}
+	if assessment != nil {
+		recommendation = sizing.Recommend(assessment, settings)
+	}
+	recommendation.ApplyDrawdown(curve.Drawdown.Breached)
+	return &recommendation, nil
+}
+
+// LossLimitStatus evaluates the loss limits from the trade history as of a time.
+// Marks are optional per-unit prices of open trades, keyed by trade ID.
+func (s *Store) LossLimitStatus(marks map[string]float64, asOf time.Time) (*risk.LossLimitStatus, error) {
+	histories, err := s.Histories()
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.positions.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	var lookupErr error
+	overrides := func(period, key string) *models.LossLimitOverride {
+		override, err := s.lossLimits.GetOverride(period, key)
+		if err != nil && lookupErr == nil {
+			lookupErr = err
+		}
+		return override
+	}
+
+	status := risk.EvaluateLossLimits(histories, marks, settings, overrides, asOf)
+	if lookupErr != nil {
+		return nil, fmt.Errorf("failed to load loss limit overrides: %w", lookupErr)
+	}
+	return &status, nil
+}
+
+// RecordEquitySnapshot saves today's account value, net deposits plus realized P&L
+func (s *Store) RecordEquitySnapshot() (*models.EquitySnapshot, error) {
+	settings, err := s.positions.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load position settings: %w", err)
+	}
+
+	histories, err := s.Histories()
+	if err != nil {
+		return nil, err
+	}
+	flows, err := s.CashFlows(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := equity.Snapshot(histories, flows, time.Now())
+	if err := s.equity.Save(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to save equity snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// recordEquity saves today's account value after a trade changes. The change is
+// already saved, so a failure is only logged.
+func (s *Store) recordEquity() {
+	if _, err := s.RecordEquitySnapshot(); err != nil {
+		log.Printf("Failed to record equity snapshot: %v", err)
+	}
+}
+
+// EquityCurve analyzes the stored snapshots plus a live snapshot for today
+func (s *Store) EquityCurve(settings *models.PositionSettings) (*equity.Curve, error) {
+	snapshots, err := database.LogBadKeys(s.equity.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch equity snapshots: %w", err)
+	}
+
+	histories, err := s.Histories()
+	if err != nil {
+		return nil, err
+	}
+	flows, err := s.CashFlows(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	today := equity.Snapshot(histories, flows, time.Now())
+	curve := equity.Analyze(equity.WithSnapshot(snapshots, today), settings.MaxDrawdownTolerance)
+	return &curve, nil
+}
+
+// CashFlows returns the recorded deposits and withdrawals, or the account value
+// from the settings while none have been recorded
+func (s *Store) CashFlows(settings *models.PositionSettings) ([]*models.CashFlow, error) {
+	recorded, err := database.LogBadKeys(s.cashFlows.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cash flows: %w", err)
+	}
+	return equity.Flows(recorded, settings.AccountValue), nil
+}
+
+// Histories returns the history of every trade
+func (s *Store) Histories() ([]*models.TradeHistory, error) {
+	trades, err := database.LogBadKeys(s.trades.GetAll())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trades: %w", err)
+	}
+
+	events, err := database.LogBadKeys(s.events.GetAllByTrade())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade events: %w", err)
+	}
+
+	histories := make([]*models.TradeHistory, 0, len(trades))
+	for _, trade := range trades {
+		histories = append(histories, models.NewTradeHistory(trade, events[trade.ID]))
+	}
+	return histories, nil
+}
+
+// History returns a trade with its lifecycle events, derived status and realized P&L
+func (s *Store) History(id string) (*models.TradeHistory, error) {
+	trade, err := s.trades.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade %s: %w", id, err)
+	}
+
+	events, err := database.LogBadKeys(s.events.GetByTrade(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events for trade %s: %w", id, err)
+	}
+
+	return models.NewTradeHistory(trade, events), nil
+}
+
+// CloseTrade records a close, expiration, assignment or adjustment against a trade.
+// A close, expiration or assignment without a quantity closes every open unit;
+// closing fewer units than are open records a partial exit.
+func (s *Store) CloseTrade(id string, event *models.TradeEvent) (*models.TradeHistory, error) {
+	if event.Type == "" {
+		event.Type = models.EventClose
+	}
+	if event.Type == models.EventRoll {
+		return nil, fmt.Errorf("use RollTrade to roll a trade")
+	}
+
+	history, err := s.History(id)
+	if err != nil {
+		return nil, err
+	}
+
+	event.TradeID = id
+	if event.Quantity == 0 && event.ClosesPosition() {
+		event.Quantity = history.OpenQuantity
+	}
+	if err := history.ValidateEvent(event); err != nil {
+		return nil, fmt.Errorf("invalid trade event: %w", err)
+	}
+
+	if err := s.events.Save(event); err != nil {
+		return nil, fmt.Errorf("failed to save trade event: %w", err)
+	}
+	s.recordEquity()
+
+	return s.History(id)
+}
+
+// RollTrade closes open units of a trade and opens the new trade they were rolled into.
+// If the event quantity is zero all open units are rolled. The new trade and the roll
+// event are saved together, and a roll is allowed while the loss limits lock new trades.
+func (s *Store) RollTrade(id string, event *models.TradeEvent, newTrade *models.Trade) (*models.TradeHistory, error) {
+	history, err := s.History(id)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Type = models.EventRoll
+	event.TradeID = id
+	if event.Quantity == 0 {
+		event.Quantity = history.OpenQuantity
+	}
+	if err := history.ValidateEvent(event); err != nil {
+		return nil, fmt.Errorf("invalid trade event: %w", err)
+	}
+
+	newTrade.ID = ""
+	newTrade.RolledFromID = id
+	if newTrade.EntryDate.IsZero() {
+		newTrade.EntryDate = event.Date
+	}
+	if _, err := s.CheckTrade(newTrade, false); err != nil {
+		return nil, fmt.Errorf("failed to save rolled trade: %w", err)
+	}
+
+	err = s.db.Batch(func(tx *database.Tx) error {
+		if err := s.trades.SaveTx(tx, newTrade); err != nil {
+			return fmt.Errorf("failed to save rolled trade: %w", err)
+		}
+		event.RolledToID = newTrade.ID
+		if err := s.events.SaveTx(tx, event); err != nil {
+			return fmt.Errorf("failed to save trade event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordEquity()
+
+	return s.History(id)
+}
@@ -0,0 +1,106 @@
+package trading
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stonk-risk-management/pkg/database"
+	"stonk-risk-management/pkg/models"
+	"stonk-risk-management/pkg/risk"
+	"stonk-risk-management/pkg/rules"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := database.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(database.MigrationOptions{DefaultRules: rules.Defaults()}); err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(db)
+}
+
+// longCall is a call bought at a price, risking the price times 100
+func longCall(id string, entry time.Time, price float64) *models.Trade {
+	trade := &models.Trade{
+		ID:             id,
+		Symbol:         "AAPL",
+		Sector:         "Technology",
+		Strategy:       "Directional",
+		Type:           "Long Call",
+		EntryDate:      entry,
+		ExpirationDate: entry.AddDate(0, 1, 0),
+		EntryPrice:     price,
+	}
+	trade.Legs = []models.Leg{models.SummaryLeg(trade)}
+	return trade
+}
+
+// While a loss limit is locked, trades that add risk are refused whether or not
+// the client chose their ID; edits that do not raise the max loss are allowed
+func TestSaveTradeLossLimitLock(t *testing.T) {
+	s := newTestStore(t)
+	settings := &models.PositionSettings{AccountValue: 10000, AccountRiskPerTrade: 10, DailyLossLimit: 3, WeeklyLossLimit: 6, RiskEnforcement: "warn"}
+	if err := s.positions.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// A $400 loss today locks the 3% daily limit
+	_, today, _ := risk.DayPeriod(time.Now())
+	loser := longCall("loser", today, 5)
+	if err := s.trades.Save(loser); err != nil {
+		t.Fatal(err)
+	}
+	close := &models.TradeEvent{TradeID: loser.ID, Type: models.EventClose, Date: today, Price: 1, Quantity: 1}
+	if err := s.events.Save(close); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.trades.Save(longCall("open", today.AddDate(0, 0, -1), 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func(price float64, notes string) *models.Trade {
+		trade := longCall("open", today.AddDate(0, 0, -1), price)
+		trade.Notes = notes
+		return trade
+	}
+
+	tests := []struct {
+		name   string
+		trade  *models.Trade
+		locked bool
+	}{
+		{"new trade", longCall("", today, 1), true},
+		{"new trade with an ID", longCall("client-chosen", today, 1), true},
+		{"edit raising the max loss", edit(3, ""), true},
+		{"edit keeping the max loss", edit(2, "Took profits on half"), false},
+		{"edit lowering the max loss", edit(1.5, "Took profits on half"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SaveTrade(tt.trade)
+			if locked := err != nil && strings.Contains(err.Error(), "locked by loss limits"); locked != tt.locked {
+				t.Fatalf("err = %v, want locked %v", err, tt.locked)
+			}
+			if !tt.locked && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	stored, err := s.trades.Get("open")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.EntryPrice != 1.5 || stored.Notes != "Took profits on half" {
+		t.Errorf("stored trade = %v %q, want the allowed edits", stored.EntryPrice, stored.Notes)
+	}
+	if _, err := s.trades.Get("client-chosen"); err == nil {
+		t.Error("refused trade was saved")
+	}
+}